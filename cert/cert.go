@@ -45,6 +45,26 @@ type CertInput struct {
 	LongName string
 	Fqdn     string
 	Prefix   string
+
+	// private key algorithm and size, rsa 2048 is used when not set
+	KeyAlgo string
+	KeySize int
+}
+
+// KeyType is the algorithm and size of a generated private key
+type KeyType struct {
+	Algo string
+	Size int
+}
+
+// DefaultKeyType is the private key type used when no key type is set
+const DefaultKeyType = "rsa2048"
+
+// KeyTypes are the supported private key types of the generated certificates
+var KeyTypes = map[string]KeyType{
+	"rsa2048":  {Algo: "rsa", Size: 2048},
+	"rsa4096":  {Algo: "rsa", Size: 4096},
+	"ecdsa256": {Algo: "ecdsa", Size: 256},
 }
 
 // CaRootInput struct
@@ -83,8 +103,8 @@ var rootCACSRTempl string = `{
 var NodeCSRTempl string = `{
     "CN": "{{.Name}}.{{.Prefix}}.io",
     "key": {
-      "algo": "rsa",
-      "size": 2048
+      "algo": "{{or .KeyAlgo "rsa"}}",
+      "size": {{or .KeySize 2048}}
     },
     "names": [{
       "C": "BE",
@@ -96,6 +116,9 @@ var NodeCSRTempl string = `{
       "{{.Name}}",
       "{{.LongName}}",
       "{{.Fqdn}}"
+      {{- range .Hosts}},
+      "{{.}}"
+      {{- end}}
     ]
 }
 `
@@ -234,10 +257,10 @@ func writeCertFiles(certs *Certificates, filesPrefix string) {
 //CreateRootCA creates RootCA key/certificate if it is needed by the topology
 func CreateRootCA(configName, labCARoot string, ns map[string]nodes.Node) error {
 	rootCANeeded := false
-	// check if srl kinds defined in topo
+	// check if the topology has nodes with the certificates signed by the lab CA,
 	// for them we need to create rootCA and certs
 	for _, n := range ns {
-		if n.Config().Kind == "srl" {
+		if u, ok := n.(nodes.LabCAUser); ok && u.UsesLabCA() {
 			rootCANeeded = true
 			break
 		}
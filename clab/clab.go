@@ -8,7 +8,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,6 +35,12 @@ type CLab struct {
 	Runtimes      map[string]runtime.ContainerRuntime
 	globalRuntime string
 	Dir           *Directory
+	// hook notified of the nodes lifecycle stages
+	lifecycleHook nodes.LifecycleHook
+	// deployment timings of the nodes, nil unless registered with WithDeployTimings
+	timings *DeployTimings
+	// when set, the nodes keep the NOS topology files generated by a previous deployment
+	preserveTopology bool
 
 	timeout time.Duration
 }
@@ -92,6 +103,32 @@ func WithKeepMgmtNet() ClabOption {
 	}
 }
 
+// WithLifecycleHook registers the hook that is notified of the lifecycle stages of the nodes supporting it
+func WithLifecycleHook(h nodes.LifecycleHook) ClabOption {
+	return func(c *CLab) error {
+		c.lifecycleHook = h
+		return nil
+	}
+}
+
+// WithDeployTimings records how long the deployment phases of the nodes take, see WriteDeployTimings.
+// like WithLifecycleHook, it must precede WithTopoFile
+func WithDeployTimings(t *DeployTimings) ClabOption {
+	return func(c *CLab) error {
+		c.timings = t
+		return nil
+	}
+}
+
+// WithPreserveTopology makes the nodes that support it keep the NOS topology files generated by a previous deployment,
+// like WithLifecycleHook, it must precede WithTopoFile
+func WithPreserveTopology() ClabOption {
+	return func(c *CLab) error {
+		c.preserveTopology = true
+		return nil
+	}
+}
+
 func WithTopoFile(file, varsFile string) ClabOption {
 	return func(c *CLab) error {
 		if file == "" {
@@ -143,6 +180,13 @@ func (c *CLab) initMgmtNetwork() error {
 		c.Config.Mgmt.IPv4Subnet = dockerNetIPv4Addr
 		c.Config.Mgmt.IPv6Subnet = dockerNetIPv6Addr
 	}
+	// the subnet is derived from the network name, so that the labs sharing the network use the same subnet
+	// and the labs with their own networks get distinct subnets
+	if c.Config.Mgmt.IPv6Subnet == autoIPv6Subnet {
+		c.Config.Mgmt.IPv6Subnet = utils.ULASubnet(c.Config.Mgmt.Network).String()
+		c.Config.Mgmt.IPv6ULA = true
+		log.Debugf("Generated IPv6 subnet %s for management network %q", c.Config.Mgmt.IPv6Subnet, c.Config.Mgmt.Network)
+	}
 
 	// init docker network mtu
 	if c.Config.Mgmt.MTU == "" {
@@ -160,6 +204,352 @@ func (c *CLab) GlobalRuntime() runtime.ContainerRuntime {
 	return c.Runtimes[c.globalRuntime]
 }
 
+// GenerateNodeCerts generates TLS certificates for the nodes that implement nodes.CertGenerator
+// using maxWorkers concurrent workers, since key generation is CPU-bound.
+// returns the error of the first node that failed to generate its certificate.
+func (c *CLab) GenerateNodeCerts(maxWorkers uint) error {
+	var certNodes []nodes.Node
+	for _, n := range c.Nodes {
+		if _, ok := n.(nodes.CertGenerator); ok {
+			certNodes = append(certNodes, n)
+		}
+	}
+	if len(certNodes) == 0 {
+		return nil
+	}
+
+	workers := int(maxWorkers)
+	if workers <= 0 {
+		workers = goruntime.NumCPU()
+	}
+	if workers > len(certNodes) {
+		workers = len(certNodes)
+	}
+
+	input := make(chan nodes.Node)
+	errs := make(chan error, len(certNodes))
+	wg := new(sync.WaitGroup)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for n := range input {
+				err := n.(nodes.CertGenerator).GenerateCert(c.Config.Name, c.Dir.LabCA, c.Dir.LabCARoot)
+				if err != nil {
+					errs <- fmt.Errorf("failed to generate certificate for node %q: %v", n.Config().ShortName, err)
+				}
+			}
+		}()
+	}
+
+	for _, n := range certNodes {
+		input <- n
+	}
+	close(input)
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// RenderNodes runs the pre-deploy stage of the nodes, which renders their config artifacts in the lab dir,
+// and writes the configs that nodes implementing nodes.ConfigRenderer apply after they boot to w.
+// no containers are created, which makes it suitable for a dry run of the deployment.
+func (c *CLab) RenderNodes(w io.Writer) error {
+	names := make([]string, 0, len(c.Nodes))
+	for name := range c.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		n := c.Nodes[name]
+		if err := n.PreDeploy(c.Config.Name, c.Dir.LabCA, c.Dir.LabCARoot); err != nil {
+			return fmt.Errorf("failed pre-deploy phase for node %q: %v", name, err)
+		}
+		log.Infof("node %q config artifacts rendered to %s", name, n.Config().LabDir)
+
+		r, ok := n.(nodes.ConfigRenderer)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "# node %q config applied after boot\n", name)
+		if err := r.RenderConfig(w); err != nil {
+			return fmt.Errorf("failed to render config for node %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// runningNodeAddrs checks that the container of the node is running
+// and sets the node mgmt addresses that are not set in the topology from the container.
+func (c *CLab) runningNodeAddrs(ctx context.Context, name string, n nodes.Node) error {
+	ctr, err := c.runningContainer(ctx, name, n)
+	if err != nil {
+		return err
+	}
+	if ctr == nil {
+		return fmt.Errorf("node %q is not running", name)
+	}
+	setMgmtAddrs(n.Config(), ctr)
+	return nil
+}
+
+// setMgmtAddrs sets the node mgmt addresses that are not set in the topology from its container
+func setMgmtAddrs(cfg *types.NodeConfig, ctr *types.GenericContainer) {
+	if cfg.MgmtIPv4Address == "" {
+		cfg.MgmtIPv4Address = ctr.NetworkSettings.IPv4addr
+	}
+	if cfg.MgmtIPv6Address == "" {
+		cfg.MgmtIPv6Address = ctr.NetworkSettings.IPv6addr
+	}
+}
+
+// runningContainer returns the running container of the node, nil if the node has no running container
+func (c *CLab) runningContainer(ctx context.Context, name string, n nodes.Node) (*types.GenericContainer, error) {
+	ctrs, err := n.GetRuntime().ListContainers(ctx, []*types.GenericFilter{
+		{FilterType: "label", Field: "containerlab", Operator: "=", Match: c.Config.Name},
+		{FilterType: "label", Field: NodeNameLabel, Operator: "=", Match: name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ctrs) == 0 || ctrs[0].State != "running" {
+		return nil, nil
+	}
+	return &ctrs[0], nil
+}
+
+// ReconcileNode reconciles the running config of the node that implements nodes.Reconciler with the config rendered
+// from its startup-config and returns the diff between the two, the diff is applied to the running config when apply is set.
+// the node mgmt addresses are taken from its container, so that the startup-config is rendered as when the node was deployed.
+func (c *CLab) ReconcileNode(ctx context.Context, name string, apply bool) (string, error) {
+	n, ok := c.Nodes[name]
+	if !ok {
+		return "", fmt.Errorf("node %q is not found in the topology", name)
+	}
+	r, ok := n.(nodes.Reconciler)
+	if !ok {
+		return "", fmt.Errorf("node %q of kind %s doesn't support reconciliation", name, n.Config().Kind)
+	}
+	if err := c.runningNodeAddrs(ctx, name, n); err != nil {
+		return "", err
+	}
+	return r.Reconcile(ctx, apply)
+}
+
+// ReconfigureNode re-applies the config containerlab generates to the running node that implements nodes.Reconfigurer.
+// the node mgmt addresses are taken from its container, so that a regenerated certificate has the addresses in its SANs.
+func (c *CLab) ReconfigureNode(ctx context.Context, name string) error {
+	n, ok := c.Nodes[name]
+	if !ok {
+		return fmt.Errorf("node %q is not found in the topology", name)
+	}
+	r, ok := n.(nodes.Reconfigurer)
+	if !ok {
+		return fmt.Errorf("node %q of kind %s doesn't support reconfiguration", name, n.Config().Kind)
+	}
+
+	if err := c.runningNodeAddrs(ctx, name, n); err != nil {
+		return err
+	}
+
+	if cg, ok := n.(nodes.CertGenerator); ok {
+		if err := cg.GenerateCert(c.Config.Name, c.Dir.LabCA, c.Dir.LabCARoot); err != nil {
+			return fmt.Errorf("failed to generate certificate for node %q: %v", name, err)
+		}
+	}
+
+	if err := r.ReConfigure(ctx); err != nil {
+		return err
+	}
+
+	// the interfaces of the links added to the running lab are not configured by the node yet
+	if is, ok := n.(nodes.InterfaceSyncer); ok {
+		added, err := is.SyncInterfaces(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to enable new interfaces of node %q: %v", name, err)
+		}
+		if len(added) > 0 {
+			log.Infof("enabled new interfaces %s of node %s", strings.Join(added, ", "), name)
+		}
+	}
+	return nil
+}
+
+// PushNodeStartupConfig re-renders the startup-config of the node that implements nodes.StartupConfigPusher
+// and applies it to the running node without recreating its container.
+// when the node is not running only its startup-config files are regenerated.
+func (c *CLab) PushNodeStartupConfig(ctx context.Context, name string) error {
+	n, ok := c.Nodes[name]
+	if !ok {
+		return fmt.Errorf("node %q is not found in the topology", name)
+	}
+	p, ok := n.(nodes.StartupConfigPusher)
+	if !ok {
+		return fmt.Errorf("node %q of kind %s doesn't support pushing the startup-config", name, n.Config().Kind)
+	}
+
+	ctr, err := c.runningContainer(ctx, name, n)
+	if err != nil {
+		return err
+	}
+	// the startup-config is rendered with the node mgmt addresses as when the node was deployed
+	if ctr != nil {
+		setMgmtAddrs(n.Config(), ctr)
+	}
+	return p.PushStartupConfig(ctx, ctr != nil)
+}
+
+// DeleteVolumes removes the named volumes mounted by the nodes that implement nodes.VolumeUser, with their data.
+// a failure to remove a volume doesn't stop the removal of the other volumes.
+func (c *CLab) DeleteVolumes(ctx context.Context) error {
+	names := make([]string, 0, len(c.Nodes))
+	for name := range c.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failed int
+	for _, name := range names {
+		n := c.Nodes[name]
+		vu, ok := n.(nodes.VolumeUser)
+		if !ok || len(vu.Volumes()) == 0 {
+			continue
+		}
+		vm, ok := n.GetRuntime().(runtime.VolumeManager)
+		if !ok {
+			continue
+		}
+		for _, v := range vu.Volumes() {
+			log.Infof("Removing volume %s of node %s", v, name)
+			if err := vm.DeleteVolume(ctx, v); err != nil {
+				log.Errorf("failed to remove volume %s of node %s: %v", v, name, err)
+				failed++
+			}
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("failed to remove %d volume(s) of lab %s", failed, c.Config.Name)
+	}
+	return nil
+}
+
+// CollectDiagnostics collects the diagnostics of the running lab nodes to the node-named subdirectories of destDir.
+// nodes that don't support diagnostics collection or are not running are skipped,
+// a failure to collect the diagnostics of a node doesn't stop the collection for the other nodes.
+func (c *CLab) CollectDiagnostics(ctx context.Context, destDir string) error {
+	names := make([]string, 0, len(c.Nodes))
+	for name := range c.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failed []string
+	for _, name := range names {
+		n := c.Nodes[name]
+		dc, ok := n.(nodes.DiagnosticsCollector)
+		if !ok {
+			log.Infof("node %q of kind %s doesn't support diagnostics collection, skipping", name, n.Config().Kind)
+			continue
+		}
+
+		ctrs, err := n.GetRuntime().ListContainers(ctx, []*types.GenericFilter{
+			{FilterType: "label", Field: "containerlab", Operator: "=", Match: c.Config.Name},
+			{FilterType: "label", Field: NodeNameLabel, Operator: "=", Match: name},
+		})
+		if err != nil {
+			return err
+		}
+		if len(ctrs) == 0 || ctrs[0].State != "running" {
+			log.Infof("node %q is not running, skipping", name)
+			continue
+		}
+
+		log.Infof("collecting diagnostics of node %q", name)
+		if err := dc.CollectDiagnostics(ctx, filepath.Join(destDir, name)); err != nil {
+			log.Errorf("failed to collect diagnostics of node %q: %v", name, err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("failed to collect diagnostics of nodes %q", failed)
+	}
+	return nil
+}
+
+// ConfigExport is the result of the config export of a lab node
+type ConfigExport struct {
+	Node string
+	// path of the exported config file, empty if the node is skipped or the export failed
+	Path string
+	// set for the nodes that don't support saving and exporting their config
+	Skipped bool
+	Err     error
+}
+
+// ExportConfigs saves the config of the lab nodes and exports it to destDir concurrently, with at most maxWorkers nodes
+// at a time, defaulting to the number of CPUs. the export of each node is bounded by timeout.
+// nodes that don't implement nodes.ConfigExporter or can't save their config, e.g. with a read-only config dir, are skipped.
+// a failure to export the config of a node doesn't stop the export of the other nodes.
+// the results are sorted by the node name.
+func (c *CLab) ExportConfigs(ctx context.Context, destDir string, maxWorkers uint, timeout time.Duration) []*ConfigExport {
+	names := make([]string, 0, len(c.Nodes))
+	for name := range c.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]*ConfigExport, len(names))
+	var exports []int
+	for i, name := range names {
+		res[i] = &ConfigExport{Node: name}
+		n := c.Nodes[name]
+		if _, ok := n.(nodes.ConfigExporter); !ok || !nodes.HasCapability(n, nodes.CapabilitySaveConfig) {
+			res[i].Skipped = true
+			continue
+		}
+		exports = append(exports, i)
+	}
+	if len(exports) == 0 {
+		return res
+	}
+
+	workers := int(maxWorkers)
+	if workers <= 0 {
+		workers = goruntime.NumCPU()
+	}
+	if workers > len(exports) {
+		workers = len(exports)
+	}
+
+	input := make(chan *ConfigExport)
+	wg := new(sync.WaitGroup)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for r := range input {
+				// a wedged node must not block the export of the other nodes
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				r.Path, r.Err = c.Nodes[r.Node].(nodes.ConfigExporter).ExportConfig(ctx, destDir)
+				if r.Err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					r.Err = fmt.Errorf("config export timed out after %s: %v", timeout, r.Err)
+				}
+				cancel()
+			}
+		}()
+	}
+
+	for _, i := range exports {
+		input <- res[i]
+	}
+	close(input)
+	wg.Wait()
+
+	return res
+}
+
 // CreateNodes will schedule nodes creation
 // returns waitgroups for nodes with static and dynamic IPs,
 // since static nodes are scheduled first
@@ -0,0 +1,297 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// fakeCertNode is a node that implements nodes.CertGenerator
+type fakeCertNode struct {
+	nodes.Node
+	cfg   *types.NodeConfig
+	calls *int32
+	err   error
+}
+
+func (n *fakeCertNode) Config() *types.NodeConfig { return n.cfg }
+
+func (n *fakeCertNode) GenerateCert(_, _, _ string) error {
+	atomic.AddInt32(n.calls, 1)
+	return n.err
+}
+
+func TestGenerateNodeCerts(t *testing.T) {
+	tests := map[string]struct {
+		failing string
+		workers uint
+	}{
+		"default-workers": {},
+		"single-worker": {
+			workers: 1,
+		},
+		"failing-node": {
+			failing: "node3",
+			workers: 2,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int32
+			c := &CLab{
+				Config: &Config{Name: "lab"},
+				Dir:    &Directory{},
+				Nodes:  map[string]nodes.Node{},
+			}
+			for _, n := range []string{"node1", "node2", "node3", "node4"} {
+				fn := &fakeCertNode{cfg: &types.NodeConfig{ShortName: n}, calls: &calls}
+				if n == tc.failing {
+					fn.err = errors.New("key generation failed")
+				}
+				c.Nodes[n] = fn
+			}
+
+			err := c.GenerateNodeCerts(tc.workers)
+			if calls != 4 {
+				t.Fatalf("wanted 4 certificates generated, got %d", calls)
+			}
+			if tc.failing == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.failing) {
+				t.Fatalf("wanted an error naming %s, got %v", tc.failing, err)
+			}
+		})
+	}
+}
+
+// fakeExportNode is a node that implements nodes.ConfigExporter
+type fakeExportNode struct {
+	nodes.Node
+	cfg  *types.NodeConfig
+	caps []nodes.NodeCapability
+	err  error
+}
+
+func (n *fakeExportNode) Config() *types.NodeConfig            { return n.cfg }
+func (n *fakeExportNode) Capabilities() []nodes.NodeCapability { return n.caps }
+func (n *fakeExportNode) ExportConfig(_ context.Context, dir string) (string, error) {
+	if n.err != nil {
+		return "", n.err
+	}
+	return dir + "/" + n.cfg.ShortName + ".json", nil
+}
+
+func TestExportConfigs(t *testing.T) {
+	save := []nodes.NodeCapability{nodes.CapabilitySaveConfig}
+	c := &CLab{Nodes: map[string]nodes.Node{
+		"node1": &fakeExportNode{cfg: &types.NodeConfig{ShortName: "node1"}, caps: save},
+		"node2": &fakeExportNode{cfg: &types.NodeConfig{ShortName: "node2"}, caps: save, err: errors.New("save failed")},
+		// e.g. a node with a read-only config dir
+		"node3": &fakeExportNode{cfg: &types.NodeConfig{ShortName: "node3"}},
+		"node4": &fakeCertNode{cfg: &types.NodeConfig{ShortName: "node4"}},
+		"node5": &fakeExportNode{cfg: &types.NodeConfig{ShortName: "node5"}, caps: save},
+	}}
+
+	for _, workers := range []uint{0, 1, 2} {
+		t.Run(fmt.Sprintf("workers-%d", workers), func(t *testing.T) {
+			res := c.ExportConfigs(context.Background(), "configs", workers, time.Minute)
+			got := make([]string, 0, len(res))
+			for _, r := range res {
+				switch {
+				case r.Skipped:
+					got = append(got, r.Node+" skipped")
+				case r.Err != nil:
+					got = append(got, r.Node+" failed")
+				default:
+					got = append(got, r.Node+" "+r.Path)
+				}
+			}
+			want := []string{"node1 configs/node1.json", "node2 failed", "node3 skipped", "node4 skipped", "node5 configs/node5.json"}
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Fatalf("wanted %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestInitMgmtNetworkAutoIPv6(t *testing.T) {
+	c := &CLab{Config: &Config{Mgmt: &types.MgmtNet{Network: "lab-net", IPv6Subnet: autoIPv6Subnet, MTU: "1500"}}}
+	if err := c.initMgmtNetwork(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := utils.ULASubnet("lab-net").String(); c.Config.Mgmt.IPv6Subnet != want {
+		t.Fatalf("wanted IPv6 subnet %s, got %s", want, c.Config.Mgmt.IPv6Subnet)
+	}
+	if !c.Config.Mgmt.IPv6ULA {
+		t.Fatalf("wanted the IPv6 subnet to be marked as generated")
+	}
+	if c.Config.Mgmt.IPv4Subnet != "" {
+		t.Fatalf("wanted no IPv4 subnet, got %s", c.Config.Mgmt.IPv4Subnet)
+	}
+}
+
+func TestVerifyWaitFor(t *testing.T) {
+	tests := map[string]struct {
+		waitFor map[string][]string
+		wantErr string
+	}{
+		"no-deps": {
+			waitFor: map[string][]string{},
+		},
+		"chain": {
+			waitFor: map[string][]string{"srl1": {"radius"}, "srl2": {"srl1", "radius"}},
+		},
+		"unknown-node": {
+			waitFor: map[string][]string{"srl1": {"tacacs"}},
+			wantErr: `node "srl1" waits for node "tacacs"`,
+		},
+		"self": {
+			waitFor: map[string][]string{"srl1": {"srl1"}},
+			wantErr: "srl1 -> srl1",
+		},
+		"cycle": {
+			waitFor: map[string][]string{"radius": {"srl2"}, "srl1": {"radius"}, "srl2": {"srl1"}},
+			wantErr: "radius -> srl2 -> srl1 -> radius",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &CLab{Nodes: map[string]nodes.Node{}}
+			for _, n := range []string{"radius", "srl1", "srl2"} {
+				c.Nodes[n] = &fakeCertNode{cfg: &types.NodeConfig{ShortName: n, WaitFor: tc.waitFor[n]}}
+			}
+
+			err := c.verifyWaitFor()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("wanted an error containing '%s', got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// fakeVolumeRuntime records the removed volumes and fails to remove the volumes in failing
+type fakeVolumeRuntime struct {
+	runtime.ContainerRuntime
+	deleted []string
+	failing map[string]bool
+}
+
+func (*fakeVolumeRuntime) CreateVolume(_ context.Context, _ string, _ map[string]string) error {
+	return nil
+}
+
+func (r *fakeVolumeRuntime) DeleteVolume(_ context.Context, name string) error {
+	if r.failing[name] {
+		return errors.New("volume is in use")
+	}
+	r.deleted = append(r.deleted, name)
+	return nil
+}
+
+// fakeVolumeNode is a node that implements nodes.VolumeUser
+type fakeVolumeNode struct {
+	nodes.Node
+	rt      runtime.ContainerRuntime
+	volumes []string
+}
+
+func (n *fakeVolumeNode) GetRuntime() runtime.ContainerRuntime { return n.rt }
+func (n *fakeVolumeNode) Volumes() []string                    { return n.volumes }
+
+func TestDeleteVolumes(t *testing.T) {
+	rt := &fakeVolumeRuntime{failing: map[string]bool{"vol3": true}}
+	c := &CLab{
+		Config: &Config{Name: "lab"},
+		Nodes: map[string]nodes.Node{
+			"node1": &fakeVolumeNode{rt: rt, volumes: []string{"vol1"}},
+			"node2": &fakeVolumeNode{rt: rt},
+			"node3": &fakeVolumeNode{rt: rt, volumes: []string{"vol3"}},
+			"node4": &fakeVolumeNode{rt: rt, volumes: []string{"vol4"}},
+			"node5": &fakeCertNode{cfg: &types.NodeConfig{ShortName: "node5"}},
+		},
+	}
+
+	// a failure to remove a volume doesn't stop the removal of the others
+	if err := c.DeleteVolumes(context.Background()); err == nil {
+		t.Fatal("wanted an error for the volume that failed to be removed, got nil")
+	}
+	if got := fmt.Sprint(rt.deleted); got != "[vol1 vol4]" {
+		t.Fatalf("wanted volumes [vol1 vol4] removed, got %s", got)
+	}
+}
+
+// fakeImageNode is a node that implements nodes.RuntimeResolver and nodes.ImageChecker
+type fakeImageNode struct {
+	nodes.Node
+	cfg        *types.NodeConfig
+	resolveErr error
+	checkErr   error
+}
+
+func (n *fakeImageNode) Config() *types.NodeConfig { return n.cfg }
+
+func (n *fakeImageNode) ResolveRuntime(_ context.Context) error {
+	n.cfg.NodeType = "ixrd3"
+	return n.resolveErr
+}
+
+func (n *fakeImageNode) CheckImage(_ context.Context) error { return n.checkErr }
+
+func TestCheckNodeImages(t *testing.T) {
+	tests := map[string]struct {
+		resolveErr error
+		checkErr   error
+		strict     bool
+		wantErr    bool
+	}{
+		"ok":                      {},
+		"incompatible-image":      {checkErr: errors.New("type ixrd3 is not supported")},
+		"incompatible-strict":     {checkErr: errors.New("type ixrd3 is not supported"), strict: true, wantErr: true},
+		"invalid-resolved-type":   {resolveErr: errors.New("breakout mode is not supported"), wantErr: true},
+		"invalid-resolved-strict": {resolveErr: errors.New("breakout mode is not supported"), strict: true, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			n := &fakeImageNode{
+				cfg:        &types.NodeConfig{ShortName: "node1", Labels: map[string]string{}},
+				resolveErr: tc.resolveErr,
+				checkErr:   tc.checkErr,
+			}
+			c := &CLab{Nodes: map[string]nodes.Node{"node1": n}}
+
+			err := c.CheckNodeImages(context.Background(), tc.strict)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("wanted error %v, got %v", tc.wantErr, err)
+			}
+			if !tc.wantErr && n.cfg.Labels[NodeTypeLabel] != "ixrd3" {
+				t.Fatalf("wanted the node type label set to the resolved type, got %q", n.cfg.Labels[NodeTypeLabel])
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	log "github.com/sirupsen/logrus"
@@ -31,10 +32,15 @@ const (
 	dockerNetName     = "clab"
 	dockerNetIPv4Addr = "172.20.20.0/24"
 	dockerNetIPv6Addr = "2001:172:20:20::/64"
+	// ipv6_subnet value that generates a unique local IPv6 subnet for the management network
+	autoIPv6Subnet = "auto"
 	// NSPath value assigned to host interfaces
 	hostNSPath = "__host"
 	// veth link mtu
 	DefaultVethLinkMTU = 9500
+	// range of the link MTU values that can be set on the veth interfaces
+	minVethLinkMTU = 68
+	maxVethLinkMTU = 65535
 	// containerlab's reserved OUI
 	ClabOUI = "aa:c1:ab"
 
@@ -106,6 +112,11 @@ func (c *CLab) parseTopology() error {
 	c.Dir.LabCARoot = filepath.Join(c.Dir.LabCA, "root")
 	c.Dir.LabGraph = filepath.Join(c.Dir.Lab, "graph")
 
+	// the group startup configs are resolved for the nodes, so the group references are checked first
+	if err := c.Config.Topology.CheckGroups(); err != nil {
+		return err
+	}
+
 	// initialize Nodes and Links variable
 	c.Nodes = make(map[string]nodes.Node)
 	c.Links = make(map[int]*types.Link)
@@ -192,7 +203,8 @@ func (c *CLab) NewNode(nodeName, nodeRuntime string, nodeDef *types.NodeDefiniti
 	n := nodeInitializer()
 	// Init
 
-	err = n.Init(nodeCfg, nodes.WithRuntime(c.Runtimes[nodeRuntime]), nodes.WithMgmtNet(c.Config.Mgmt))
+	err = n.Init(nodeCfg, nodes.WithRuntime(c.Runtimes[nodeRuntime]), nodes.WithMgmtNet(c.Config.Mgmt),
+		nodes.WithLifecycleHook(c.nodeLifecycleHook()), nodes.WithPreserveTopology(c.preserveTopology))
 	if err != nil {
 		log.Errorf("failed to initialize node %q: %v", nodeCfg.ShortName, err)
 		return fmt.Errorf("failed to initialize node %q: %v", nodeCfg.ShortName, err)
@@ -224,6 +236,7 @@ func (c *CLab) createNodeCfg(nodeName string, nodeDef *types.NodeDefinition, idx
 		LabDir:          filepath.Join(c.Dir.Lab, nodeName),
 		Index:           idx,
 		Group:           c.Config.Topology.GetNodeGroup(nodeName),
+		GroupIndex:      c.Config.Topology.GetNodeGroupIndex(nodeName),
 		Kind:            strings.ToLower(c.Config.Topology.GetNodeKind(nodeName)),
 		NodeType:        c.Config.Topology.GetNodeType(nodeName),
 		Position:        c.Config.Topology.GetNodePosition(nodeName),
@@ -237,7 +250,7 @@ func (c *CLab) createNodeCfg(nodeName string, nodeDef *types.NodeDefinition, idx
 		MgmtIPv4Address: nodeDef.GetMgmtIPv4(),
 		MgmtIPv6Address: nodeDef.GetMgmtIPv6(),
 		Publish:         c.Config.Topology.GetNodePublish(nodeName),
-		Sysctls:         make(map[string]string),
+		Sysctls:         c.Config.Topology.GetNodeSysctls(nodeName),
 		Endpoints:       make([]*types.Endpoint, 0),
 		Sandbox:         c.Config.Topology.GetNodeSandbox(nodeName),
 		Kernel:          c.Config.Topology.GetNodeKernel(nodeName),
@@ -246,6 +259,11 @@ func (c *CLab) createNodeCfg(nodeName string, nodeDef *types.NodeDefinition, idx
 		CPUSet:          c.Config.Topology.GetNodeCPUSet(nodeName),
 		Memory:          c.Config.Topology.GetNodeMemory(nodeName),
 		StartupDelay:    c.Config.Topology.GetNodeStartupDelay(nodeName),
+		BootTimeout:     c.Config.Topology.GetNodeBootTimeout(nodeName),
+		WaitFor:         c.Config.Topology.GetNodeWaitFor(nodeName),
+		DNS:             c.Config.Topology.GetNodeDNS(nodeName),
+		Ulimits:         c.Config.Topology.GetNodeUlimits(nodeName),
+		ExtraHosts:      c.Config.Topology.GetNodeExtraHosts(nodeName),
 
 		// Extras
 		Extras: c.Config.Topology.GetNodeExtras(nodeName),
@@ -259,13 +277,32 @@ func (c *CLab) createNodeCfg(nodeName string, nodeDef *types.NodeDefinition, idx
 		return nil, err
 	}
 
+	// the variables of the later env files override the earlier ones,
+	// the variables set with env override the variables of all env files
+	envFiles, err := c.Config.Topology.GetNodeEnvFiles(nodeCfg.ShortName)
+	if err != nil {
+		return nil, err
+	}
+	var fileEnv map[string]string
+	for _, f := range envFiles {
+		env, err := utils.ReadEnvFile(f)
+		if err != nil {
+			return nil, err
+		}
+		fileEnv = utils.MergeStringMaps(fileEnv, env)
+	}
+	nodeCfg.Env = utils.MergeStringMaps(fileEnv, nodeCfg.Env)
+
 	nodeCfg.EnforceStartupConfig = c.Config.Topology.GetNodeEnforceStartupConfig(nodeCfg.ShortName)
+	nodeCfg.StartupConfigMode = c.Config.Topology.GetNodeStartupConfigMode(nodeCfg.ShortName)
+	nodeCfg.StartupConfigSHA256 = c.Config.Topology.GetNodeStartupConfigSHA256(nodeCfg.ShortName)
 
 	// initialize license field
 	nodeCfg.License, err = c.Config.Topology.GetNodeLicense(nodeCfg.ShortName)
 	if err != nil {
 		return nil, err
 	}
+	nodeCfg.LicenseSHA256 = c.Config.Topology.GetNodeLicenseSHA256(nodeCfg.ShortName)
 	// initialize bind mounts
 	binds := c.Config.Topology.GetNodeBinds(nodeName)
 	err = resolveBindPaths(binds, nodeCfg.LabDir)
@@ -277,7 +314,27 @@ func (c *CLab) createNodeCfg(nodeName string, nodeDef *types.NodeDefinition, idx
 	if err != nil {
 		return nil, err
 	}
+	if _, err := types.ParseUlimits(nodeCfg.Ulimits); err != nil {
+		return nil, fmt.Errorf("node %s: %v", nodeName, err)
+	}
+	if err := nodeCfg.DNS.Validate(); err != nil {
+		return nil, fmt.Errorf("node %s: %v", nodeName, err)
+	}
+	// docker doesn't allow custom resolvers for the containers sharing the host network
+	if nodeCfg.DNS != nil && nodeCfg.NetworkMode == "host" {
+		return nil, fmt.Errorf("node %s: dns can't be set for a node with the host network-mode", nodeName)
+	}
+	if err := types.ValidateExtraHosts(nodeCfg.ExtraHosts); err != nil {
+		return nil, fmt.Errorf("node %s: %v", nodeName, err)
+	}
+	// the containers sharing the host network use the /etc/hosts of the host
+	if len(nodeCfg.ExtraHosts) != 0 && nodeCfg.NetworkMode == "host" {
+		return nil, fmt.Errorf("node %s: extra-hosts can't be set for a node with the host network-mode", nodeName)
+	}
 	nodeCfg.Labels = c.Config.Topology.GetNodeLabels(nodeCfg.ShortName)
+	if err := checkReservedLabels(nodeCfg.Labels); err != nil {
+		return nil, fmt.Errorf("node %s: %v", nodeName, err)
+	}
 
 	nodeCfg.Config = c.Config.Topology.GetNodeConfigDispatcher(nodeCfg.ShortName)
 
@@ -290,13 +347,21 @@ func (c *CLab) NewLink(l *types.LinkConfig) *types.Link {
 		log.Fatalf("endpoint %q has wrong syntax, unexpected number of items", l.Endpoints) // skipcq: RVV-A0003
 	}
 
-	return &types.Link{
+	link := &types.Link{
 		A:      c.NewEndpoint(l.Endpoints[0]),
 		B:      c.NewEndpoint(l.Endpoints[1]),
 		MTU:    DefaultVethLinkMTU,
 		Labels: l.Labels,
 		Vars:   l.Vars,
 	}
+	if l.MTU != 0 {
+		link.MTU = l.MTU
+		link.A.MTU = l.MTU
+		link.B.MTU = l.MTU
+	}
+	link.A.Link = link
+	link.B.Link = link
+	return link
 }
 
 // NewEndpoint initializes a new endpoint object
@@ -368,6 +433,9 @@ func (c *CLab) CheckTopologyDefinition(ctx context.Context) error {
 	if err = c.verifyRootNetnsInterfaceUniqueness(); err != nil {
 		return err
 	}
+	if err = c.verifyHostPorts(); err != nil {
+		return err
+	}
 	if err = c.VerifyContainersUniqueness(ctx); err != nil {
 		return err
 	}
@@ -377,9 +445,84 @@ func (c *CLab) CheckTopologyDefinition(ctx context.Context) error {
 	if err = c.verifyHostIfaces(); err != nil {
 		return err
 	}
+	if err = c.verifyWaitFor(); err != nil {
+		return err
+	}
+	if err = c.verifyNodeEndpoints(); err != nil {
+		return err
+	}
 	return c.VerifyImages(ctx)
 }
 
+// verifyNodeEndpoints checks the link endpoints of the nodes that validate them against their settings
+func (c *CLab) verifyNodeEndpoints() error {
+	for _, n := range c.Nodes {
+		if ec, ok := n.(nodes.EndpointChecker); ok {
+			if err := ec.CheckEndpoints(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// verifyWaitFor checks that the nodes referenced in wait-for exist
+// and that the wait-for dependencies don't form a cycle
+func (c *CLab) verifyWaitFor() error {
+	for name, n := range c.Nodes {
+		for _, dep := range n.Config().WaitFor {
+			if _, ok := c.Nodes[dep]; !ok {
+				return fmt.Errorf("node %q waits for node %q which is not defined in the topology", name, dep)
+			}
+		}
+	}
+
+	// depth-first search with the nodes on the current path marked as visiting
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(c.Nodes))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			// the cycle is the part of the path starting at the node seen again
+			for i, n := range path {
+				if n == name {
+					return fmt.Errorf("wait-for dependency cycle detected: %s", strings.Join(append(path[i:], name), " -> "))
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range c.Nodes[name].Config().WaitFor {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	// visit the nodes in a stable order to report the same cycle on every run
+	names := make([]string, 0, len(c.Nodes))
+	for name := range c.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // VerifyBridgeExists verifies if every node of kind=bridge/ovs-bridge exists on the lab host
 func (c *CLab) verifyBridgesExist() error {
 	for name, node := range c.Nodes {
@@ -397,6 +540,9 @@ func (c *CLab) verifyLinks() error {
 	// dups accumulates duplicate links
 	dups := []string{}
 	for _, lc := range c.Config.Topology.Links {
+		if lc.MTU != 0 && (lc.MTU < minVethLinkMTU || lc.MTU > maxVethLinkMTU) {
+			return fmt.Errorf("link %q has mtu %d, it must be in the range %d-%d", lc.Endpoints, lc.MTU, minVethLinkMTU, maxVethLinkMTU)
+		}
 		for _, e := range lc.Endpoints {
 			if err := checkEndpoint(e); err != nil {
 				return err
@@ -430,11 +576,53 @@ func (c *CLab) VerifyImages(ctx context.Context) error {
 
 	}
 
+	pulls := make(map[string]time.Duration, len(images))
 	for image, runtimeName := range images {
+		start := time.Now()
 		err := c.Runtimes[runtimeName].PullImageIfRequired(ctx, image)
 		if err != nil {
 			return err
 		}
+		pulls[image] = time.Since(start)
+	}
+
+	// the nodes sharing an image report the time it took to pull it once
+	if c.timings != nil {
+		for name, node := range c.Nodes {
+			for _, imageName := range node.GetImages() {
+				c.timings.addImagePull(name, pulls[imageName])
+			}
+		}
+	}
+	return nil
+}
+
+// CheckNodeImages resolves the node settings that depend on the runtime, e.g. the SR Linux node type discovered from the image,
+// updates the node type label of the nodes with it and checks that the node images support the node settings.
+// invalid resolved settings fail the check, incompatible images are reported with a warning, or with an error if strict is set.
+// the images must be present in the local image store.
+func (c *CLab) CheckNodeImages(ctx context.Context, strict bool) error {
+	var errs []string
+	for _, n := range c.Nodes {
+		if rr, ok := n.(nodes.RuntimeResolver); ok {
+			if err := rr.ResolveRuntime(ctx); err != nil {
+				return err
+			}
+			// the node type might be taken from the image
+			n.Config().Labels[NodeTypeLabel] = n.Config().NodeType
+		}
+		ic, ok := n.(nodes.ImageChecker)
+		if !ok {
+			continue
+		}
+		if err := ic.CheckImage(ctx); err != nil {
+			log.Warn(err)
+			errs = append(errs, err.Error())
+		}
+	}
+	if strict && len(errs) != 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("node images are incompatible with the node settings: %s", strings.Join(errs, "; "))
 	}
 	return nil
 }
@@ -524,6 +712,65 @@ func (c *CLab) verifyRootNetnsInterfaceUniqueness() error {
 	return nil
 }
 
+// verifyHostPorts ensures that no host port is published by more than one node of the lab,
+// a port published on all host addresses conflicts with the same port published on any address
+func (c *CLab) verifyHostPorts() error {
+	names := make([]string, 0, len(c.Nodes))
+	for name := range c.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type hostPort struct {
+		node string
+		port PublishedPort
+	}
+	var seen []hostPort
+	for _, name := range names {
+		for _, p := range PublishedPorts(c.Nodes[name].Config()) {
+			for _, other := range seen {
+				if other.port.HostPort != p.HostPort || other.port.Protocol != p.Protocol {
+					continue
+				}
+				if other.port.HostIP == p.HostIP || wildcardHostIP(other.port.HostIP) || wildcardHostIP(p.HostIP) {
+					return fmt.Errorf("host port %d/%s is published by both node %s (container port %d) and node %s (container port %d)",
+						p.HostPort, p.Protocol, other.node, other.port.ContainerPort, name, p.ContainerPort)
+				}
+			}
+			seen = append(seen, hostPort{name, p})
+		}
+	}
+	return nil
+}
+
+// wildcardHostIP returns true if a port published on the host address ip is published on all host addresses
+func wildcardHostIP(ip string) bool {
+	return ip == "" || ip == "0.0.0.0" || ip == "::"
+}
+
+// IsReservedLabel returns true if the container label is one of the labels containerlab sets on the containers,
+// e.g. clab-node-name or the management addresses of the containerd runtime
+func IsReservedLabel(name string) bool {
+	return name == ContainerlabLabel || strings.HasPrefix(name, "clab-") ||
+		strings.HasPrefix(name, "clab.ipv4.") || strings.HasPrefix(name, "clab.ipv6.")
+}
+
+// checkReservedLabels returns an error if the user defined labels have a label reserved by containerlab,
+// which would be overridden when the container is created
+func checkReservedLabels(labels map[string]string) error {
+	var reserved []string
+	for k := range labels {
+		if IsReservedLabel(k) {
+			reserved = append(reserved, k)
+		}
+	}
+	if len(reserved) != 0 {
+		sort.Strings(reserved)
+		return fmt.Errorf("labels %q are reserved by containerlab", reserved)
+	}
+	return nil
+}
+
 // verifyVirtSupport checks if virtualization supported by vcpu if vrnetlab nodes are used
 func (c *CLab) verifyVirtSupport() error {
 	virtNeeded := false
@@ -640,6 +887,35 @@ func (*CLab) CheckResources() error {
 	return nil
 }
 
+// CheckHost checks the container host against the requirements of the nodes, e.g. the sysctls SR Linux nodes set,
+// before any container is created. the issues shared by several nodes are reported once,
+// with a warning, or with an error if strict is set.
+func (c *CLab) CheckHost(strict bool) error {
+	seen := make(map[string]struct{})
+	var issues []string
+	for _, n := range c.Nodes {
+		hc, ok := n.(nodes.HostChecker)
+		if !ok {
+			continue
+		}
+		for _, i := range hc.CheckHost() {
+			if _, ok := seen[i]; ok {
+				continue
+			}
+			seen[i] = struct{}{}
+			issues = append(issues, i)
+		}
+	}
+	sort.Strings(issues)
+	for _, i := range issues {
+		log.Warn(i)
+	}
+	if strict && len(issues) != 0 {
+		return fmt.Errorf("container host doesn't meet the node requirements: %s", strings.Join(issues, "; "))
+	}
+	return nil
+}
+
 // sets defaults after the topology has been parsed
 func (c *CLab) setDefaults() {
 	for _, n := range c.Nodes {
@@ -157,6 +157,23 @@ func prepareLinkVars(link *types.Link, varsA, varsB Dict) error {
 	return nil
 }
 
+// LinkIPs returns the IP addresses of the A and B ends of the link, in the address/prefix-length format.
+// the addresses are set with the clab_link_ip link variable or calculated from the clab_system_ip variables of the nodes,
+// empty strings are returned when the link has no addresses.
+func LinkIPs(link *types.Link) (string, string, error) {
+	varsA := make(Dict)
+	varsB := make(Dict)
+	if err := prepareLinkVars(link, varsA, varsB); err != nil {
+		return "", "", err
+	}
+	a, okA := varsA[vkLinkIP]
+	b, okB := varsB[vkLinkIP]
+	if !okA || !okB {
+		return "", "", nil
+	}
+	return fmt.Sprintf("%v", a), fmt.Sprintf("%v", b), nil
+}
+
 // Create a link name using the node names and optional link_num
 func linkName(link *types.Link) (string, string, error) {
 	var linkNo string
@@ -171,6 +188,10 @@ func linkIP(link *types.Link) (string, string, error) {
 	var ipA netaddr.IPPrefix
 	var err error
 	//
+	// nodes defined outside of the topology nodes, such as host, have no variables
+	if link.A.Node.Config == nil || link.B.Node.Config == nil {
+		return "", "", nil
+	}
 	_, okA := link.A.Node.Config.Vars[vkSystemIP]
 	_, okB := link.B.Node.Config.Vars[vkSystemIP]
 	if okA != okB {
@@ -120,6 +120,25 @@ func TestLinkIP(t *testing.T) {
 	assert(t, n2, "1.1.2.3/31")
 }
 
+func TestLinkIPs(t *testing.T) {
+	l := gettestLink()
+	a, b, _ := LinkIPs(l)
+	assert(t, a, "1.1.2.0/31")
+	assert(t, b, "1.1.2.1/31")
+
+	l.Vars[vkLinkIP] = "10.0.0.1/31"
+	a, b, _ = LinkIPs(l)
+	assert(t, a, "10.0.0.1/31")
+	assert(t, b, "10.0.0.0/31")
+
+	// host nodes have no variables to calculate the addresses from
+	l = gettestLink()
+	l.B.Node = &types.NodeConfig{ShortName: "host"}
+	a, b, _ = LinkIPs(l)
+	assert(t, a, "")
+	assert(t, b, "")
+}
+
 func TestPrepareLinkVars(t *testing.T) {
 	a := make(Dict)
 	b := make(Dict)
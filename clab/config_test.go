@@ -12,6 +12,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/docker/go-connections/nat"
 	"github.com/google/go-cmp/cmp"
 	"github.com/srl-labs/containerlab/types"
 )
@@ -277,6 +278,29 @@ func TestEnvInit(t *testing.T) {
 	}
 }
 
+func TestEnvFilesInit(t *testing.T) {
+	c, err := NewContainerLab(WithTopoFile("test_data/topo12.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]map[string]string{
+		// later files override the earlier ones, env overrides the files
+		"node1": {"ENV1": "file1", "ENV2": "file2", "ENV3": "node", types.CLAB_ENV_INTFS: "0"},
+		// files set on the kind level
+		"node2": {"ENV1": "file1", "ENV2": "file1", "ENV3": "file1", types.CLAB_ENV_INTFS: "0"},
+	}
+	for node, w := range want {
+		if env := c.Nodes[node].Config().Env; !reflect.DeepEqual(env, w) {
+			t.Errorf("node %s: wanted %q got %q", node, w, env)
+		}
+	}
+
+	if _, err := NewContainerLab(WithTopoFile("test_data/topo13.yml", "")); err == nil {
+		t.Fatalf("wanted an error for a missing env file, got nil")
+	}
+}
+
 func TestUserInit(t *testing.T) {
 	tests := map[string]struct {
 		got  string
@@ -339,6 +363,14 @@ func TestVerifyLinks(t *testing.T) {
 			got:  "test_data/topo1.yml",
 			want: "",
 		},
+		"link_mtu": {
+			got:  "test_data/topo10.yml",
+			want: "",
+		},
+		"link_mtu_out_of_range": {
+			got:  "test_data/topo11.yml",
+			want: "link [\"lin1:eth1\" \"lin2:eth1\"] has mtu 70000, it must be in the range 68-65535",
+		},
 	}
 
 	for name, tc := range tests {
@@ -363,6 +395,26 @@ func TestVerifyLinks(t *testing.T) {
 
 }
 
+func TestLinkMTU(t *testing.T) {
+	c, err := NewContainerLab(WithTopoFile("test_data/topo10.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// links without the mtu use the default veth mtu and don't pass it to the nodes
+	wantMTUs := map[string]int{"e1-1": 9000, "e1-2": DefaultVethLinkMTU}
+	wantEndpointMTUs := map[string]int{"e1-1": 9000, "e1-2": 0}
+	for _, l := range c.Links {
+		name := l.A.EndpointName
+		if l.MTU != wantMTUs[name] {
+			t.Errorf("link %s: wanted mtu %d, got %d", l, wantMTUs[name], l.MTU)
+		}
+		if l.A.MTU != wantEndpointMTUs[name] || l.B.MTU != wantEndpointMTUs[name] {
+			t.Errorf("link %s: wanted endpoints mtu %d, got %d and %d", l, wantEndpointMTUs[name], l.A.MTU, l.B.MTU)
+		}
+	}
+}
+
 func TestLabelsInit(t *testing.T) {
 	tests := map[string]struct {
 		got  string
@@ -465,3 +517,82 @@ func TestVerifyRootNetnsInterfaceUniqueness(t *testing.T) {
 	t.Logf("error: %v", err)
 
 }
+
+func TestVerifyHostPorts(t *testing.T) {
+	tests := map[string]struct {
+		node1   nat.PortMap
+		node2   nat.PortMap
+		wantErr bool
+	}{
+		"distinct-ports": {
+			node1: nat.PortMap{"57400/tcp": {{HostPort: "57401"}}},
+			node2: nat.PortMap{"57400/tcp": {{HostPort: "57402"}}},
+		},
+		"same-port": {
+			node1:   nat.PortMap{"57400/tcp": {{HostPort: "57401"}}},
+			node2:   nat.PortMap{"22/tcp": {{HostPort: "57401"}}},
+			wantErr: true,
+		},
+		"distinct-protocols": {
+			node1: nat.PortMap{"161/udp": {{HostPort: "1161"}}},
+			node2: nat.PortMap{"22/tcp": {{HostPort: "1161"}}},
+		},
+		"distinct-host-ips": {
+			node1: nat.PortMap{"22/tcp": {{HostIP: "127.0.0.1", HostPort: "2222"}}},
+			node2: nat.PortMap{"22/tcp": {{HostIP: "127.0.0.2", HostPort: "2222"}}},
+		},
+		"all-host-ips": {
+			node1:   nat.PortMap{"22/tcp": {{HostIP: "127.0.0.1", HostPort: "2222"}}},
+			node2:   nat.PortMap{"22/tcp": {{HostIP: "0.0.0.0", HostPort: "2222"}}},
+			wantErr: true,
+		},
+		"random-host-ports": {
+			node1: nat.PortMap{"22/tcp": {{}}},
+			node2: nat.PortMap{"22/tcp": {{}}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewContainerLab(WithTopoFile("test_data/topo1.yml", ""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Nodes["node1"].Config().PortBindings = tc.node1
+			c.Nodes["node2"].Config().PortBindings = tc.node2
+
+			err = c.verifyHostPorts()
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckReservedLabels(t *testing.T) {
+	tests := map[string]struct {
+		labels  map[string]string
+		wantErr bool
+	}{
+		"custom":          {labels: map[string]string{"team": "core", "ticket": "NET-42", "clab.srl.tls": "false"}},
+		"containerlab":    {labels: map[string]string{ContainerlabLabel: "other-lab"}, wantErr: true},
+		"node-name":       {labels: map[string]string{"team": "core", NodeNameLabel: "other"}, wantErr: true},
+		"containerd-addr": {labels: map[string]string{"clab.ipv4.addr": "10.0.0.1"}, wantErr: true},
+		"no-labels":       {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkReservedLabels(tc.labels)
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
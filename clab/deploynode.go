@@ -0,0 +1,180 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/cert"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// DeployNode deploys a single node without a topology file, e.g. to use a node as a test fixture.
+// the node runs through the same stages as the nodes of a lab: Init, PreDeploy, Deploy, PostDeploy and,
+// for the nodes implementing nodes.ReadyChecker, Ready. the returned node is deleted with its Delete method.
+//
+// the node is deployed as a lab named after the node, cfg.ShortName and cfg.Kind must be set.
+// r must be initialized, its management network is set to mgmt, or to the default containerlab network when mgmt is nil.
+// when cfg.LabDir is not set, the node files are kept in a new temporary directory the caller removes once done.
+// the lab CA is kept in the ca directory next to the node lab directory, like it is for the nodes of a lab.
+func DeployNode(ctx context.Context, cfg *types.NodeConfig, r runtime.ContainerRuntime, mgmt *types.MgmtNet) (nodes.Node, error) {
+	if cfg.ShortName == "" {
+		return nil, fmt.Errorf("node name is not set")
+	}
+	initFn, ok := nodes.Nodes[cfg.Kind]
+	if !ok {
+		return nil, fmt.Errorf("node %q refers to a kind %q which is not supported. Supported kinds are %q", cfg.ShortName, cfg.Kind, kinds)
+	}
+	labName := cfg.ShortName
+
+	if err := setSingleNodeDefaults(cfg); err != nil {
+		return nil, err
+	}
+	if err := checkReservedLabels(cfg.Labels); err != nil {
+		return nil, fmt.Errorf("node %s: %v", cfg.ShortName, err)
+	}
+	if err := types.ValidateExtraHosts(cfg.ExtraHosts); err != nil {
+		return nil, fmt.Errorf("node %s: %v", cfg.ShortName, err)
+	}
+	labCA := filepath.Join(filepath.Dir(cfg.LabDir), "ca")
+	labCARoot := filepath.Join(labCA, "root")
+
+	if mgmt == nil {
+		c := &CLab{Config: &Config{Mgmt: new(types.MgmtNet)}}
+		if err := c.initMgmtNetwork(); err != nil {
+			return nil, err
+		}
+		mgmt = c.Config.Mgmt
+	}
+	r.WithMgmtNet(mgmt)
+
+	n := initFn()
+	if err := n.Init(cfg, nodes.WithRuntime(r), nodes.WithMgmtNet(mgmt)); err != nil {
+		return nil, fmt.Errorf("failed to initialize node %q: %v", cfg.ShortName, err)
+	}
+	n.Config().Labels = utils.MergeStringMaps(n.Config().Labels, map[string]string{
+		ContainerlabLabel: labName,
+		NodeNameLabel:     n.Config().ShortName,
+		NodeKindLabel:     n.Config().Kind,
+		NodeTypeLabel:     n.Config().NodeType,
+		NodeGroupLabel:    n.Config().Group,
+		NodeLabDirLabel:   n.Config().LabDir,
+	})
+	if hc, ok := n.(nodes.HostChecker); ok {
+		for _, i := range hc.CheckHost() {
+			log.Warn(i)
+		}
+	}
+	nodesMap := map[string]nodes.Node{cfg.ShortName: n}
+
+	for _, image := range n.GetImages() {
+		if image == "" {
+			return nil, fmt.Errorf("missing required image for node %q", cfg.ShortName)
+		}
+		if err := r.PullImageIfRequired(ctx, image); err != nil {
+			return nil, err
+		}
+	}
+
+	if rr, ok := n.(nodes.RuntimeResolver); ok {
+		if err := rr.ResolveRuntime(ctx); err != nil {
+			return nil, err
+		}
+		n.Config().Labels[NodeTypeLabel] = n.Config().NodeType
+	}
+
+	utils.CreateDirectory(filepath.Dir(cfg.LabDir), 0755)
+	if err := cert.CreateRootCA(labName, labCARoot, nodesMap); err != nil {
+		return nil, err
+	}
+	if err := n.PreDeploy(labName, labCA, labCARoot); err != nil {
+		return nil, fmt.Errorf("failed pre-deploy phase for node %q: %v", cfg.ShortName, err)
+	}
+
+	if err := r.CreateNet(ctx); err != nil {
+		return nil, err
+	}
+	if err := n.Deploy(ctx); err != nil {
+		return nil, fmt.Errorf("failed deploy phase for node %q: %v", cfg.ShortName, err)
+	}
+	cfg.DeploymentStatus = "created"
+
+	// the node is removed when it fails to come up, so that no half-deployed container is left behind
+	if err := postDeployNode(ctx, n, labName, nodesMap); err != nil {
+		if derr := n.Delete(ctx); derr != nil {
+			log.Errorf("failed to delete node %q: %v", cfg.ShortName, derr)
+		}
+		return nil, err
+	}
+	return n, nil
+}
+
+// setSingleNodeDefaults fills in the node config fields that are set from the topology for the nodes of a lab
+func setSingleNodeDefaults(cfg *types.NodeConfig) error {
+	cfg.Kind = strings.ToLower(cfg.Kind)
+	if cfg.LongName == "" {
+		cfg.LongName = strings.Join([]string{defaultPrefix, cfg.ShortName}, "-")
+	}
+	if cfg.Fqdn == "" {
+		cfg.Fqdn = strings.Join([]string{cfg.ShortName, cfg.ShortName, ".io"}, ".")
+	}
+	if cfg.LabDir == "" {
+		dir, err := os.MkdirTemp("", cfg.LongName+"-")
+		if err != nil {
+			return fmt.Errorf("failed to create the lab directory of node %q: %v", cfg.ShortName, err)
+		}
+		cfg.LabDir = filepath.Join(dir, cfg.ShortName)
+	}
+	if cfg.Labels == nil {
+		cfg.Labels = map[string]string{}
+	}
+	if cfg.Sysctls == nil {
+		cfg.Sysctls = map[string]string{}
+	}
+	if cfg.Endpoints == nil {
+		cfg.Endpoints = make([]*types.Endpoint, 0)
+	}
+	return nil
+}
+
+// postDeployNode sets the management addresses of the deployed node, which the post-deploy stage may use,
+// runs its post-deploy stage and waits for it to be ready
+func postDeployNode(ctx context.Context, n nodes.Node, labName string, nodesMap map[string]nodes.Node) error {
+	cfg := n.Config()
+	containers, err := n.GetRuntime().ListContainers(ctx, []*types.GenericFilter{
+		{FilterType: "label", Match: labName, Field: ContainerlabLabel, Operator: "="},
+		{FilterType: "label", Match: cfg.ShortName, Field: NodeNameLabel, Operator: "="},
+	})
+	if err != nil {
+		return err
+	}
+	for _, cnt := range containers {
+		if cnt.NetworkSettings != (types.GenericMgmtIPs{}) && cfg.NetworkMode != "host" {
+			cfg.MgmtIPv4Address = cnt.NetworkSettings.IPv4addr
+			cfg.MgmtIPv4PrefixLength = cnt.NetworkSettings.IPv4pLen
+			cfg.MgmtIPv6Address = cnt.NetworkSettings.IPv6addr
+			cfg.MgmtIPv6PrefixLength = cnt.NetworkSettings.IPv6pLen
+		}
+		cfg.ContainerID = cnt.ID
+	}
+
+	if err := n.PostDeploy(ctx, nodesMap); err != nil {
+		return fmt.Errorf("failed to run postdeploy task for node %s: %v", cfg.ShortName, err)
+	}
+	if rc, ok := n.(nodes.ReadyChecker); ok {
+		if err := rc.Ready(ctx); err != nil {
+			return fmt.Errorf("node %s is not ready: %v", cfg.ShortName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+const fakeDeployKind = "fake-deploy"
+
+// fakeDeployRuntime reports the container of the deployed node with a management address
+type fakeDeployRuntime struct {
+	runtime.ContainerRuntime
+	mgmt   *types.MgmtNet
+	pulled []string
+}
+
+func (r *fakeDeployRuntime) WithMgmtNet(m *types.MgmtNet)    { r.mgmt = m }
+func (*fakeDeployRuntime) CreateNet(_ context.Context) error { return nil }
+func (*fakeDeployRuntime) GetName() string                   { return "fake" }
+
+func (r *fakeDeployRuntime) PullImageIfRequired(_ context.Context, image string) error {
+	r.pulled = append(r.pulled, image)
+	return nil
+}
+
+func (*fakeDeployRuntime) ListContainers(_ context.Context, _ []*types.GenericFilter) ([]types.GenericContainer, error) {
+	return []types.GenericContainer{{ID: "abc", NetworkSettings: types.GenericMgmtIPs{IPv4addr: "172.20.20.2", IPv4pLen: 24}}}, nil
+}
+
+// fakeDeployNode records the deployment stages it runs through
+type fakeDeployNode struct {
+	nodes.Node
+	cfg      *types.NodeConfig
+	rt       runtime.ContainerRuntime
+	stages   []string
+	labCA    string
+	readyErr error
+}
+
+func (n *fakeDeployNode) Init(cfg *types.NodeConfig, opts ...nodes.NodeOption) error {
+	n.cfg = cfg
+	for _, o := range opts {
+		o(n)
+	}
+	n.stages = append(n.stages, "init")
+	return nil
+}
+
+func (n *fakeDeployNode) Config() *types.NodeConfig              { return n.cfg }
+func (n *fakeDeployNode) WithRuntime(r runtime.ContainerRuntime) { n.rt = r }
+func (*fakeDeployNode) WithMgmtNet(_ *types.MgmtNet)             {}
+func (n *fakeDeployNode) GetRuntime() runtime.ContainerRuntime   { return n.rt }
+func (n *fakeDeployNode) GetImages() map[string]string {
+	return map[string]string{nodes.ImageKey: n.cfg.Image}
+}
+
+func (n *fakeDeployNode) PreDeploy(_, labCADir, _ string) error {
+	n.labCA = labCADir
+	n.stages = append(n.stages, "pre-deploy")
+	return nil
+}
+
+func (n *fakeDeployNode) Deploy(_ context.Context) error {
+	n.stages = append(n.stages, "deploy")
+	return nil
+}
+
+func (n *fakeDeployNode) PostDeploy(_ context.Context, _ map[string]nodes.Node) error {
+	n.stages = append(n.stages, "post-deploy "+n.cfg.MgmtIPv4Address)
+	return nil
+}
+
+func (n *fakeDeployNode) Ready(_ context.Context) error {
+	n.stages = append(n.stages, "ready")
+	return n.readyErr
+}
+
+func (n *fakeDeployNode) Delete(_ context.Context) error {
+	n.stages = append(n.stages, "delete")
+	return nil
+}
+
+func TestDeployNode(t *testing.T) {
+	var fn *fakeDeployNode
+	nodes.Register(fakeDeployKind, func() nodes.Node {
+		fn = new(fakeDeployNode)
+		return fn
+	})
+	defer delete(nodes.Nodes, fakeDeployKind)
+
+	r := new(fakeDeployRuntime)
+	cfg := &types.NodeConfig{ShortName: "node1", Kind: fakeDeployKind, Image: "img:1"}
+	n, err := DeployNode(context.Background(), cfg, r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(filepath.Dir(cfg.LabDir))
+
+	if got := fmt.Sprint(fn.stages); got != "[init pre-deploy deploy post-deploy 172.20.20.2 ready]" {
+		t.Fatalf("unexpected deployment stages %s", got)
+	}
+	if n.Config().LongName != "clab-node1" || n.Config().ContainerID != "abc" {
+		t.Fatalf("unexpected node config %+v", n.Config())
+	}
+	// the lab CA dir is kept next to the node lab dir
+	if want := filepath.Join(filepath.Dir(cfg.LabDir), "ca"); fn.labCA != want {
+		t.Fatalf("wanted lab CA dir %s, got %s", want, fn.labCA)
+	}
+	if n.Config().Labels[ContainerlabLabel] != "node1" || n.Config().Labels[NodeKindLabel] != fakeDeployKind {
+		t.Fatalf("unexpected node labels %v", n.Config().Labels)
+	}
+	if r.mgmt == nil || r.mgmt.Network != dockerNetName {
+		t.Fatalf("wanted the default management network set on the runtime, got %+v", r.mgmt)
+	}
+	if fmt.Sprint(r.pulled) != "[img:1]" {
+		t.Fatalf("wanted image img:1 pulled, got %v", r.pulled)
+	}
+
+	// the node that doesn't get ready is removed
+	nodes.Register(fakeDeployKind, func() nodes.Node {
+		fn = &fakeDeployNode{readyErr: errors.New("timeout")}
+		return fn
+	})
+	cfg = &types.NodeConfig{ShortName: "node1", Kind: fakeDeployKind, Image: "img:1", LabDir: filepath.Join(t.TempDir(), "node1")}
+	if _, err := DeployNode(context.Background(), cfg, r, nil); err == nil {
+		t.Fatal("wanted an error for the node that is not ready, got nil")
+	}
+	if fn.stages[len(fn.stages)-1] != "delete" {
+		t.Fatalf("wanted the node deleted, got stages %v", fn.stages)
+	}
+
+	if _, err := DeployNode(context.Background(), &types.NodeConfig{ShortName: "node1", Kind: "unknown"}, r, nil); err == nil {
+		t.Fatal("wanted an error for an unknown kind, got nil")
+	}
+}
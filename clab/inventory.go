@@ -6,36 +6,258 @@ package clab
 
 import (
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"text/template"
 
+	"github.com/srl-labs/containerlab/nodes"
 	"github.com/srl-labs/containerlab/types"
 )
 
-// GenerateInventories generate various inventory files and writes it to a lab location
-func (c *CLab) GenerateInventories() error {
-	ansibleInvFPath := filepath.Join(c.Dir.Lab, "ansible-inventory.yml")
-	f, err := os.Create(ansibleInvFPath)
+const (
+	// default ports of the JSON-RPC server used by the nokia.srlinux collection
+	ansibleHTTPPort  = 80
+	ansibleHTTPSPort = 443
+)
+
+// AnsibleInventoryPath returns the default path to the ansible inventory file of the lab
+func (c *CLab) AnsibleInventoryPath() string {
+	return filepath.Join(c.Dir.Lab, "ansible-inventory.yml")
+}
+
+// GenerateInventories generates the inventory files of the lab, the ansible inventory is written to ansibleInvPath
+// and lists only the nodes with the running containers, the other inventories are written to the lab dir.
+func (c *CLab) GenerateInventories(ansibleInvPath string, containers []types.GenericContainer) error {
+	f, err := os.Create(ansibleInvPath)
 	if err != nil {
 		return err
 	}
-	return c.generateAnsibleInventory(f)
+	defer f.Close()
+	if err := c.generateAnsibleInventory(f, runningNodes(containers)); err != nil {
+		return err
+	}
+
+	if !c.hasKind(nodes.NodeKindSRL) {
+		return nil
+	}
+	gnmiTargetsFPath := filepath.Join(c.Dir.Lab, "gnmi-targets.yml")
+	g, err := os.Create(gnmiTargetsFPath)
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+	return c.generateGNMITargets(g)
+}
+
+// hasKind returns true if the lab has nodes of the kind
+func (c *CLab) hasKind(kind string) bool {
+	for _, n := range c.Nodes {
+		if n.Config().Kind == kind {
+			return true
+		}
+	}
+	return false
 }
 
-// generateAnsibleInventory generates and writes ansible inventory file to w
-func (c *CLab) generateAnsibleInventory(w io.Writer) error {
+// generateGNMITargets generates and writes the gnmic config file with the gNMI targets of SR Linux nodes to w.
+// a node with both IPv4 and IPv6 mgmt addresses has a target per address family, the nodes with the gNMI server disabled have no targets.
+// the CA and the credentials of the targets are taken from the nodes, the passwords changed by the user are not written.
+func (c *CLab) generateGNMITargets(w io.Writer) error {
+	tgtT :=
+		`# gNMI targets of the SR Linux nodes, to be used with: gnmic --config gnmi-targets.yml
+# the node certificates are verified with the tls-ca of the targets
+username: {{.Username}}
+password: {{.Password}}
+targets:
+{{- range .Targets}}
+{{- if .Insecure}}
+  # TLS is disabled
+  {{.Name}}:
+    address: "{{.Address}}"
+    insecure: true
+{{- else}}
+  # TLS certificate: {{.Cert}}
+  {{.Name}}:
+    address: "{{.Address}}"
+    tls-ca: {{.TLSCA}}
+{{- end}}
+{{- if .Username}}
+    username: {{.Username}}
+{{- end}}
+{{- if .PasswordChanged}}
+    # the password of the node is not written to this file, pass it with --password
+{{- end}}
+{{- end}}
+`
+
+	type target struct {
+		Name     string
+		Address  string
+		Cert     string
+		TLSCA    string
+		Insecure bool
+		// set when the user differs from the default one
+		Username        string
+		PasswordChanged bool
+	}
+
+	type targets struct {
+		Username string
+		Password string
+		Targets  []target
+	}
+
+	creds := nodes.DefaultCredentials[nodes.NodeKindSRL]
+	t := targets{
+		Username: creds[0],
+		Password: creds[1],
+	}
+
+	var srlNodes []nodes.Node
+	for _, n := range c.Nodes {
+		if n.Config().Kind == nodes.NodeKindSRL {
+			srlNodes = append(srlNodes, n)
+		}
+	}
+	sort.Slice(srlNodes, func(i, j int) bool {
+		return srlNodes[i].Config().ShortName < srlNodes[j].Config().ShortName
+	})
+
+	for _, node := range srlNodes {
+		n := node.Config()
+		g, ok := node.(nodes.MgmtPortsGetter)
+		if !ok || g.GetMgmtPorts().GNMI == 0 {
+			// the gNMI server is disabled
+			continue
+		}
+		port := strconv.Itoa(g.GetMgmtPorts().GNMI)
+		tgt := target{Insecure: true}
+		// the paths are empty for a node with TLS disabled
+		if g, ok := node.(nodes.CertPathsGetter); ok {
+			tgt.Cert, _, tgt.TLSCA = g.GetCertPaths()
+			tgt.Insecure = tgt.TLSCA == ""
+		}
+		if g, ok := node.(nodes.CredentialsGetter); ok {
+			nc := g.GetCredentials()
+			if nc.Username != t.Username {
+				tgt.Username = nc.Username
+			}
+			tgt.PasswordChanged = nc.Password != t.Password
+		}
+		if n.MgmtIPv4Address != "" {
+			tgt.Name = n.LongName
+			tgt.Address = net.JoinHostPort(n.MgmtIPv4Address, port)
+			t.Targets = append(t.Targets, tgt)
+		}
+		if n.MgmtIPv6Address != "" {
+			tgt.Name = n.LongName + "-ipv6"
+			tgt.Address = net.JoinHostPort(n.MgmtIPv6Address, port)
+			t.Targets = append(t.Targets, tgt)
+		}
+	}
+
+	tpl, err := template.New("gnmi-targets").Parse(tgtT)
+	if err != nil {
+		return err
+	}
+	return tpl.Execute(w, t)
+}
+
+// runningNodes returns the names of the nodes with the running containers
+func runningNodes(containers []types.GenericContainer) map[string]bool {
+	running := make(map[string]bool)
+	for _, ctr := range containers {
+		if ctr.State == "running" {
+			running[ctr.Labels[NodeNameLabel]] = true
+		}
+	}
+	return running
+}
+
+// ansibleVar is an inventory variable with the value formatted for YAML
+type ansibleVar struct {
+	Name  string
+	Value string
+}
+
+// ansibleHost is a node of the inventory with its host variables
+type ansibleHost struct {
+	*types.NodeConfig
+	Vars []ansibleVar
+}
+
+// ansibleKindVars are the group variables of the kinds, the SR Linux nodes are managed
+// with the nokia.srlinux collection over the JSON-RPC server of the nodes
+var ansibleKindVars = map[string][]ansibleVar{
+	nodes.NodeKindSRL: {
+		{"ansible_connection", "ansible.netcommon.httpapi"},
+		{"ansible_network_os", "nokia.srlinux.srlinux"},
+		{"ansible_user", strconv.Quote(nodes.DefaultCredentials[nodes.NodeKindSRL][0])},
+		{"ansible_password", strconv.Quote(nodes.DefaultCredentials[nodes.NodeKindSRL][1])},
+		{"ansible_httpapi_use_ssl", "true"},
+		{"ansible_httpapi_validate_certs", "false"},
+	},
+}
+
+// ansibleHostVars returns the host variables of the node that override the group variables of its kind.
+// a password changed by the user is not copied to the inventory: a password file is read by ansible
+// and a password set with the admin-password label is passed to ansible by the user, e.g. with -e ansible_password=<password>.
+func ansibleHostVars(node nodes.Node) []ansibleVar {
+	n := node.Config()
+	if n.Kind != nodes.NodeKindSRL {
+		return nil
+	}
+	var vars []ansibleVar
+	if g, ok := node.(nodes.CredentialsGetter); ok {
+		creds := g.GetCredentials()
+		if creds.Username != nodes.DefaultCredentials[n.Kind][0] {
+			vars = append(vars, ansibleVar{"ansible_user", strconv.Quote(creds.Username)})
+		}
+		if creds.PasswordFile != "" {
+			vars = append(vars, ansibleVar{"ansible_password", strconv.Quote("{{ lookup('file', '" + creds.PasswordFile + "') }}")})
+		}
+	}
+	if g, ok := node.(nodes.MgmtPortsGetter); ok {
+		p := g.GetMgmtPorts()
+		switch {
+		case p.JSONRPCHTTPS != 0:
+			if p.JSONRPCHTTPS != ansibleHTTPSPort {
+				vars = append(vars, ansibleVar{"ansible_httpapi_port", strconv.Itoa(p.JSONRPCHTTPS)})
+			}
+		case p.JSONRPCHTTP != 0:
+			vars = append(vars, ansibleVar{"ansible_httpapi_use_ssl", "false"})
+			if p.JSONRPCHTTP != ansibleHTTPPort {
+				vars = append(vars, ansibleVar{"ansible_httpapi_port", strconv.Itoa(p.JSONRPCHTTP)})
+			}
+		}
+	}
+	return vars
+}
+
+// generateAnsibleInventory generates and writes ansible inventory file with the running nodes to w
+func (c *CLab) generateAnsibleInventory(w io.Writer, running map[string]bool) error {
 
 	invT :=
 		`all:
   children:
 {{- range $kind, $nodes := .Nodes}}
     {{$kind}}:
+{{- with index $.KindVars $kind}}
+      vars:
+{{- range .}}
+        {{.Name}}: {{.Value}}
+{{- end}}
+{{- end}}
       hosts:
 {{- range $nodes}}
         {{.LongName}}:
           ansible_host: {{.MgmtIPv4Address}}
+{{- range .Vars}}
+          {{.Name}}: {{.Value}}
+{{- end}}
 {{- end}}
 {{- end}}
 {{- range $name, $nodes := .Groups}}
@@ -50,18 +272,24 @@ func (c *CLab) generateAnsibleInventory(w io.Writer) error {
 
 	type inv struct {
 		// clab nodes aggregated by their kind
-		Nodes map[string][]*types.NodeConfig
+		Nodes map[string][]ansibleHost
+		// group variables of the kinds
+		KindVars map[string][]ansibleVar
 		// clab nodes aggregated by user-defined groups
 		Groups map[string][]*types.NodeConfig
 	}
 
 	i := inv{
-		Nodes:  make(map[string][]*types.NodeConfig),
-		Groups: make(map[string][]*types.NodeConfig),
+		Nodes:    make(map[string][]ansibleHost),
+		KindVars: ansibleKindVars,
+		Groups:   make(map[string][]*types.NodeConfig),
 	}
 
-	for _, n := range c.Nodes {
-		i.Nodes[n.Config().Kind] = append(i.Nodes[n.Config().Kind], n.Config())
+	for name, n := range c.Nodes {
+		if !running[name] {
+			continue
+		}
+		i.Nodes[n.Config().Kind] = append(i.Nodes[n.Config().Kind], ansibleHost{NodeConfig: n.Config(), Vars: ansibleHostVars(n)})
 		if n.Config().Labels["ansible-group"] != "" {
 			i.Groups[n.Config().Labels["ansible-group"]] = append(i.Groups[n.Config().Labels["ansible-group"]], n.Config())
 		}
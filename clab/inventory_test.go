@@ -5,6 +5,7 @@
 package clab
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -12,15 +13,29 @@ import (
 )
 
 func TestGenerateAnsibleInventory(t *testing.T) {
+	pwFile, err := filepath.Abs("test_data/srl-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	tests := map[string]struct {
-		got  string
-		want string
+		got string
+		// nodes without a running container
+		stopped []string
+		want    string
 	}{
 		"case1": {
 			got: "test_data/topo1.yml",
 			want: `all:
   children:
     srl:
+      vars:
+        ansible_connection: ansible.netcommon.httpapi
+        ansible_network_os: nokia.srlinux.srlinux
+        ansible_user: "admin"
+        ansible_password: "admin"
+        ansible_httpapi_use_ssl: true
+        ansible_httpapi_validate_certs: false
       hosts:
         clab-topo1-node1:
           ansible_host: 172.100.100.11
@@ -29,27 +44,58 @@ func TestGenerateAnsibleInventory(t *testing.T) {
 `,
 		},
 		"case2": {
-			got: "test_data/topo8_ansible_groups.yml",
+			got:     "test_data/topo8_ansible_groups.yml",
+			stopped: []string{"node3"},
 			want: `all:
   children:
     srl:
+      vars:
+        ansible_connection: ansible.netcommon.httpapi
+        ansible_network_os: nokia.srlinux.srlinux
+        ansible_user: "admin"
+        ansible_password: "admin"
+        ansible_httpapi_use_ssl: true
+        ansible_httpapi_validate_certs: false
       hosts:
         clab-topo8_ansible_groups-node1:
           ansible_host: 172.100.100.11
         clab-topo8_ansible_groups-node2:
           ansible_host: 172.100.100.12
-        clab-topo8_ansible_groups-node3:
-          ansible_host: 172.100.100.13
     extra_group:
       hosts:
         clab-topo8_ansible_groups-node2:
           ansible_host: 172.100.100.12
-        clab-topo8_ansible_groups-node3:
-          ansible_host: 172.100.100.13
     spine:
       hosts:
         clab-topo8_ansible_groups-node1:
           ansible_host: 172.100.100.11
+`,
+		},
+		"srl-settings": {
+			got: "test_data/topo15.yml",
+			want: `all:
+  children:
+    srl:
+      vars:
+        ansible_connection: ansible.netcommon.httpapi
+        ansible_network_os: nokia.srlinux.srlinux
+        ansible_user: "admin"
+        ansible_password: "admin"
+        ansible_httpapi_use_ssl: true
+        ansible_httpapi_validate_certs: false
+      hosts:
+        clab-topo15-node1:
+          ansible_host: 172.100.100.11
+          ansible_httpapi_use_ssl: false
+        clab-topo15-node2:
+          ansible_host: 172.100.100.12
+          ansible_user: "clab"
+          ansible_password: "{{ lookup('file', '` + pwFile + `') }}"
+        clab-topo15-node3:
+          ansible_host: 172.100.100.13
+        clab-topo15-node4:
+          ansible_host: 172.100.100.14
+          ansible_httpapi_port: 8443
 `,
 		},
 	}
@@ -63,9 +109,16 @@ func TestGenerateAnsibleInventory(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
+			running := make(map[string]bool)
+			for n := range c.Nodes {
+				running[n] = true
+			}
+			for _, n := range tc.stopped {
+				delete(running, n)
+			}
 
 			var s strings.Builder
-			err = c.generateAnsibleInventory(&s)
+			err = c.generateAnsibleInventory(&s, running)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -76,3 +129,47 @@ func TestGenerateAnsibleInventory(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateGNMITargets(t *testing.T) {
+	c, err := NewContainerLab(WithTopoFile("test_data/topo15.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Nodes["node2"].Config().MgmtIPv6Address = "2001:172:100:100::12"
+
+	var s strings.Builder
+	if err := c.generateGNMITargets(&s); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# gNMI targets of the SR Linux nodes, to be used with: gnmic --config gnmi-targets.yml
+# the node certificates are verified with the tls-ca of the targets
+username: admin
+password: admin
+targets:
+  # TLS is disabled
+  clab-topo15-node1:
+    address: "172.100.100.11:57400"
+    insecure: true
+  # TLS certificate: ` + c.Dir.LabCA + `/node2/node2.pem
+  clab-topo15-node2:
+    address: "172.100.100.12:50052"
+    tls-ca: ` + c.Dir.LabCARoot + `/root-ca.pem
+    username: clab
+    # the password of the node is not written to this file, pass it with --password
+  # TLS certificate: ` + c.Dir.LabCA + `/node2/node2.pem
+  clab-topo15-node2-ipv6:
+    address: "[2001:172:100:100::12]:50052"
+    tls-ca: ` + c.Dir.LabCARoot + `/root-ca.pem
+    username: clab
+    # the password of the node is not written to this file, pass it with --password
+  # TLS certificate: ` + c.Dir.LabCA + `/node3/node3.pem
+  clab-topo15-node3:
+    address: "172.100.100.13:57400"
+    tls-ca: /etc/pki/corp-ca.pem
+    # the password of the node is not written to this file, pass it with --password
+`
+	if d := cmp.Diff(want, s.String()); d != "" {
+		t.Errorf("unexpected gnmi targets (-want +got):\n%s", d)
+	}
+}
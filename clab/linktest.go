@@ -0,0 +1,159 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// linkTestSubnet is the range the /31 link test prefixes are allocated from.
+// it is the shared address space of RFC 6598, which is not expected to be used in the labs.
+var linkTestSubnet = net.IPNet{IP: net.IPv4(100, 64, 0, 0).To4(), Mask: net.CIDRMask(10, 32)}
+
+// LinkTestResult is the outcome of the connectivity test of a link, Err is nil if the test passed
+type LinkTestResult struct {
+	Link *types.Link
+	Err  error
+}
+
+// linkTest holds the temporary addresses of a tested link
+type linkTest struct {
+	link         *types.Link
+	addrA, addrB net.IP
+}
+
+// TestLinks verifies the point-to-point links between the nodes that implement nodes.LinkTester.
+// both ends of a link get temporary addresses from a /31 prefix, the A end pings the B end,
+// and the temporary addresses are removed once all links are tested.
+// the results are sorted as the links in the topology file.
+func (c *CLab) TestLinks(ctx context.Context) ([]LinkTestResult, error) {
+	tests := c.linkTests()
+	if maxLinks := 1 << 21; len(tests) > maxLinks {
+		return nil, fmt.Errorf("%d links can be tested at most, the lab has %d", maxLinks, len(tests))
+	}
+
+	// addresses of each node are configured at once, so that the node commits only once
+	addrs := make(map[string]map[string]string)
+	for _, t := range tests {
+		for _, e := range []struct {
+			ep   *types.Endpoint
+			addr net.IP
+		}{{t.link.A, t.addrA}, {t.link.B, t.addrB}} {
+			if addrs[e.ep.Node.ShortName] == nil {
+				addrs[e.ep.Node.ShortName] = make(map[string]string)
+			}
+			addrs[e.ep.Node.ShortName][e.ep.EndpointName] = e.addr.String() + "/31"
+		}
+	}
+
+	// nodeErrs holds the errors of the nodes that failed to configure the addresses
+	nodeErrs := make(map[string]error)
+	mu := &sync.Mutex{}
+	c.forEachTestNode(addrs, func(name string, lt nodes.LinkTester, ifAddrs map[string]string) {
+		if err := lt.AddTestAddrs(ctx, ifAddrs); err != nil {
+			mu.Lock()
+			nodeErrs[name] = err
+			mu.Unlock()
+		}
+	})
+
+	results := make([]LinkTestResult, len(tests))
+	wg := &sync.WaitGroup{}
+	wg.Add(len(tests))
+	for i, t := range tests {
+		go func(i int, t linkTest) {
+			defer wg.Done()
+			results[i].Link = t.link
+			for _, n := range []string{t.link.A.Node.ShortName, t.link.B.Node.ShortName} {
+				if err := nodeErrs[n]; err != nil {
+					results[i].Err = fmt.Errorf("not tested, failed to configure node %s: %v", n, err)
+					return
+				}
+			}
+			results[i].Err = c.Nodes[t.link.A.Node.ShortName].(nodes.LinkTester).Ping(ctx, t.addrB.String())
+		}(i, t)
+	}
+	wg.Wait()
+
+	c.forEachTestNode(addrs, func(name string, lt nodes.LinkTester, ifAddrs map[string]string) {
+		if nodeErrs[name] != nil {
+			return
+		}
+		ifaces := make([]string, 0, len(ifAddrs))
+		for i := range ifAddrs {
+			ifaces = append(ifaces, i)
+		}
+		sort.Strings(ifaces)
+		if err := lt.RemoveTestAddrs(ctx, ifaces); err != nil {
+			log.Warnf("failed to remove link test addresses from node %s: %v", name, err)
+		}
+	})
+
+	return results, nil
+}
+
+// forEachTestNode concurrently calls f for each node with the link test addresses in addrs
+func (c *CLab) forEachTestNode(addrs map[string]map[string]string,
+	f func(name string, lt nodes.LinkTester, ifAddrs map[string]string)) {
+	wg := &sync.WaitGroup{}
+	wg.Add(len(addrs))
+	for name, ifAddrs := range addrs {
+		go func(name string, ifAddrs map[string]string) {
+			defer wg.Done()
+			f(name, c.Nodes[name].(nodes.LinkTester), ifAddrs)
+		}(name, ifAddrs)
+	}
+	wg.Wait()
+}
+
+// linkTests returns the links that can be tested with the addresses allocated for them, in the topology file order.
+// a link can be tested if it connects two distinct nodes that implement nodes.LinkTester
+// over interfaces their NOS can address.
+func (c *CLab) linkTests() []linkTest {
+	keys := make([]int, 0, len(c.Links))
+	for k := range c.Links {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	base := binary.BigEndian.Uint32(linkTestSubnet.IP)
+	var tests []linkTest
+	for _, k := range keys {
+		l := c.Links[k]
+		if l.A.Node.ShortName == l.B.Node.ShortName || !c.linkTestable(l.A) || !c.linkTestable(l.B) {
+			continue
+		}
+		t := linkTest{link: l, addrA: make(net.IP, 4), addrB: make(net.IP, 4)}
+		binary.BigEndian.PutUint32(t.addrA, base+uint32(2*len(tests)))
+		binary.BigEndian.PutUint32(t.addrB, base+uint32(2*len(tests))+1)
+		tests = append(tests, t)
+	}
+	return tests
+}
+
+// linkTestable returns true if the endpoint's node implements nodes.LinkTester
+// and maps the endpoint to a NOS interface
+func (c *CLab) linkTestable(e *types.Endpoint) bool {
+	n, ok := c.Nodes[e.Node.ShortName]
+	if !ok {
+		return false
+	}
+	if _, ok := n.(nodes.LinkTester); !ok {
+		return false
+	}
+	if m, ok := n.(nodes.InterfaceMapper); ok {
+		return len(m.InterfaceMap([]string{e.EndpointName})) > 0
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/srl-labs/containerlab/nodes"
+)
+
+// fakeLinkTester records the link test addresses of a node and answers pings to reachable addresses
+type fakeLinkTester struct {
+	nodes.Node
+	reachable map[string]bool
+	added     map[string]string
+	removed   []string
+}
+
+func (n *fakeLinkTester) AddTestAddrs(_ context.Context, addrs map[string]string) error {
+	n.added = addrs
+	return nil
+}
+
+func (n *fakeLinkTester) Ping(_ context.Context, addr string) error {
+	if !n.reachable[addr] {
+		return errors.New("no reply")
+	}
+	return nil
+}
+
+func (n *fakeLinkTester) RemoveTestAddrs(_ context.Context, ifaces []string) error {
+	n.removed = ifaces
+	return nil
+}
+
+func TestTestLinks(t *testing.T) {
+	c, err := NewContainerLab(WithTopoFile("test_data/topo14.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srl1 := &fakeLinkTester{Node: c.Nodes["srl1"], reachable: map[string]bool{"100.64.0.1": true}}
+	srl2 := &fakeLinkTester{Node: c.Nodes["srl2"]}
+	c.Nodes["srl1"], c.Nodes["srl2"] = srl1, srl2
+
+	results, err := c.TestLinks(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the srl1-lin1 link is skipped as linux nodes can't be tested
+	if len(results) != 2 {
+		t.Fatalf("want 2 tested links, got %d", len(results))
+	}
+	if results[0].Err != nil {
+		t.Errorf("link %s: unexpected error: %v", results[0].Link, results[0].Err)
+	}
+	if results[1].Err == nil {
+		t.Errorf("link %s: expected an error", results[1].Link)
+	}
+
+	wantAdded := map[string]map[string]string{
+		"srl1": {"e1-1": "100.64.0.0/31", "e1-3": "100.64.0.2/31"},
+		"srl2": {"e1-1": "100.64.0.1/31", "e1-3": "100.64.0.3/31"},
+	}
+	gotAdded := map[string]map[string]string{"srl1": srl1.added, "srl2": srl2.added}
+	if d := cmp.Diff(wantAdded, gotAdded); d != "" {
+		t.Errorf("added addresses mismatch (-want +got):\n%s", d)
+	}
+	for name, n := range map[string]*fakeLinkTester{"srl1": srl1, "srl2": srl2} {
+		if d := cmp.Diff([]string{"e1-1", "e1-3"}, n.removed); d != "" {
+			t.Errorf("node %s: removed interfaces mismatch (-want +got):\n%s", name, d)
+		}
+	}
+}
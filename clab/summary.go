@@ -0,0 +1,145 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// summary is the deploy summary of the SR Linux nodes of a lab
+type summary struct {
+	Name  string        `json:"name"`
+	Nodes []summaryNode `json:"nodes"`
+}
+
+// summaryNode holds the access details of a node.
+// the TLS files are referenced by their paths, so that no secrets are written to the summary.
+type summaryNode struct {
+	Name      string          `json:"name"`
+	LongName  string          `json:"long-name"`
+	Kind      string          `json:"kind"`
+	MgmtIPv4  string          `json:"mgmt-ipv4,omitempty"`
+	MgmtIPv6  string          `json:"mgmt-ipv6,omitempty"`
+	Ports     []PublishedPort `json:"ports,omitempty"`
+	Username  string          `json:"username"`
+	TLSCert   string          `json:"tls-cert,omitempty"`
+	TLSKey    string          `json:"tls-key,omitempty"`
+	TLSRootCA string          `json:"tls-root-ca,omitempty"`
+}
+
+// PublishedPort is a container port published on the host
+type PublishedPort struct {
+	HostIP        string `json:"host-ip,omitempty"`
+	HostPort      int    `json:"host-port"`
+	ContainerPort int    `json:"container-port"`
+	Protocol      string `json:"protocol"`
+}
+
+// GenerateSummary writes the deploy summary of the SR Linux nodes to clab-<lab>-summary.json in the lab directory.
+// the summary is generated from the node state, so it must be called once the nodes are deployed.
+func (c *CLab) GenerateSummary() error {
+	if !c.hasKind(nodes.NodeKindSRL) {
+		return nil
+	}
+	f, err := os.Create(filepath.Join(c.Dir.Lab, filepath.Base(c.Dir.Lab)+"-summary.json"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.generateSummary(f)
+}
+
+// generateSummary writes the deploy summary of the SR Linux nodes to w in JSON format
+func (c *CLab) generateSummary(w io.Writer) error {
+	s := summary{
+		Name:  c.Config.Name,
+		Nodes: []summaryNode{},
+	}
+
+	var srlNodes []nodes.Node
+	for _, n := range c.Nodes {
+		if n.Config().Kind == nodes.NodeKindSRL {
+			srlNodes = append(srlNodes, n)
+		}
+	}
+	sort.Slice(srlNodes, func(i, j int) bool {
+		return srlNodes[i].Config().ShortName < srlNodes[j].Config().ShortName
+	})
+
+	for _, node := range srlNodes {
+		n := node.Config()
+		sn := summaryNode{
+			Name:     n.ShortName,
+			LongName: n.LongName,
+			Kind:     n.Kind,
+			MgmtIPv4: n.MgmtIPv4Address,
+			MgmtIPv6: n.MgmtIPv6Address,
+			Ports:    PublishedPorts(n),
+			Username: summaryUsername(node),
+		}
+		// the paths are empty for a node with TLS disabled
+		if g, ok := node.(nodes.CertPathsGetter); ok {
+			sn.TLSCert, sn.TLSKey, sn.TLSRootCA = g.GetCertPaths()
+		}
+		s.Nodes = append(s.Nodes, sn)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// summaryUsername returns the username the node is managed with or the default username of its kind
+func summaryUsername(node nodes.Node) string {
+	if g, ok := node.(nodes.CredentialsGetter); ok {
+		return g.GetCredentials().Username
+	}
+	return nodes.DefaultCredentials[node.Config().Kind][0]
+}
+
+// String returns the published port in the docker format, e.g. 127.0.0.1:2202->22/tcp
+func (p PublishedPort) String() string {
+	hostIP := p.HostIP
+	if hostIP == "" {
+		hostIP = "0.0.0.0"
+	}
+	return fmt.Sprintf("%s->%d/%s", net.JoinHostPort(hostIP, strconv.Itoa(p.HostPort)), p.ContainerPort, p.Protocol)
+}
+
+// PublishedPorts returns the ports of the node published on the host sorted by the host port
+func PublishedPorts(n *types.NodeConfig) []PublishedPort {
+	var ports []PublishedPort
+	for cPort, bindings := range n.PortBindings {
+		for _, b := range bindings {
+			hPort, err := strconv.Atoi(b.HostPort)
+			if err != nil {
+				continue
+			}
+			ports = append(ports, PublishedPort{
+				HostIP:        b.HostIP,
+				HostPort:      hPort,
+				ContainerPort: cPort.Int(),
+				Protocol:      cPort.Proto(),
+			})
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].HostPort != ports[j].HostPort {
+			return ports[i].HostPort < ports[j].HostPort
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+	return ports
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerateSummary(t *testing.T) {
+	c, err := NewContainerLab(WithTopoFile("test_data/topo15.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Nodes["node2"].Config().MgmtIPv6Address = "2001:172:100:100::12"
+	c.Nodes["node2"].Config().PortBindings = nat.PortMap{
+		"50052/tcp": {{HostPort: "57401"}},
+		"22/tcp":    {{HostIP: "127.0.0.1", HostPort: "2202"}},
+	}
+
+	var s strings.Builder
+	if err := c.generateSummary(&s); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{
+  "name": "topo15",
+  "nodes": [
+    {
+      "name": "node1",
+      "long-name": "clab-topo15-node1",
+      "kind": "srl",
+      "mgmt-ipv4": "172.100.100.11",
+      "username": "admin"
+    },
+    {
+      "name": "node2",
+      "long-name": "clab-topo15-node2",
+      "kind": "srl",
+      "mgmt-ipv4": "172.100.100.12",
+      "mgmt-ipv6": "2001:172:100:100::12",
+      "ports": [
+        {
+          "host-ip": "127.0.0.1",
+          "host-port": 2202,
+          "container-port": 22,
+          "protocol": "tcp"
+        },
+        {
+          "host-port": 57401,
+          "container-port": 50052,
+          "protocol": "tcp"
+        }
+      ],
+      "username": "clab",
+      "tls-cert": "` + c.Dir.LabCA + `/node2/node2.pem",
+      "tls-key": "` + c.Dir.LabCA + `/node2/node2-key.pem",
+      "tls-root-ca": "` + c.Dir.LabCARoot + `/root-ca.pem"
+    },
+    {
+      "name": "node3",
+      "long-name": "clab-topo15-node3",
+      "kind": "srl",
+      "mgmt-ipv4": "172.100.100.13",
+      "username": "admin",
+      "tls-cert": "` + c.Dir.LabCA + `/node3/node3.pem",
+      "tls-key": "` + c.Dir.LabCA + `/node3/node3-key.pem",
+      "tls-root-ca": "/etc/pki/corp-ca.pem"
+    },
+    {
+      "name": "node4",
+      "long-name": "clab-topo15-node4",
+      "kind": "srl",
+      "mgmt-ipv4": "172.100.100.14",
+      "username": "admin",
+      "tls-cert": "` + c.Dir.LabCA + `/node4/node4.pem",
+      "tls-key": "` + c.Dir.LabCA + `/node4/node4-key.pem",
+      "tls-root-ca": "` + c.Dir.LabCARoot + `/root-ca.pem"
+    }
+  ]
+}
+`
+	if d := cmp.Diff(want, s.String()); d != "" {
+		t.Errorf("unexpected summary (-want +got):\n%s", d)
+	}
+}
+
+func TestPublishedPortString(t *testing.T) {
+	tests := map[PublishedPort]string{
+		{HostPort: 57401, ContainerPort: 57400, Protocol: "tcp"}:                     "0.0.0.0:57401->57400/tcp",
+		{HostIP: "127.0.0.1", HostPort: 2202, ContainerPort: 22, Protocol: "tcp"}:    "127.0.0.1:2202->22/tcp",
+		{HostIP: "2001:db8::1", HostPort: 1161, ContainerPort: 161, Protocol: "udp"}: "[2001:db8::1]:1161->161/udp",
+	}
+	for p, want := range tests {
+		if got := p.String(); got != want {
+			t.Errorf("wanted %q, got %q", want, got)
+		}
+	}
+}
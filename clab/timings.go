@@ -0,0 +1,180 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// deployment phases the durations are reported for
+const (
+	phaseImagePull  = "image-pull"
+	phaseCreate     = "create"
+	phaseReady      = "ready"
+	phasePostDeploy = "post-deploy"
+
+	// extension of the node_exporter textfile collector files, the timings are written in the Prometheus text format
+	promFileExt = ".prom"
+	promMetric  = "containerlab_node_deploy_phase_seconds"
+)
+
+// DeployTimings records how long the deployment phases of each node took.
+// it is notified of the nodes lifecycle stages, so the create, ready and post-deploy phases are reported
+// only for the nodes reporting their stages, such as srl. the image pull is timed for all the nodes.
+// nothing is recorded unless the timings are registered with WithDeployTimings.
+type DeployTimings struct {
+	m sync.Mutex
+	// time each node completed its stages at
+	stages map[string]map[nodes.Stage]time.Time
+	// time taken to pull the images of each node
+	imagePull map[string]time.Duration
+}
+
+// NewDeployTimings returns empty deployment timings
+func NewDeployTimings() *DeployTimings {
+	return &DeployTimings{
+		stages:    make(map[string]map[nodes.Stage]time.Time),
+		imagePull: make(map[string]time.Duration),
+	}
+}
+
+// OnStage records the time the node completed the stage at
+func (t *DeployTimings) OnStage(nodeName string, stage nodes.Stage) {
+	t.record(nodeName, stage, time.Now())
+}
+
+func (t *DeployTimings) record(nodeName string, stage nodes.Stage, at time.Time) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.stages[nodeName] == nil {
+		t.stages[nodeName] = make(map[nodes.Stage]time.Time)
+	}
+	t.stages[nodeName][stage] = at
+}
+
+func (t *DeployTimings) addImagePull(nodeName string, d time.Duration) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.imagePull[nodeName] += d
+}
+
+// phases returns the durations of the deployment phases of each node in seconds.
+// the create phase starts once the node completed its pre-deploy stage, the ready phase once it was deployed
+// and the post-deploy phase once it was ready, or deployed when the node didn't report that it was ready.
+func (t *DeployTimings) phases() map[string]map[string]float64 {
+	t.m.Lock()
+	defer t.m.Unlock()
+	res := make(map[string]map[string]float64)
+	add := func(name, phase string, d time.Duration) {
+		if res[name] == nil {
+			res[name] = make(map[string]float64)
+		}
+		res[name][phase] = d.Seconds()
+	}
+	for name, d := range t.imagePull {
+		add(name, phaseImagePull, d)
+	}
+	for name, st := range t.stages {
+		preDeploy, okPreDeploy := st[nodes.StagePreDeploy]
+		deploy, okDeploy := st[nodes.StageDeploy]
+		ready, okReady := st[nodes.StageReady]
+		postDeploy, okPostDeploy := st[nodes.StagePostDeploy]
+		if okPreDeploy && okDeploy {
+			add(name, phaseCreate, deploy.Sub(preDeploy))
+		}
+		if okDeploy && okReady {
+			add(name, phaseReady, ready.Sub(deploy))
+		}
+		switch {
+		case okReady && okPostDeploy && !postDeploy.Before(ready):
+			add(name, phasePostDeploy, postDeploy.Sub(ready))
+		case okDeploy && okPostDeploy:
+			add(name, phasePostDeploy, postDeploy.Sub(deploy))
+		}
+	}
+	return res
+}
+
+// writeJSON writes the durations of the deployment phases of each node in seconds
+func (t *DeployTimings) writeJSON(w io.Writer) error {
+	b, err := json.MarshalIndent(t.phases(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// writeProm writes the durations of the deployment phases of each node in the Prometheus text format,
+// as expected by the textfile collector of node_exporter
+func (t *DeployTimings) writeProm(w io.Writer, lab string) error {
+	ph := t.phases()
+	names := make([]string, 0, len(ph))
+	for name := range ph {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# HELP %s Time taken by the deployment phases of the lab nodes.\n", promMetric)
+	fmt.Fprintf(buf, "# TYPE %s gauge\n", promMetric)
+	for _, name := range names {
+		for _, phase := range []string{phaseImagePull, phaseCreate, phaseReady, phasePostDeploy} {
+			if v, ok := ph[name][phase]; ok {
+				fmt.Fprintf(buf, "%s{lab=%q,node=%q,phase=%q} %g\n", promMetric, lab, name, phase, v)
+			}
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// WriteDeployTimings writes the deployment timings of the nodes to the file by path,
+// in the Prometheus text format for a file with the .prom extension and in JSON otherwise.
+// it is a no-op when the timings are not registered with WithDeployTimings.
+func (c *CLab) WriteDeployTimings(path string) error {
+	if c.timings == nil {
+		return nil
+	}
+	buf := new(bytes.Buffer)
+	var err error
+	if filepath.Ext(path) == promFileExt {
+		err = c.timings.writeProm(buf, c.Config.Name)
+	} else {
+		err = c.timings.writeJSON(buf)
+	}
+	if err != nil {
+		return err
+	}
+	// the file is replaced atomically, so that a collector never reads it half written
+	return utils.WriteFileAtomic(path, buf.Bytes(), 0644)
+}
+
+// stageHooks notifies all the hooks of the nodes lifecycle stages, nil hooks are skipped
+type stageHooks []nodes.LifecycleHook
+
+func (hs stageHooks) OnStage(nodeName string, stage nodes.Stage) {
+	for _, h := range hs {
+		nodes.NotifyStage(h, nodeName, stage)
+	}
+}
+
+// nodeLifecycleHook returns the hook the nodes report their lifecycle stages to
+func (c *CLab) nodeLifecycleHook() nodes.LifecycleHook {
+	if c.timings == nil {
+		return c.lifecycleHook
+	}
+	return stageHooks{c.timings, c.lifecycleHook}
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/srl-labs/containerlab/nodes"
+)
+
+func TestDeployTimings(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timings := NewDeployTimings()
+	timings.addImagePull("srl1", 2*time.Second)
+	timings.addImagePull("srl2", 2*time.Second)
+	timings.record("srl1", nodes.StagePreDeploy, start)
+	timings.record("srl1", nodes.StageDeploy, start.Add(1500*time.Millisecond))
+	timings.record("srl1", nodes.StageReady, start.Add(31500*time.Millisecond))
+	timings.record("srl1", nodes.StagePostDeploy, start.Add(36*time.Second))
+	// srl2 booted from a saved config and didn't report that it was ready
+	timings.record("srl2", nodes.StagePreDeploy, start)
+	timings.record("srl2", nodes.StageDeploy, start.Add(time.Second))
+	timings.record("srl2", nodes.StagePostDeploy, start.Add(3*time.Second))
+
+	var s strings.Builder
+	if err := timings.writeJSON(&s); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "srl1": {
+    "create": 1.5,
+    "image-pull": 2,
+    "post-deploy": 4.5,
+    "ready": 30
+  },
+  "srl2": {
+    "create": 1,
+    "image-pull": 2,
+    "post-deploy": 2
+  }
+}
+`
+	if d := cmp.Diff(want, s.String()); d != "" {
+		t.Fatalf("json timings mismatch (-want +got):\n%s", d)
+	}
+
+	s.Reset()
+	if err := timings.writeProm(&s, "lab1"); err != nil {
+		t.Fatal(err)
+	}
+	want = `# HELP containerlab_node_deploy_phase_seconds Time taken by the deployment phases of the lab nodes.
+# TYPE containerlab_node_deploy_phase_seconds gauge
+containerlab_node_deploy_phase_seconds{lab="lab1",node="srl1",phase="image-pull"} 2
+containerlab_node_deploy_phase_seconds{lab="lab1",node="srl1",phase="create"} 1.5
+containerlab_node_deploy_phase_seconds{lab="lab1",node="srl1",phase="ready"} 30
+containerlab_node_deploy_phase_seconds{lab="lab1",node="srl1",phase="post-deploy"} 4.5
+containerlab_node_deploy_phase_seconds{lab="lab1",node="srl2",phase="image-pull"} 2
+containerlab_node_deploy_phase_seconds{lab="lab1",node="srl2",phase="create"} 1
+containerlab_node_deploy_phase_seconds{lab="lab1",node="srl2",phase="post-deploy"} 2
+`
+	if d := cmp.Diff(want, s.String()); d != "" {
+		t.Fatalf("prometheus timings mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestNodeLifecycleHook(t *testing.T) {
+	c := &CLab{}
+	if c.nodeLifecycleHook() != nil {
+		t.Fatalf("wanted no hook when neither a hook nor the timings are registered")
+	}
+	c.timings = NewDeployTimings()
+	c.nodeLifecycleHook().OnStage("srl1", nodes.StageDeploy)
+	if _, ok := c.timings.stages["srl1"][nodes.StageDeploy]; !ok {
+		t.Fatalf("wanted the stage to be recorded by the timings")
+	}
+}
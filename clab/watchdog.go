@@ -0,0 +1,242 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// WatchOptions sets how Watch supervises the lab nodes
+type WatchOptions struct {
+	// interval the state of the nodes is checked at
+	Interval time.Duration
+	// max number of restarts of a node, the node is left exited once it crashed more times.
+	// the count is reset once the node stays up for MaxBackoff after a restart.
+	MaxRestarts int
+	// delay between the first and the second restart of a node, doubled on every following restart up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultWatchOptions are the options Watch uses unless set otherwise
+var DefaultWatchOptions = WatchOptions{
+	Interval:    5 * time.Second,
+	MaxRestarts: 5,
+	Backoff:     10 * time.Second,
+	MaxBackoff:  5 * time.Minute,
+}
+
+// restartState holds the restarts of a node
+type restartState struct {
+	restarts int
+	// time of the last restart
+	last time.Time
+	// set while the node is being restarted
+	restarting bool
+	// set once the node exceeded the max number of restarts
+	gaveUp bool
+}
+
+// watchdog restarts the SR Linux containers of the lab that exited
+type watchdog struct {
+	c    *CLab
+	opts WatchOptions
+
+	m      sync.Mutex
+	states map[string]*restartState
+	wg     sync.WaitGroup
+
+	// links the netns of the restarted container and recreates its links, replaced in tests
+	linkNS func(nspath, containerName string) error
+	wire   func(l *types.Link) error
+}
+
+func newWatchdog(c *CLab, opts WatchOptions) *watchdog {
+	return &watchdog{
+		c:      c,
+		opts:   opts,
+		states: make(map[string]*restartState),
+		linkNS: utils.LinkContainerNS,
+		wire:   c.CreateVirtualWiring,
+	}
+}
+
+// Watch supervises the SR Linux nodes of the deployed lab until ctx is done.
+// a node container that exited is started again, its links are recreated and its post-deploy tasks are run,
+// so that the default config applied by containerlab is restored.
+// the restarts of a node are delayed with a backoff and are bounded by the max number of restarts to avoid crash loops.
+func (c *CLab) Watch(ctx context.Context, opts WatchOptions) error {
+	w := newWatchdog(c, opts)
+	var watched int
+	for _, name := range w.nodeNames() {
+		n := c.Nodes[name]
+		if _, ok := n.GetRuntime().(runtime.StatusInspector); !ok {
+			log.Warnf("node %s is not watched, runtime %s can't report the state of its container", name, n.GetRuntime().GetName())
+			continue
+		}
+		watched++
+	}
+	if watched == 0 {
+		return fmt.Errorf("the lab has no nodes that can be watched")
+	}
+	log.Infof("watching %d nodes, press Ctrl+C to stop", watched)
+
+	t := time.NewTicker(opts.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			w.wg.Wait()
+			return nil
+		case <-t.C:
+			w.check(ctx, time.Now())
+		}
+	}
+}
+
+// nodeNames returns the sorted names of the supervised nodes
+func (w *watchdog) nodeNames() []string {
+	var names []string
+	for name, n := range w.c.Nodes {
+		if n.Config().Kind == nodes.NodeKindSRL {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// check restarts the nodes that exited and are due for a restart at now
+func (w *watchdog) check(ctx context.Context, now time.Time) {
+	for _, name := range w.nodeNames() {
+		n := w.c.Nodes[name]
+		si, ok := n.GetRuntime().(runtime.StatusInspector)
+		if !ok {
+			continue
+		}
+
+		w.m.Lock()
+		st := w.states[name]
+		if st == nil {
+			st = &restartState{}
+			w.states[name] = st
+		}
+		busy := st.restarting || st.gaveUp
+		w.m.Unlock()
+		if busy {
+			continue
+		}
+
+		status, err := si.ContainerStatus(ctx, n.Config().LongName)
+		if err != nil {
+			log.Debugf("failed to get the state of node %s: %v", name, err)
+			continue
+		}
+
+		w.m.Lock()
+		if !status.Exited() {
+			if st.restarts > 0 && now.Sub(st.last) >= w.opts.MaxBackoff {
+				st.restarts = 0
+			}
+			w.m.Unlock()
+			continue
+		}
+		if st.restarts >= w.opts.MaxRestarts {
+			st.gaveUp = true
+			w.m.Unlock()
+			log.Errorf("node %s %s and was restarted %d times already, it is not restarted anymore",
+				name, exitReason(status.ExitCode), st.restarts)
+			continue
+		}
+		if st.restarts > 0 && now.Before(st.last.Add(w.backoff(st.restarts))) {
+			w.m.Unlock()
+			continue
+		}
+		st.restarts++
+		st.last = now
+		st.restarting = true
+		attempt := st.restarts
+		w.m.Unlock()
+
+		log.Warnf("node %s %s, restarting it (attempt %d of %d)", name, exitReason(status.ExitCode), attempt, w.opts.MaxRestarts)
+		w.wg.Add(1)
+		go func(name string, n nodes.Node) {
+			defer w.wg.Done()
+			if err := w.restart(ctx, n); err != nil {
+				log.Errorf("failed to restart node %s: %v", name, err)
+			} else {
+				log.Infof("node %s restarted", name)
+			}
+			w.m.Lock()
+			w.states[name].restarting = false
+			w.m.Unlock()
+		}(name, n)
+	}
+}
+
+// backoff returns the delay before the restart that follows the given number of restarts
+func (w *watchdog) backoff(restarts int) time.Duration {
+	d := w.opts.Backoff
+	for i := 1; i < restarts && d < w.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > w.opts.MaxBackoff {
+		d = w.opts.MaxBackoff
+	}
+	return d
+}
+
+// restart starts the exited container of the node, recreates its links, which are gone with its netns,
+// and runs its post-deploy tasks
+func (w *watchdog) restart(ctx context.Context, n nodes.Node) error {
+	cfg := n.Config()
+	r := n.GetRuntime()
+	if err := r.StartContainer(ctx, cfg.LongName); err != nil {
+		return err
+	}
+	nsPath, err := r.GetNSPath(ctx, cfg.LongName)
+	if err != nil {
+		return err
+	}
+	cfg.NSPath = nsPath
+	if err := w.linkNS(nsPath, cfg.LongName); err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(w.c.Links))
+	for id := range w.c.Links {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		l := w.c.Links[id]
+		if l.A.Node != cfg && l.B.Node != cfg {
+			continue
+		}
+		if err := w.wire(l); err != nil {
+			return fmt.Errorf("failed to recreate the %s: %v", l, err)
+		}
+	}
+	return n.PostDeploy(ctx, w.c.Nodes)
+}
+
+// exitReason describes how the container exited by its exit code,
+// the codes above 128 are reported by the runtimes for the processes killed by a signal
+func exitReason(code int) string {
+	if code > 128 && code < 160 {
+		return fmt.Sprintf("was killed by signal %d (exit code %d)", code-128, code)
+	}
+	return fmt.Sprintf("exited with code %d", code)
+}
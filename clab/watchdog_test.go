@@ -0,0 +1,129 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// fakeWatchRuntime reports the container states and records the started containers
+type fakeWatchRuntime struct {
+	runtime.ContainerRuntime
+	states  map[string]*runtime.ContainerStatus
+	started []string
+}
+
+func (r *fakeWatchRuntime) ContainerStatus(_ context.Context, id string) (*runtime.ContainerStatus, error) {
+	return r.states[id], nil
+}
+
+func (r *fakeWatchRuntime) StartContainer(_ context.Context, id string) error {
+	r.started = append(r.started, id)
+	return nil
+}
+
+func (*fakeWatchRuntime) GetNSPath(_ context.Context, id string) (string, error) {
+	return "/proc/1/ns/net", nil
+}
+
+// fakeWatchedNode counts the post-deploy runs of a node
+type fakeWatchedNode struct {
+	nodes.Node
+	rt          *fakeWatchRuntime
+	postDeploys int
+}
+
+func (n *fakeWatchedNode) GetRuntime() runtime.ContainerRuntime { return n.rt }
+
+func (n *fakeWatchedNode) PostDeploy(_ context.Context, _ map[string]nodes.Node) error {
+	n.postDeploys++
+	return nil
+}
+
+func TestWatchdogRestarts(t *testing.T) {
+	c, err := NewContainerLab(WithTopoFile("test_data/topo14.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := &fakeWatchRuntime{states: map[string]*runtime.ContainerStatus{
+		"clab-topo14-srl1": {State: runtime.ContainerStateExited, ExitCode: 137},
+		"clab-topo14-srl2": {State: "running"},
+	}}
+	srl1 := &fakeWatchedNode{Node: c.Nodes["srl1"], rt: rt}
+	srl2 := &fakeWatchedNode{Node: c.Nodes["srl2"], rt: rt}
+	c.Nodes["srl1"], c.Nodes["srl2"] = srl1, srl2
+
+	w := newWatchdog(c, WatchOptions{MaxRestarts: 2, Backoff: 10 * time.Second, MaxBackoff: time.Minute})
+	w.linkNS = func(_, _ string) error { return nil }
+	var wired int
+	w.wire = func(_ *types.Link) error {
+		wired++
+		return nil
+	}
+
+	start := time.Now()
+	for _, tc := range []struct {
+		after    time.Duration
+		restarts int
+	}{
+		// the first restart is immediate
+		{0, 1},
+		// the second one is delayed by the backoff
+		{5 * time.Second, 1},
+		{10 * time.Second, 2},
+		// the node is not restarted more than the max number of restarts
+		{time.Minute, 2},
+	} {
+		w.check(context.Background(), start.Add(tc.after))
+		w.wg.Wait()
+		if len(rt.started) != tc.restarts || srl1.postDeploys != tc.restarts {
+			t.Fatalf("after %s: want %d restarts, got %d starts and %d post-deploys",
+				tc.after, tc.restarts, len(rt.started), srl1.postDeploys)
+		}
+	}
+	for _, name := range rt.started {
+		if name != "clab-topo14-srl1" {
+			t.Fatalf("want only clab-topo14-srl1 restarted, got %s", name)
+		}
+	}
+	if srl2.postDeploys != 0 {
+		t.Fatalf("want running srl2 left untouched, got %d post-deploys", srl2.postDeploys)
+	}
+	// all the three links of srl1 are recreated on every restart
+	if wired != 6 {
+		t.Fatalf("want 6 recreated links, got %d", wired)
+	}
+}
+
+func TestWatchdogBackoff(t *testing.T) {
+	w := newWatchdog(&CLab{}, WatchOptions{Backoff: 10 * time.Second, MaxBackoff: 30 * time.Second})
+	for restarts, want := range map[int]time.Duration{
+		1: 10 * time.Second,
+		2: 20 * time.Second,
+		3: 30 * time.Second,
+		8: 30 * time.Second,
+	} {
+		if got := w.backoff(restarts); got != want {
+			t.Errorf("backoff after %d restarts: want %s, got %s", restarts, want, got)
+		}
+	}
+}
+
+func TestExitReason(t *testing.T) {
+	for code, want := range map[int]string{
+		1:   "exited with code 1",
+		137: "was killed by signal 9 (exit code 137)",
+	} {
+		if got := exitReason(code); got != want {
+			t.Errorf("exit code %d: want %q, got %q", code, want, got)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/runtime"
+)
+
+// directory the diagnostics are collected to
+var collectDir string
+
+// collectCmd represents the collect command
+var collectCmd = &cobra.Command{
+	Use:   "collect",
+	Short: "collect diagnostics of the lab nodes for bug reports",
+	Long: `collect gathers the diagnostics of the running lab nodes, e.g. the tech-support archive and the container log of SR Linux nodes.
+Refer to the https://containerlab.srlinux.dev/cmd/collect/ documentation to see the kinds that support it`,
+	PreRunE: sudoCheck,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if topo == "" {
+			return errors.New("provide topology file path with --topo flag")
+		}
+		opts := []clab.ClabOption{
+			clab.WithTimeout(timeout),
+			clab.WithTopoFile(topo, varsFile),
+			clab.WithRuntime(rt,
+				&runtime.RuntimeConfig{
+					Debug:            debug,
+					Timeout:          timeout,
+					GracefulShutdown: graceful,
+				},
+			),
+		}
+		c, err := clab.NewContainerLab(opts...)
+		if err != nil {
+			return err
+		}
+
+		dir := collectDir
+		if dir == "" {
+			dir = filepath.Join(c.Dir.Lab, "diagnostics")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		if err := c.CollectDiagnostics(ctx, dir); err != nil {
+			return err
+		}
+		log.Infof("diagnostics collected to %s", dir)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(collectCmd)
+	collectCmd.Flags().StringVarP(&collectDir, "dir", "", "", "directory to collect the diagnostics to, defaults to the diagnostics directory in the lab directory")
+}
@@ -10,9 +10,11 @@ import (
 	"fmt"
 	"net"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"sort"
 	"strings"
 	"sync"
+	"syscall"
 
 	cfssllog "github.com/cloudflare/cfssl/log"
 	log "github.com/sirupsen/logrus"
@@ -38,6 +40,34 @@ var reconfigure bool
 // max-workers flag
 var maxWorkers uint
 
+// max-cert-workers flag
+var maxCertWorkers uint
+
+// dry-run flag
+var dryRun bool
+
+// fail the deployment when a node image is incompatible with the node settings
+var strict bool
+
+// verify-links flag
+var verifyLinks bool
+
+// keep-failed flag
+var keepFailed bool
+
+// path to the file the deployment timings of the nodes are written to
+var metricsFile string
+
+// path to the ansible inventory file, the lab dir is used when empty
+var ansibleInventory string
+
+// preserve-topology flag
+var preserveTopology bool
+
+// watch flag and the max number of restarts of a watched node
+var watch bool
+var watchMaxRestarts int
+
 // deployCmd represents the deploy command
 var deployCmd = &cobra.Command{
 	Use:          "deploy",
@@ -48,8 +78,29 @@ var deployCmd = &cobra.Command{
 	PreRunE:      sudoCheck,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var err error
+		if dryRun && reconfigure {
+			return fmt.Errorf("--dry-run and --reconfigure flags can't be used together")
+		}
+		if dryRun && watch {
+			return fmt.Errorf("--dry-run and --watch flags can't be used together")
+		}
+		if preserveTopology && reconfigure {
+			return fmt.Errorf("--preserve-topology and --reconfigure flags can't be used together")
+		}
+		if watchMaxRestarts < 0 {
+			return fmt.Errorf("--watch-max-restarts flag can't be negative")
+		}
 		opts := []clab.ClabOption{
 			clab.WithTimeout(timeout),
+		}
+		// the nodes are timed only when the timings are requested
+		if metricsFile != "" {
+			opts = append(opts, clab.WithDeployTimings(clab.NewDeployTimings()))
+		}
+		if preserveTopology {
+			opts = append(opts, clab.WithPreserveTopology())
+		}
+		opts = append(opts,
 			clab.WithTopoFile(topo, varsFile),
 			clab.WithRuntime(rt,
 				&runtime.RuntimeConfig{
@@ -58,7 +109,7 @@ var deployCmd = &cobra.Command{
 					GracefulShutdown: graceful,
 				},
 			),
-		}
+		)
 		c, err := clab.NewContainerLab(opts...)
 		if err != nil {
 			return err
@@ -93,12 +144,23 @@ var deployCmd = &cobra.Command{
 			return err
 		}
 
+		if err = c.CheckHost(strict); err != nil {
+			return err
+		}
+
+		if err = c.CheckNodeImages(ctx, strict); err != nil {
+			return err
+		}
+
 		log.Info("Creating lab directory: ", c.Dir.Lab)
 		utils.CreateDirectory(c.Dir.Lab, 0755)
 
 		// create an empty ansible inventory file that will get populated later
 		// we create it here first, so that bind mounts of ansible-inventory.yml file could work
-		ansibleInvFPath := filepath.Join(c.Dir.Lab, "ansible-inventory.yml")
+		ansibleInvFPath := ansibleInventory
+		if ansibleInvFPath == "" {
+			ansibleInvFPath = c.AnsibleInventoryPath()
+		}
 		_, err = os.Create(ansibleInvFPath)
 		if err != nil {
 			return err
@@ -111,6 +173,14 @@ var deployCmd = &cobra.Command{
 		if err := cert.CreateRootCA(c.Config.Name, c.Dir.LabCARoot, c.Nodes); err != nil {
 			return err
 		}
+		if err := c.GenerateNodeCerts(maxCertWorkers); err != nil {
+			return err
+		}
+
+		if dryRun {
+			log.Info("Dry run: rendering nodes config artifacts, no containers will be created")
+			return c.RenderNodes(os.Stdout)
+		}
 
 		// create docker network or use existing one
 		if err = c.GlobalRuntime().CreateNet(ctx); err != nil {
@@ -151,8 +221,11 @@ var deployCmd = &cobra.Command{
 			}
 		}
 
+		// the user defined entries of the nodes come first
 		for _, n := range c.Nodes {
-			n.Config().ExtraHosts = extraHosts
+			hosts := make([]string, 0, len(n.Config().ExtraHosts)+len(extraHosts))
+			hosts = append(hosts, n.Config().ExtraHosts...)
+			n.Config().ExtraHosts = append(hosts, extraHosts...)
 		}
 
 		nodesStaticWg, nodesDynWg := c.CreateNodes(ctx, nodeWorkers, serialNodes)
@@ -176,30 +249,77 @@ var deployCmd = &cobra.Command{
 		log.Debug("enriching nodes with IP information...")
 		enrichNodes(containers, c.Nodes)
 
-		if err := c.GenerateInventories(); err != nil {
-			return err
+		// nodes that failed to deploy or to become ready, keyed by node name
+		failed := make(map[string]error)
+		failedMu := &sync.Mutex{}
+		for name, node := range c.Nodes {
+			if node.Config().DeploymentStatus != "created" {
+				failed[name] = fmt.Errorf("failed to deploy")
+			}
 		}
 
 		wg := &sync.WaitGroup{}
 		wg.Add(len(c.Nodes))
 
-		for _, node := range c.Nodes {
-			go func(node nodes.Node, wg *sync.WaitGroup) {
+		// a node's ready channel is closed once its postdeploy stage is done,
+		// nodes listed in wait-for of other nodes also need to report they are ready,
+		// with the keep-failed flag all nodes need to report they are ready
+		ready := make(map[string]chan struct{}, len(c.Nodes))
+		waitedFor := make(map[string]bool)
+		for name, node := range c.Nodes {
+			ready[name] = make(chan struct{})
+			for _, dep := range node.Config().WaitFor {
+				waitedFor[dep] = true
+			}
+		}
+
+		for name, node := range c.Nodes {
+			go func(name string, node nodes.Node, wg *sync.WaitGroup) {
 				defer wg.Done()
+				defer close(ready[name])
+				for _, dep := range node.Config().WaitFor {
+					log.Infof("node %s is waiting for node %s to be ready", name, dep)
+					<-ready[dep]
+				}
 				err := node.PostDeploy(ctx, c.Nodes)
 				if err != nil {
 					log.Errorf("failed to run postdeploy task for node %s: %v", node.Config().ShortName, err)
+					failedMu.Lock()
+					failed[name] = err
+					failedMu.Unlock()
 				}
-			}(node, wg)
+				if r, ok := node.(nodes.ReadyChecker); ok && (waitedFor[name] || keepFailed) {
+					if err := r.Ready(ctx); err != nil {
+						log.Errorf("node %s is not ready: %v", name, err)
+						failedMu.Lock()
+						failed[name] = err
+						failedMu.Unlock()
+					}
+				}
+			}(name, node, wg)
 		}
 		wg.Wait()
 
+		var linksErr error
+		if verifyLinks {
+			linksErr = verifyLabLinks(ctx, c)
+		}
+
 		// Update containers after postDeploy action
 		containers, err = c.ListContainers(ctx, labels)
 		if err != nil {
 			return err
 		}
 
+		// the inventories are generated once the post-deploy stage is done, so that only the running nodes are listed
+		if err := c.GenerateInventories(ansibleInvFPath, containers); err != nil {
+			return err
+		}
+
+		if err := c.GenerateSummary(); err != nil {
+			log.Errorf("failed to generate deploy summary: %v", err)
+		}
+
 		// generate graph of the lab topology
 		if graph {
 			if err = c.GenerateGraph(topo); err != nil {
@@ -239,7 +359,29 @@ var deployCmd = &cobra.Command{
 		// print table summary
 		printContainerInspect(c, containers, format)
 
-		return nil
+		if metricsFile != "" {
+			if err := c.WriteDeployTimings(metricsFile); err != nil {
+				log.Errorf("failed to write the deployment timings to %s: %v", metricsFile, err)
+			}
+		}
+
+		if keepFailed && len(failed) != 0 {
+			reportFailedNodes(ctx, c, failed)
+			return failedNodesErr(failed)
+		}
+
+		if watch {
+			if linksErr != nil {
+				log.Error(linksErr)
+			}
+			sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			opts := clab.DefaultWatchOptions
+			opts.MaxRestarts = watchMaxRestarts
+			return c.Watch(sigCtx, opts)
+		}
+
+		return linksErr
 	},
 }
 
@@ -251,6 +393,52 @@ func init() {
 	deployCmd.Flags().IPNetVarP(&mgmtIPv6Subnet, "ipv6-subnet", "6", net.IPNet{}, "management network IPv6 subnet range")
 	deployCmd.Flags().BoolVarP(&reconfigure, "reconfigure", "", false, "regenerate configuration artifacts and overwrite the previous ones if any")
 	deployCmd.Flags().UintVarP(&maxWorkers, "max-workers", "", 0, "limit the maximum number of workers creating nodes and virtual wires")
+	deployCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "render the nodes config artifacts to the lab directory and print the configs applied after boot, without creating any containers")
+	deployCmd.Flags().BoolVarP(&strict, "strict", "", false, "fail the deployment when a node image is known to be incompatible with the node settings, e.g. the SR Linux node type, or the container host doesn't meet the node requirements")
+	deployCmd.Flags().BoolVarP(&verifyLinks, "verify-links", "", false, "test the connectivity of the links between SR Linux nodes with temporary addresses once the lab is deployed")
+	deployCmd.Flags().BoolVarP(&keepFailed, "keep-failed", "", false, "keep the containers of the nodes that failed to deploy or to become ready, print their names and exit with an error")
+	deployCmd.Flags().StringVarP(&metricsFile, "metrics-file", "", "", "write how long the deployment phases of each node took to the file, in the Prometheus text format for a .prom file and in JSON otherwise")
+	deployCmd.Flags().StringVarP(&ansibleInventory, "ansible-inventory", "", "", "path to the generated ansible inventory file, defaults to ansible-inventory.yml in the lab directory")
+	deployCmd.Flags().BoolVarP(&watch, "watch", "", false, "block once the lab is deployed and restart the SR Linux nodes whose containers exit, until interrupted")
+	deployCmd.Flags().IntVarP(&watchMaxRestarts, "watch-max-restarts", "", clab.DefaultWatchOptions.MaxRestarts, "max number of restarts of a node crashing repeatedly with the --watch flag")
+	deployCmd.Flags().BoolVarP(&preserveTopology, "preserve-topology", "", false, "keep the SR Linux topology files generated by a previous deployment, so that the nodes keep their base mac addresses")
+	deployCmd.Flags().UintVarP(&maxCertWorkers, "max-cert-workers", "", 0, "limit the maximum number of workers generating node certificates, defaults to the number of CPUs")
+}
+
+// reportFailedNodes logs the container name and state of the failed nodes,
+// so that the containers kept with the --keep-failed flag can be inspected.
+func reportFailedNodes(ctx context.Context, c *clab.CLab, failed map[string]error) {
+	for _, name := range sortedNames(failed) {
+		n, ok := c.Nodes[name]
+		if !ok {
+			continue
+		}
+		state := "unknown"
+		if si, ok := n.GetRuntime().(runtime.StatusInspector); ok {
+			if st, err := si.ContainerStatus(ctx, n.Config().LongName); err == nil {
+				state = st.State
+				if st.Exited() {
+					state = fmt.Sprintf("%s with exit code %d", st.State, st.ExitCode)
+				}
+			}
+		}
+		log.Errorf("node %s failed: %v; container %s is kept for inspection, state: %s", name, failed[name], n.Config().LongName, state)
+	}
+	log.Infof("use the logs and exec commands of the %s runtime to inspect the failed containers, remove them with the destroy command", c.GlobalRuntime().GetName())
+}
+
+// failedNodesErr returns the error listing the failed nodes in alphabetical order.
+func failedNodesErr(failed map[string]error) error {
+	return fmt.Errorf("nodes %q failed to deploy or to become ready", sortedNames(failed))
+}
+
+func sortedNames(m map[string]error) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func setFlags(conf *clab.Config) {
@@ -268,6 +456,33 @@ func setFlags(conf *clab.Config) {
 	}
 }
 
+// verifyLabLinks runs the connectivity test of the lab links and logs the result of each link.
+// an error is returned if any of the links failed the test.
+func verifyLabLinks(ctx context.Context, c *clab.CLab) error {
+	log.Info("Verifying links connectivity...")
+	results, err := c.TestLinks(ctx)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		log.Info("no links between SR Linux nodes to verify")
+		return nil
+	}
+	var failed int
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			log.Errorf("%s: FAIL: %v", r.Link, r.Err)
+			continue
+		}
+		log.Infof("%s: PASS", r.Link)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d links failed the connectivity test", failed, len(results))
+	}
+	return nil
+}
+
 func enrichNodes(containers []types.GenericContainer, nodesMap map[string]nodes.Node) {
 	for _, c := range containers {
 		name = c.Labels[clab.NodeNameLabel]
@@ -0,0 +1,21 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFailedNodesErr(t *testing.T) {
+	failed := map[string]error{
+		"srl2": errors.New("container exited"),
+		"srl1": errors.New("failed to deploy"),
+	}
+	want := `nodes ["srl1" "srl2"] failed to deploy or to become ready`
+	if err := failedNodesErr(failed); err.Error() != want {
+		t.Fatalf("wanted error %q, got %q", want, err)
+	}
+}
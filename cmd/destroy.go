@@ -13,14 +13,18 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/nodes"
 	"github.com/srl-labs/containerlab/runtime"
 	"github.com/srl-labs/containerlab/types"
 )
 
 var (
-	cleanup     bool
-	graceful    bool
-	keepMgmtNet bool
+	cleanup       bool
+	graceful      bool
+	keepMgmtNet   bool
+	saveOnDestroy bool
+	purge         bool
+	saveStrict    bool
 )
 
 // destroyCmd represents the destroy command
@@ -33,6 +37,10 @@ var destroyCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var err error
 		var labs []*clab.CLab
+		// the saved configs are kept in the lab directory
+		if saveOnDestroy && cleanup {
+			return fmt.Errorf("--save-on-destroy and --cleanup flags can't be used together")
+		}
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
@@ -116,6 +124,9 @@ func init() {
 	destroyCmd.Flags().BoolVarP(&all, "all", "a", false, "destroy all containerlab labs")
 	destroyCmd.Flags().UintVarP(&maxWorkers, "max-workers", "", 0, "limit the maximum number of workers deleting nodes")
 	destroyCmd.Flags().BoolVarP(&keepMgmtNet, "keep-mgmt-net", "", false, "do not remove the management network")
+	destroyCmd.Flags().BoolVarP(&saveOnDestroy, "save-on-destroy", "", false, "save the config of the running nodes before destroying the lab")
+	destroyCmd.Flags().BoolVarP(&purge, "purge", "", false, "delete the named volumes of the nodes, which are kept by default")
+	destroyCmd.Flags().BoolVarP(&saveStrict, "strict", "", false, "do not destroy the lab if a node fails to save its config with --save-on-destroy")
 }
 
 func destroyLab(ctx context.Context, c *clab.CLab) (err error) {
@@ -126,9 +137,19 @@ func destroyLab(ctx context.Context, c *clab.CLab) (err error) {
 		return err
 	}
 	if len(containers) == 0 {
+		// the volumes outlive the containers, so they can be purged after the lab was destroyed
+		if purge {
+			return c.DeleteVolumes(ctx)
+		}
 		return nil
 	}
 
+	if saveOnDestroy {
+		if err := saveRunningNodes(ctx, c, containers, saveStrict); err != nil {
+			return err
+		}
+	}
+
 	var labDir string
 	if cleanup {
 		labDir = filepath.Dir(containers[0].Labels["clab-node-lab-dir"])
@@ -164,6 +185,12 @@ func destroyLab(ctx context.Context, c *clab.CLab) (err error) {
 		}
 	}
 
+	if purge {
+		if err := c.DeleteVolumes(ctx); err != nil {
+			log.Error(err)
+		}
+	}
+
 	log.Info("Removing containerlab host entries from /etc/hosts file")
 	err = clab.DeleteEntriesFromHostsFile(c.Config.Name)
 	if err != nil {
@@ -182,3 +209,30 @@ func destroyLab(ctx context.Context, c *clab.CLab) (err error) {
 	// delete container network namespaces symlinks
 	return c.DeleteNetnsSymlinks()
 }
+
+// saveRunningNodes saves the config of the lab nodes whose containers are running.
+// failures to save the config are logged, and returned as an error only when strict is set.
+func saveRunningNodes(ctx context.Context, c *clab.CLab, containers []types.GenericContainer, strict bool) error {
+	var running []nodes.Node
+	for _, cont := range containers {
+		n, ok := c.Nodes[cont.Labels[clab.NodeNameLabel]]
+		if !ok {
+			continue
+		}
+		if cont.State != "running" {
+			log.Debugf("node %s is not running, skipping config save", n.Config().ShortName)
+			continue
+		}
+		running = append(running, n)
+	}
+
+	log.Infof("Saving the config of the running nodes of lab %s", c.Config.Name)
+	errs := saveNodesConfig(ctx, running)
+	for _, err := range errs {
+		log.Errorf("failed to save config: %v", err)
+	}
+	if strict && len(errs) != 0 {
+		return fmt.Errorf("failed to save the config of %d node(s), lab %s is not destroyed", len(errs), c.Config.Name)
+	}
+	return nil
+}
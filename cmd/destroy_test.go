@@ -0,0 +1,106 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// fakeSaveNode is a node that records whether its config was saved
+type fakeSaveNode struct {
+	nodes.Node
+	cfg     *types.NodeConfig
+	saveErr error
+	saved   bool
+}
+
+func (n *fakeSaveNode) Config() *types.NodeConfig { return n.cfg }
+
+func (n *fakeSaveNode) SaveConfig(_ context.Context) error {
+	n.saved = true
+	return n.saveErr
+}
+
+func TestSaveRunningNodes(t *testing.T) {
+	tests := map[string]struct {
+		states    map[string]string
+		saveErrs  map[string]error
+		strict    bool
+		wantSaved []string
+		wantErr   bool
+	}{
+		"non_running_skipped": {
+			states:    map[string]string{"n1": "running", "n2": "exited"},
+			wantSaved: []string{"n1"},
+		},
+		"save_error_logged": {
+			states:    map[string]string{"n1": "running", "n2": "running"},
+			saveErrs:  map[string]error{"n2": errors.New("save failed")},
+			wantSaved: []string{"n1", "n2"},
+		},
+		"save_error_strict": {
+			states:    map[string]string{"n1": "running", "n2": "running"},
+			saveErrs:  map[string]error{"n2": errors.New("save failed")},
+			strict:    true,
+			wantSaved: []string{"n1", "n2"},
+			wantErr:   true,
+		},
+		"non_running_strict": {
+			states:    map[string]string{"n1": "running", "n2": "exited"},
+			saveErrs:  map[string]error{"n2": errors.New("save failed")},
+			strict:    true,
+			wantSaved: []string{"n1"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &clab.CLab{
+				Config: &clab.Config{Name: "test"},
+				Nodes:  map[string]nodes.Node{},
+			}
+			fakes := map[string]*fakeSaveNode{}
+			var containers []types.GenericContainer
+			for name, state := range tc.states {
+				n := &fakeSaveNode{
+					cfg:     &types.NodeConfig{ShortName: name},
+					saveErr: tc.saveErrs[name],
+				}
+				fakes[name] = n
+				c.Nodes[name] = n
+				containers = append(containers, types.GenericContainer{
+					State:  state,
+					Labels: map[string]string{clab.NodeNameLabel: name},
+				})
+			}
+			// containers of other labs or unknown nodes are ignored
+			containers = append(containers, types.GenericContainer{
+				State:  "running",
+				Labels: map[string]string{clab.NodeNameLabel: "unknown"},
+			})
+
+			err := saveRunningNodes(context.Background(), c, containers, tc.strict)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("wanted error %v, got %v", tc.wantErr, err)
+			}
+
+			want := map[string]bool{}
+			for _, name := range tc.wantSaved {
+				want[name] = true
+			}
+			for name, n := range fakes {
+				if n.saved != want[name] {
+					t.Fatalf("node %s: wanted saved %v, got %v", name, want[name], n.saved)
+				}
+			}
+		})
+	}
+}
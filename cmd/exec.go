@@ -9,9 +9,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/google/shlex"
+	"github.com/olekukonko/tablewriter"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/srl-labs/containerlab/clab"
@@ -23,11 +27,15 @@ var (
 	labels      []string
 	execFormat  string
 	execCommand string
+	// run the command in an interactive TTY session on a single node
+	execInteractive bool
+	// max number of nodes the command runs on concurrently
+	execMaxWorkers uint
 )
 
 // execCmd represents the exec command
 var execCmd = &cobra.Command{
-	Use:     "exec",
+	Use:     "exec [-- command...] | --interactive node [command...]",
 	Short:   "execute a command on one or multiple containers",
 	PreRunE: sudoCheck,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -36,16 +44,51 @@ var execCmd = &cobra.Command{
 
 		}
 
-		if execCommand == "" {
-			return errors.New("provide command to execute")
+		var iNode string
+		var iCmd []string
+		if execInteractive {
+			if len(args) == 0 {
+				return errors.New("provide the name of the node to start the interactive session on")
+			}
+			iNode, iCmd = args[0], args[1:]
+			if execCommand != "" {
+				var err error
+				if iCmd, err = shlex.Split(execCommand); err != nil {
+					return err
+				}
+			}
+			if len(iCmd) == 0 {
+				return errors.New("provide command to execute")
+			}
+		}
+
+		var execArgs []string
+		if !execInteractive {
+			var err error
+			switch {
+			case execCommand != "":
+				execArgs, err = shlex.Split(execCommand)
+			case len(args) == 1:
+				// the command passed after -- as a single quoted string
+				execArgs, err = shlex.Split(args[0])
+			default:
+				execArgs = args
+			}
+			if err != nil {
+				return err
+			}
+			if len(execArgs) == 0 {
+				return errors.New("provide command to execute")
+			}
 		}
 
 		switch execFormat {
 		case "json",
-			"plain":
+			"plain",
+			"table":
 			// expected values, go on
 		default:
-			log.Error("format is expected to be either json or plain")
+			return errors.New("format is expected to be one of json, plain or table")
 		}
 		opts := []clab.ClabOption{
 			clab.WithTimeout(timeout),
@@ -71,6 +114,9 @@ var execCmd = &cobra.Command{
 
 		filters := []*types.GenericFilter{{FilterType: "label", Match: name, Field: "containerlab", Operator: "="}}
 		filters = append(filters, types.FilterFromLabelStrings(labels)...)
+		if execInteractive {
+			filters = append(filters, &types.GenericFilter{FilterType: "label", Match: iNode, Field: clab.NodeNameLabel, Operator: "="})
+		}
 		containers, err := c.ListContainers(ctx, filters)
 		if err != nil {
 			return err
@@ -80,7 +126,19 @@ var execCmd = &cobra.Command{
 			return errors.New("no containers found")
 		}
 
-		jsonResult := make(map[string]map[string]map[string]interface{})
+		if execInteractive {
+			cont := containers[0]
+			if cont.State != "running" || len(cont.Names) == 0 {
+				return fmt.Errorf("node %s is not running", iNode)
+			}
+			nodeRuntime, err := c.GetNodeRuntime(strings.TrimPrefix(cont.Names[0], "/"))
+			if err != nil {
+				return err
+			}
+			return nodeRuntime.ExecInteractive(ctx, cont.ID, iCmd)
+		}
+
+		targets := make(map[string]runtime.ContainerRuntime)
 		for _, cont := range containers {
 			if cont.State != "running" {
 				continue
@@ -88,29 +146,99 @@ var execCmd = &cobra.Command{
 			if len(cont.Names) == 0 {
 				continue
 			}
-			nodeRuntime, err := c.GetNodeRuntime(strings.TrimPrefix(cont.Names[0], "/"))
+			contName := strings.TrimLeft(cont.Names[0], "/")
+			nodeRuntime, err := c.GetNodeRuntime(contName)
 			if err != nil {
 				return err
 			}
+			targets[contName] = nodeRuntime
+		}
 
-			contName := strings.TrimLeft(cont.Names[0], "/")
-			if jsonResult[contName], err = execCmds(
-				ctx, cont, nodeRuntime, []string{execCommand}, execFormat,
-			); err != nil {
-				return err
-			}
+		results := runtime.BulkExec(ctx, targets, execArgs, execMaxWorkers)
+		if err := printExecResults(results, execFormat); err != nil {
+			return err
 		}
-		if execFormat == "json" {
-			result, err := json.Marshal(jsonResult)
-			if err != nil {
-				log.Errorf("Issue converting to json %v", err)
+
+		var failed []string
+		for contName, res := range results {
+			if res.Error != "" || res.ExitCode != 0 {
+				failed = append(failed, contName)
 			}
-			fmt.Println(string(result))
 		}
-		return err
+		if len(failed) > 0 {
+			sort.Strings(failed)
+			return fmt.Errorf("command failed on %d node(s): %s", len(failed), strings.Join(failed, ", "))
+		}
+		return nil
 	},
 }
 
+// printExecResults prints the results of a command executed on multiple containers in the given format
+func printExecResults(results map[string]*runtime.ExecResult, format string) error {
+	contNames := make([]string, 0, len(results))
+	for contName := range results {
+		contNames = append(contNames, contName)
+	}
+	sort.Strings(contNames)
+
+	switch format {
+	case "json":
+		jsonResult := make(map[string]map[string]interface{})
+		for contName, res := range results {
+			var doc interface{}
+			r := map[string]interface{}{
+				"stdout":    res.Stdout,
+				"stderr":    res.Stderr,
+				"exit-code": res.ExitCode,
+			}
+			if json.Unmarshal([]byte(res.Stdout), &doc) == nil {
+				r["stdout"] = doc
+			}
+			if res.Error != "" {
+				r["error"] = res.Error
+			}
+			jsonResult[contName] = r
+		}
+		b, err := json.MarshalIndent(jsonResult, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal exec results: %v", err)
+		}
+		fmt.Println(string(b))
+	case "table":
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Name", "Exit Code", "Stdout", "Stderr"})
+		table.SetAutoFormatHeaders(false)
+		table.SetAutoWrapText(false)
+		for _, contName := range contNames {
+			res := results[contName]
+			stderr := res.Stderr
+			if res.Error != "" {
+				stderr = res.Error
+			}
+			table.Append([]string{contName, strconv.Itoa(res.ExitCode), strings.TrimSpace(res.Stdout), strings.TrimSpace(stderr)})
+		}
+		table.Render()
+	default:
+		for _, contName := range contNames {
+			res := results[contName]
+			if res.Error != "" {
+				log.Errorf("%s: failed to execute cmd: %s", contName, res.Error)
+				continue
+			}
+			if len(res.Stdout) > 0 {
+				log.Infof("%s: stdout:\n%s", contName, res.Stdout)
+			}
+			if len(res.Stderr) > 0 {
+				log.Infof("%s: stderr:\n%s", contName, res.Stderr)
+			}
+			if res.ExitCode != 0 {
+				log.Warnf("%s: command exited with code %d", contName, res.ExitCode)
+			}
+		}
+	}
+	return nil
+}
+
 func execCmds(
 	ctx context.Context,
 	cont types.GenericContainer,
@@ -160,5 +288,7 @@ func init() {
 	rootCmd.AddCommand(execCmd)
 	execCmd.Flags().StringVarP(&execCommand, "cmd", "", "", "command to execute")
 	execCmd.Flags().StringSliceVarP(&labels, "label", "", []string{}, "labels to filter container subset")
-	execCmd.Flags().StringVarP(&execFormat, "format", "f", "plain", "output format. One of [json, plain]")
+	execCmd.Flags().StringVarP(&execFormat, "format", "f", "plain", "output format. One of [json, plain, table]")
+	execCmd.Flags().BoolVarP(&execInteractive, "interactive", "i", false, "run the command in an interactive TTY session on the node passed as an argument")
+	execCmd.Flags().UintVarP(&execMaxWorkers, "max-workers", "", 0, "limit the maximum number of nodes the command is executed on concurrently, defaults to the number of CPUs")
 }
@@ -17,6 +17,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/nodes"
 	"github.com/srl-labs/containerlab/runtime"
 	"github.com/srl-labs/containerlab/types"
 )
@@ -24,6 +25,7 @@ import (
 var format string
 var details bool
 var all bool
+var interfaces bool
 
 type containerDetails struct {
 	LabName     string `json:"lab_name,omitempty"`
@@ -31,11 +33,21 @@ type containerDetails struct {
 	Name        string `json:"name,omitempty"`
 	ContainerID string `json:"container_id,omitempty"`
 	Image       string `json:"image,omitempty"`
+	// registry digest of the image, e.g. sha256:1a2b...
+	ImageDigest string `json:"image_digest,omitempty"`
 	Kind        string `json:"kind,omitempty"`
 	Group       string `json:"group,omitempty"`
 	State       string `json:"state,omitempty"`
 	IPv4Address string `json:"ipv4_address,omitempty"`
 	IPv6Address string `json:"ipv6_address,omitempty"`
+	Version     string `json:"version,omitempty"`
+	TLSCert     string `json:"tls_cert,omitempty"`
+	TLSKey      string `json:"tls_key,omitempty"`
+	TLSCA       string `json:"tls_ca,omitempty"`
+	// ports published on the host, e.g. 0.0.0.0:2202->22/tcp
+	Ports []string `json:"ports,omitempty"`
+	// labels defined in the topology file, without the labels set by containerlab
+	Labels map[string]string `json:"labels,omitempty"`
 }
 type BridgeDetails struct{}
 
@@ -94,6 +106,9 @@ var inspectCmd = &cobra.Command{
 			log.Println("no containers found")
 			return nil
 		}
+		if interfaces {
+			return printInterfaceMap(c)
+		}
 		if details {
 			b, err := json.MarshalIndent(containers, "", "  ")
 			if err != nil {
@@ -114,16 +129,49 @@ func init() {
 	inspectCmd.Flags().BoolVarP(&details, "details", "", false, "print all details of lab containers")
 	inspectCmd.Flags().StringVarP(&format, "format", "f", "table", "output format. One of [table, json]")
 	inspectCmd.Flags().BoolVarP(&all, "all", "a", false, "show all deployed containerlab labs")
+	inspectCmd.Flags().BoolVarP(&interfaces, "interfaces", "", false, "print the mapping of container interface names to NOS interface names in JSON format")
+}
+
+// printInterfaceMap prints the container to NOS interface names mapping of the lab nodes
+// whose kinds name interfaces differently, keyed by the node name.
+// the mapping is built from the links defined in the topology file.
+func printInterfaceMap(c *clab.CLab) error {
+	if topo == "" {
+		return fmt.Errorf("interfaces mapping requires a topology file path (--topo)")
+	}
+
+	ifaces := make(map[string][]string)
+	for _, l := range c.Links {
+		for _, ep := range []*types.Endpoint{l.A, l.B} {
+			ifaces[ep.Node.ShortName] = append(ifaces[ep.Node.ShortName], ep.EndpointName)
+		}
+	}
+
+	m := make(map[string]map[string]string)
+	for name, n := range c.Nodes {
+		mapper, ok := n.(nodes.InterfaceMapper)
+		if !ok {
+			continue
+		}
+		m[name] = mapper.InterfaceMap(ifaces[name])
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal interfaces mapping: %v", err)
+	}
+	fmt.Println(string(b))
+	return nil
 }
 
 func toTableData(det []containerDetails) [][]string {
 	tabData := make([][]string, 0, len(det))
 	for i, d := range det {
 		if all {
-			tabData = append(tabData, []string{fmt.Sprintf("%d", i+1), d.LabPath, d.LabName, d.Name, d.ContainerID, d.Image, d.Kind, d.State, d.IPv4Address, d.IPv6Address})
+			tabData = append(tabData, []string{fmt.Sprintf("%d", i+1), d.LabPath, d.LabName, d.Name, d.ContainerID, d.Image, d.Kind, d.Version, d.State, d.IPv4Address, d.IPv6Address})
 			continue
 		}
-		tabData = append(tabData, []string{fmt.Sprintf("%d", i+1), d.Name, d.ContainerID, d.Image, d.Kind, d.State, d.IPv4Address, d.IPv6Address})
+		tabData = append(tabData, []string{fmt.Sprintf("%d", i+1), d.Name, d.ContainerID, d.Image, d.Kind, d.Version, d.State, d.IPv4Address, d.IPv6Address})
 	}
 	return tabData
 }
@@ -143,6 +191,7 @@ func printContainerInspect(c *clab.CLab, containers []types.GenericContainer, fo
 			LabName:     cont.Labels["containerlab"],
 			LabPath:     path,
 			Image:       cont.Image,
+			ImageDigest: cont.ImageDigest,
 			State:       cont.State,
 			IPv4Address: getContainerIPv4(cont),
 			IPv6Address: getContainerIPv6(cont),
@@ -162,6 +211,19 @@ func printContainerInspect(c *clab.CLab, containers []types.GenericContainer, fo
 		if group, ok := cont.Labels["clab-node-group"]; ok {
 			cdet.Group = group
 		}
+		n := containerNode(c, cont, cdet.Name)
+		if cont.State == "running" {
+			cdet.Version = getNodeVersion(n)
+		}
+		if format == "json" {
+			cdet.TLSCert, cdet.TLSKey, cdet.TLSCA = getNodeCertPaths(n)
+		}
+		if n != nil {
+			for _, p := range clab.PublishedPorts(n.Config()) {
+				cdet.Ports = append(cdet.Ports, p.String())
+			}
+			cdet.Labels = customLabels(n.Config().Labels)
+		}
 		contDetails = append(contDetails, cdet)
 	}
 
@@ -188,6 +250,7 @@ func printContainerInspect(c *clab.CLab, containers []types.GenericContainer, fo
 		"Container ID",
 		"Image",
 		"Kind",
+		"Version",
 		"State",
 		"IPv4 Address",
 		"IPv6 Address",
@@ -204,6 +267,8 @@ func printContainerInspect(c *clab.CLab, containers []types.GenericContainer, fo
 	table.AppendBulk(tabData)
 	table.Render()
 
+	printPublishedPorts(contDetails)
+
 	if !printMysocket {
 		return nil
 	}
@@ -225,6 +290,91 @@ func printContainerInspect(c *clab.CLab, containers []types.GenericContainer, fo
 	return nil
 }
 
+// customLabels returns the labels of a node that are not set by containerlab, nil if the node has none
+func customLabels(labels map[string]string) map[string]string {
+	var res map[string]string
+	for k, v := range labels {
+		if clab.IsReservedLabel(k) {
+			continue
+		}
+		if res == nil {
+			res = make(map[string]string)
+		}
+		res[k] = v
+	}
+	return res
+}
+
+// printPublishedPorts prints the table of the ports the containers publish on the host,
+// nothing is printed when none of the containers publishes a port
+func printPublishedPorts(det []containerDetails) {
+	var tabData [][]string
+	for _, d := range det {
+		if len(d.Ports) > 0 {
+			tabData = append(tabData, []string{d.Name, strings.Join(d.Ports, "\n")})
+		}
+	}
+	if len(tabData) == 0 {
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Published Ports"})
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(tabData)
+	table.Render()
+}
+
+// getNodeVersion returns the NOS version of the node for the kinds that report it.
+func getNodeVersion(n nodes.Node) string {
+	v, ok := n.(nodes.VersionReporter)
+	if !ok {
+		return ""
+	}
+	return v.RunningVersion(context.Background())
+}
+
+// getNodeCertPaths returns the paths of the node TLS certificate, key and the lab root CA certificate.
+// the paths are empty for the kinds that don't use TLS certificates.
+func getNodeCertPaths(n nodes.Node) (cert, key, ca string) {
+	g, ok := n.(nodes.CertPathsGetter)
+	if !ok {
+		return "", "", ""
+	}
+	return g.GetCertPaths()
+}
+
+// containerNode returns the lab node of the container.
+// when the lab nodes are not known from the topology file, the node is initialized from the container labels.
+// nil is returned for the kinds that don't report any inspect details and if the node can't be initialized.
+func containerNode(c *clab.CLab, cont types.GenericContainer, longName string) nodes.Node {
+	if n, ok := c.Nodes[cont.Labels[clab.NodeNameLabel]]; ok {
+		return n
+	}
+	initFn, ok := nodes.Nodes[cont.Labels[clab.NodeKindLabel]]
+	if !ok {
+		return nil
+	}
+	n := initFn()
+	switch n.(type) {
+	case nodes.VersionReporter, nodes.CertPathsGetter:
+	default:
+		return nil
+	}
+	err := n.Init(&types.NodeConfig{
+		ShortName: cont.Labels[clab.NodeNameLabel],
+		LongName:  longName,
+		Kind:      cont.Labels[clab.NodeKindLabel],
+		LabDir:    cont.Labels[clab.NodeLabDirLabel],
+		Labels:    cont.Labels,
+		Sysctls:   map[string]string{},
+	}, nodes.WithRuntime(c.GlobalRuntime()))
+	if err != nil {
+		return nil
+	}
+	return n
+}
+
 func getContainerIPv4(ctr types.GenericContainer) string {
 	if ctr.NetworkSettings.IPv4addr == "" {
 		return "N/A"
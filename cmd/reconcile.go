@@ -0,0 +1,76 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/runtime"
+)
+
+// reconcile flags, the diff is reported unless it is applied
+var reconcileDiff bool
+var reconcileApply bool
+
+// reconcileCmd represents the reconcile command
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile node",
+	Short: "diff the running config of a node with its startup-config and apply the diff",
+	Long: `reconcile compares the running config of a node with the config rendered from its startup-config, reports the diff and, with the --apply flag, applies it to the node.
+Refer to the https://containerlab.srlinux.dev/cmd/reconcile/ documentation to see the kinds that support it`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: sudoCheck,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if topo == "" {
+			return errors.New("provide topology file path with --topo flag")
+		}
+		if reconcileDiff && reconcileApply {
+			return errors.New("--diff and --apply flags can't be used together")
+		}
+		opts := []clab.ClabOption{
+			clab.WithTimeout(timeout),
+			clab.WithTopoFile(topo, varsFile),
+			clab.WithRuntime(rt,
+				&runtime.RuntimeConfig{
+					Debug:            debug,
+					Timeout:          timeout,
+					GracefulShutdown: graceful,
+				},
+			),
+		}
+		c, err := clab.NewContainerLab(opts...)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		diff, err := c.ReconcileNode(ctx, args[0], reconcileApply)
+		if err != nil {
+			return err
+		}
+		if diff == "" {
+			log.Infof("running config of node %q matches its startup-config", args[0])
+			return nil
+		}
+		fmt.Println(diff)
+		if reconcileApply {
+			log.Infof("diff applied to the running config of node %q", args[0])
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reconcileCmd)
+	reconcileCmd.Flags().BoolVarP(&reconcileDiff, "diff", "", false, "report the diff between the running config and the startup-config, the default")
+	reconcileCmd.Flags().BoolVarP(&reconcileApply, "apply", "", false, "apply the diff between the running config and the startup-config to the node")
+}
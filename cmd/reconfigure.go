@@ -0,0 +1,61 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/runtime"
+)
+
+// reconfigure flag pushing the node's startup-config instead of the default configuration
+var reconfigureConfigOnly bool
+
+// reconfigureCmd represents the reconfigure command
+var reconfigureCmd = &cobra.Command{
+	Use:   "reconfigure node",
+	Short: "re-apply the default configuration to a running node",
+	Long: `reconfigure re-applies the configuration containerlab generates for a node, e.g. the gNMI and JSON-RPC servers config of SR Linux nodes, to the running node.
+With the --config-only flag the startup-config of the node is rendered again and pushed to the running node instead, without recreating its container.
+Refer to the https://containerlab.srlinux.dev/cmd/reconfigure/ documentation to see the kinds that support it`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: sudoCheck,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if topo == "" {
+			return errors.New("provide topology file path with --topo flag")
+		}
+		opts := []clab.ClabOption{
+			clab.WithTimeout(timeout),
+			clab.WithTopoFile(topo, varsFile),
+			clab.WithRuntime(rt,
+				&runtime.RuntimeConfig{
+					Debug:            debug,
+					Timeout:          timeout,
+					GracefulShutdown: graceful,
+				},
+			),
+		}
+		c, err := clab.NewContainerLab(opts...)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		if reconfigureConfigOnly {
+			return c.PushNodeStartupConfig(ctx, args[0])
+		}
+		return c.ReconfigureNode(ctx, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reconfigureCmd)
+	reconfigureCmd.Flags().BoolVarP(&reconfigureConfigOnly, "config-only", "", false, "render the startup-config of the node again and push it to the running node")
+}
@@ -6,9 +6,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
+	"time"
 
+	"github.com/olekukonko/tablewriter"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/srl-labs/containerlab/clab"
@@ -16,6 +21,15 @@ import (
 	"github.com/srl-labs/containerlab/runtime"
 )
 
+// max time to save the config of a node
+var saveTimeout time.Duration
+
+// saveAll exports the saved config of every node to the configs dir of the lab
+var saveAll bool
+
+// name of the lab dir subdirectory the node configs are exported to with --all
+const exportedConfigsDir = "configs"
+
 // saveCmd represents the save command
 var saveCmd = &cobra.Command{
 	Use:   "save",
@@ -46,19 +60,17 @@ Refer to the https://containerlab.srlinux.dev/cmd/save/ documentation to see the
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		var wg sync.WaitGroup
-		wg.Add(len(c.Nodes))
+		if saveAll {
+			return exportNodesConfig(ctx, c)
+		}
+
+		nodesList := make([]nodes.Node, 0, len(c.Nodes))
 		for _, node := range c.Nodes {
-			go func(node nodes.Node) {
-				defer wg.Done()
-
-				err := node.SaveConfig(ctx)
-				if err != nil {
-					log.Errorf("err: %v", err)
-				}
-			}(node)
+			nodesList = append(nodesList, node)
+		}
+		for _, err := range saveNodesConfig(ctx, nodesList) {
+			log.Errorf("err: %v", err)
 		}
-		wg.Wait()
 
 		return nil
 	},
@@ -66,4 +78,69 @@ Refer to the https://containerlab.srlinux.dev/cmd/save/ documentation to see the
 
 func init() {
 	rootCmd.AddCommand(saveCmd)
+	saveCmd.Flags().DurationVarP(&saveTimeout, "save-timeout", "", time.Minute, "max time to save the config of a node, e.g: 30s, 2m")
+	saveCmd.Flags().BoolVarP(&saveAll, "all", "", false, "save the config of every node and export it to the configs directory of the lab")
+	saveCmd.Flags().UintVarP(&maxWorkers, "max-workers", "", 0, "limit the maximum number of nodes exporting their config concurrently with --all, defaults to the number of CPUs")
+}
+
+// saveNodesConfig saves the config of the nodes concurrently and returns the errors of the failed saves.
+// the save of each node is bounded by the save timeout.
+func saveNodesConfig(ctx context.Context, nodesList []nodes.Node) []error {
+	var errs []error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(nodesList))
+	for _, node := range nodesList {
+		go func(node nodes.Node) {
+			defer wg.Done()
+
+			// a wedged node must not block the save of the other nodes
+			ctx, cancel := context.WithTimeout(ctx, saveTimeout)
+			defer cancel()
+
+			err := node.SaveConfig(ctx)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("node %s: config save timed out after %s: %v", node.Config().ShortName, saveTimeout, err)
+			}
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(node)
+	}
+	wg.Wait()
+	return errs
+}
+
+// exportNodesConfig saves the config of the lab nodes, exports it to the configs dir of the lab
+// and prints the per-node summary. an error is returned if the export failed for any node.
+func exportNodesConfig(ctx context.Context, c *clab.CLab) error {
+	dir := filepath.Join(c.Dir.Lab, exportedConfigsDir)
+	res := c.ExportConfigs(ctx, dir, maxWorkers, saveTimeout)
+
+	var failed int
+	tabData := make([][]string, 0, len(res))
+	for _, r := range res {
+		switch {
+		case r.Skipped:
+			tabData = append(tabData, []string{r.Node, "skipped", "the node can't save and export its config"})
+		case r.Err != nil:
+			failed++
+			tabData = append(tabData, []string{r.Node, "failed", r.Err.Error()})
+		default:
+			tabData = append(tabData, []string{r.Node, "saved", r.Path})
+		}
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Result", "Config"})
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(tabData)
+	table.Render()
+
+	if failed != 0 {
+		return fmt.Errorf("failed to export the config of %d of %d nodes", failed, len(res))
+	}
+	return nil
 }
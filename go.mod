@@ -22,6 +22,7 @@ require (
 	github.com/kellerza/template v0.0.5
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/olekukonko/tablewriter v0.0.5-0.20201029120751-42e21c7531a3
+	github.com/openconfig/gnmi v0.0.0-20210707145734-c69a5df04b53
 	github.com/opencontainers/runtime-spec v1.0.3-0.20210326190908-1c3f411f0417
 	github.com/pkg/errors v0.9.1
 	github.com/scrapli/scrapligo v0.1.1-0.20210909232153-75c4a2e96780
@@ -32,6 +33,7 @@ require (
 	github.com/weaveworks/ignite v0.9.1-0.20210705155449-2dbcdd663727
 	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519
 	golang.org/x/term v0.0.0-20210916214954-140adaaadfaf
+	google.golang.org/grpc v1.40.0
 	gopkg.in/yaml.v2 v2.4.0
 	inet.af/netaddr v0.0.0-20210903134321-85fa6c94624e
 )
@@ -168,7 +170,6 @@ require (
 	google.golang.org/api v0.57.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/genproto v0.0.0-20210924002016-3dee208752a0 // indirect
-	google.golang.org/grpc v1.40.0 // indirect
 	google.golang.org/protobuf v1.27.1 // indirect
 	gopkg.in/inf.v0 v0.9.1 // indirect
 	gopkg.in/square/go-jose.v2 v2.6.0 // indirect
@@ -0,0 +1,44 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package nodes
+
+// NodeCapability is a feature of a node reported by a CapabilityReporter
+type NodeCapability string
+
+const (
+	// CapabilitySaveConfig is reported when SaveConfig persists the node config
+	CapabilitySaveConfig NodeCapability = "save-config"
+	// CapabilityGNMI is reported when the node runs a gNMI server
+	CapabilityGNMI NodeCapability = "gnmi"
+	// CapabilityJSONRPC is reported when the node runs a JSON-RPC server
+	CapabilityJSONRPC NodeCapability = "json-rpc"
+	// CapabilityExec is reported when the NOS CLI commands can be executed in the node container
+	CapabilityExec NodeCapability = "exec"
+)
+
+// CapabilityReporter is implemented by nodes that can report the features they support,
+// e.g. for the CLI to skip the actions the node doesn't support.
+// Capabilities reflects the node settings, e.g. a server disabled by the node labels is not reported.
+type CapabilityReporter interface {
+	Capabilities() []NodeCapability
+}
+
+// Capabilities returns the capabilities of n, which are empty if n doesn't implement CapabilityReporter
+func Capabilities(n Node) []NodeCapability {
+	if r, ok := n.(CapabilityReporter); ok {
+		return r.Capabilities()
+	}
+	return nil
+}
+
+// HasCapability returns true if n reports the capability c
+func HasCapability(n Node, c NodeCapability) bool {
+	for _, nc := range Capabilities(n) {
+		if nc == c {
+			return true
+		}
+	}
+	return false
+}
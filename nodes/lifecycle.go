@@ -0,0 +1,45 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package nodes
+
+// Stage is a node lifecycle stage reported to a LifecycleHook
+type Stage string
+
+const (
+	StageInit       Stage = "init"
+	StagePreDeploy  Stage = "pre-deploy"
+	StageDeploy     Stage = "deploy"
+	StageReady      Stage = "ready"
+	StagePostDeploy Stage = "post-deploy"
+)
+
+// LifecycleHook is implemented by the consumers of containerlab that want to follow the nodes lifecycle,
+// e.g. to update a UI or a progress bar.
+// OnStage is called by the nodes once they successfully completed a stage, it may be called concurrently for different nodes.
+type LifecycleHook interface {
+	OnStage(nodeName string, stage Stage)
+}
+
+// LifecycleHookSetter is implemented by nodes that report their lifecycle stages to a LifecycleHook
+type LifecycleHookSetter interface {
+	WithLifecycleHook(LifecycleHook)
+}
+
+// WithLifecycleHook registers the hook with the nodes that report their lifecycle stages
+func WithLifecycleHook(h LifecycleHook) NodeOption {
+	return func(n Node) {
+		if hs, ok := n.(LifecycleHookSetter); ok {
+			hs.WithLifecycleHook(h)
+		}
+	}
+}
+
+// NotifyStage reports the stage of the node to the hook, it is a no-op when no hook is registered
+func NotifyStage(h LifecycleHook, nodeName string, stage Stage) {
+	if h == nil {
+		return
+	}
+	h.OnStage(nodeName, stage)
+}
@@ -6,6 +6,7 @@ package nodes
 
 import (
 	"context"
+	"io"
 
 	"github.com/srl-labs/containerlab/runtime"
 	"github.com/srl-labs/containerlab/types"
@@ -66,6 +67,162 @@ type Node interface {
 	GetRuntime() runtime.ContainerRuntime
 }
 
+// CertGenerator is implemented by nodes that use a TLS certificate signed by the lab CA.
+// GenerateCert is called for all such nodes before the nodes are scheduled for creation.
+type CertGenerator interface {
+	GenerateCert(configName, labCADir, labCARoot string) error
+}
+
+// LabCAUser is implemented by nodes that may have their TLS certificate signed by the lab CA.
+// UsesLabCA returns false when the node doesn't need the lab CA, e.g. its certificate is signed by an external CA,
+// the lab CA is generated only when a node of the lab uses it.
+type LabCAUser interface {
+	UsesLabCA() bool
+}
+
+// CertPathsGetter is implemented by nodes that have their TLS certificate written to the lab CA dir.
+// GetCertPaths returns the paths of the node certificate, its key and the lab root CA certificate,
+// the paths are empty when the node doesn't use TLS.
+type CertPathsGetter interface {
+	GetCertPaths() (cert, key, ca string)
+}
+
+// Credentials are the credentials of the user managing a node.
+// a password changed by the user is not set, so that it is not written to the generated files,
+// it is referenced by the path of the file it is read from, if any.
+type Credentials struct {
+	Username     string
+	Password     string
+	PasswordFile string
+}
+
+// CredentialsGetter is implemented by nodes whose credentials can be changed from the default credentials of their kind.
+// GetCredentials returns the credentials of the user containerlab configures on the node.
+type CredentialsGetter interface {
+	GetCredentials() Credentials
+}
+
+// MgmtPorts are the container ports of the management servers of a node, zero for a server that is not enabled
+type MgmtPorts struct {
+	GNMI         int
+	JSONRPCHTTP  int
+	JSONRPCHTTPS int
+}
+
+// MgmtPortsGetter is implemented by nodes that run management servers set up by containerlab,
+// so that the generated inventories point to the ports the servers listen on.
+type MgmtPortsGetter interface {
+	GetMgmtPorts() MgmtPorts
+}
+
+// InterfaceMapper is implemented by nodes whose NOS names interfaces differently from the container interfaces.
+// InterfaceMap returns the map of the passed container interface names to the NOS interface names.
+type InterfaceMapper interface {
+	InterfaceMap(ifaces []string) map[string]string
+}
+
+// VersionReporter is implemented by nodes that can report the version of the NOS running in the container.
+// RunningVersion returns an empty string if the version can't be retrieved, e.g. when the node is not running.
+type VersionReporter interface {
+	RunningVersion(context.Context) string
+}
+
+// ReadyChecker is implemented by nodes that can report when they finished booting.
+// Ready returns when the node is ready or with an error if it is not ready in time.
+type ReadyChecker interface {
+	Ready(context.Context) error
+}
+
+// ConfigRenderer is implemented by nodes that apply a generated config after they boot.
+// RenderConfig writes the config to w without accessing the node, e.g. for the deploy dry run.
+type ConfigRenderer interface {
+	RenderConfig(w io.Writer) error
+}
+
+// Reconfigurer is implemented by nodes that can re-apply the config containerlab generates to a running node.
+// ReConfigure is idempotent, re-applying the config doesn't duplicate any of its parts.
+type Reconfigurer interface {
+	ReConfigure(context.Context) error
+}
+
+// StartupConfigPusher is implemented by nodes that can re-render their startup-config and apply it to the running node.
+// with running unset the node is not running and only its startup-config files are regenerated.
+type StartupConfigPusher interface {
+	PushStartupConfig(ctx context.Context, running bool) error
+}
+
+// InterfaceSyncer is implemented by nodes that can bring up the interfaces added to the running node, e.g. by a new link.
+// SyncInterfaces enables the NOS interfaces of the container interfaces that are not configured yet
+// and returns their NOS names.
+type InterfaceSyncer interface {
+	SyncInterfaces(context.Context) ([]string, error)
+}
+
+// RunningConfigGetter is implemented by nodes that can return their running config, e.g. to diff it against a desired state.
+// GetRunningConfig returns the config in the NOS native format.
+type RunningConfigGetter interface {
+	GetRunningConfig(context.Context) (string, error)
+}
+
+// Reconciler is implemented by nodes that can reconcile their running config with the config rendered from the startup-config.
+// Reconcile returns the diff between the two, empty if they match, and applies it to the running config when apply is set.
+type Reconciler interface {
+	Reconcile(ctx context.Context, apply bool) (string, error)
+}
+
+// DiagnosticsCollector is implemented by nodes that can collect the diagnostics needed for bug reports, e.g. a tech-support bundle.
+// CollectDiagnostics writes the diagnostics files of the running node to destDir.
+type DiagnosticsCollector interface {
+	CollectDiagnostics(ctx context.Context, destDir string) error
+}
+
+// ConfigExporter is implemented by nodes that can save their config and export it to a file, e.g. to commit it to version control.
+// ExportConfig saves the config of the running node, writes it to a node-named file in destDir and returns the file path.
+type ConfigExporter interface {
+	ExportConfig(ctx context.Context, destDir string) (string, error)
+}
+
+// RuntimeResolver is implemented by nodes with settings that depend on the container runtime, e.g. the node type taken from the image.
+// ResolveRuntime is called with the deploy context once the images are pulled, before the nodes are created,
+// it returns an error when the resolved settings are invalid for the node, which fails the deployment.
+type RuntimeResolver interface {
+	ResolveRuntime(context.Context) error
+}
+
+// ImageChecker is implemented by nodes that can tell whether their image supports the node settings.
+// CheckImage is best-effort, it returns an error only when the image is known to be incompatible with the settings.
+type ImageChecker interface {
+	CheckImage(context.Context) error
+}
+
+// HostChecker is implemented by nodes with requirements on the container host, e.g. the kernel sysctls the node sets.
+// CheckHost returns the issues of the host that prevent the node from working, each with the guidance to fix it.
+// the issues don't name the node, so that the issues shared by several nodes are reported once.
+type HostChecker interface {
+	CheckHost() []string
+}
+
+// EndpointChecker is implemented by nodes that validate their link endpoints against the node settings.
+// CheckEndpoints is called once the links of the topology are parsed, before any container is created.
+type EndpointChecker interface {
+	CheckEndpoints() error
+}
+
+// LinkTester is implemented by nodes that can take part in the connectivity test of their point-to-point links.
+// AddTestAddrs configures the IPv4 prefixes mapped to the container interfaces, Ping reports an error
+// if addr doesn't answer and RemoveTestAddrs removes the config added by AddTestAddrs.
+type LinkTester interface {
+	AddTestAddrs(ctx context.Context, addrs map[string]string) error
+	Ping(ctx context.Context, addr string) error
+	RemoveTestAddrs(ctx context.Context, ifaces []string) error
+}
+
+// VolumeUser is implemented by nodes that mount named runtime volumes, which outlive the node containers.
+// Volumes returns the names of the volumes, so that they can be removed when the lab is purged.
+type VolumeUser interface {
+	Volumes() []string
+}
+
 var Nodes = map[string]Initializer{}
 
 type Initializer func() Node
@@ -92,6 +249,20 @@ func WithRuntime(r runtime.ContainerRuntime) NodeOption {
 	}
 }
 
+// TopologyPreserver is implemented by nodes that can keep the NOS topology file generated by a previous deployment
+type TopologyPreserver interface {
+	WithPreserveTopology()
+}
+
+// WithPreserveTopology makes the nodes that support it keep the NOS topology file generated by a previous deployment
+func WithPreserveTopology(preserve bool) NodeOption {
+	return func(n Node) {
+		if tp, ok := n.(TopologyPreserver); ok && preserve {
+			tp.WithPreserveTopology()
+		}
+	}
+}
+
 var DefaultConfigTemplates = map[string]string{
 	"vr-sros": "",
 }
@@ -0,0 +1,128 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// srlBreakoutModes are the breakout modes supported by the ports of the node types,
+// the types not listed have no breakout capable ports
+var srlBreakoutModes = map[string][]string{
+	"ixrd2": {"4x10G", "4x25G"},
+	"ixrd3": {"4x10G", "4x25G"},
+	"ixrh2": {"4x10G", "4x25G"},
+	"ixrh3": {"4x10G", "4x25G", "2x100G", "4x100G"},
+	"ixr6":  {"4x10G", "4x25G", "4x100G"},
+	"ixr10": {"4x10G", "4x25G", "4x100G"},
+}
+
+// breakoutModeRe matches the breakout modes in the <number of ports>x<port speed> format, e.g. 4x25G
+var breakoutModeRe = regexp.MustCompile(`^([0-9]+)x([0-9]+G)$`)
+
+// interfaceBreakout is the breakout mode of an SR Linux port
+type interfaceBreakout struct {
+	// SR Linux name of the port, e.g. ethernet-1/3
+	Name  string
+	Ports int
+	Speed string
+	mode  string
+}
+
+// parseBreakouts parses the comma separated list of the ports breakout modes set with the breakout label,
+// e.g. e1-3:4x25G,e1-4:4x10G. the ports are named like the link endpoints.
+// returns the breakouts sorted by the port name.
+func parseBreakouts(v string) ([]interfaceBreakout, error) {
+	var res []interfaceBreakout
+	seen := make(map[string]struct{})
+	for _, b := range strings.Split(v, ",") {
+		b = strings.TrimSpace(b)
+		if b == "" {
+			continue
+		}
+		i := strings.Index(b, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("%s label: %q should be in the <port>:<mode> format, e.g. e1-3:4x25G", breakoutLabel, b)
+		}
+		port, mode := b[:i], b[i+1:]
+		name := srlInterfaceName(port)
+		if name == "" || strings.Count(port, "-") != 1 {
+			return nil, fmt.Errorf("%s label: port %q should be named eX-Y, e.g. e1-3", breakoutLabel, port)
+		}
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%s label: port %q is set more than once", breakoutLabel, port)
+		}
+		seen[name] = struct{}{}
+		m := breakoutModeRe.FindStringSubmatch(mode)
+		if m == nil {
+			return nil, fmt.Errorf("%s label: port %s mode %q should be in the <ports>x<speed> format, e.g. 4x25G", breakoutLabel, port, mode)
+		}
+		n, _ := strconv.Atoi(m[1])
+		res = append(res, interfaceBreakout{Name: name, Ports: n, Speed: m[2], mode: mode})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// checkBreakoutModes returns an error if a breakout mode of the node is not supported by the node type
+func (s *srl) checkBreakoutModes() error {
+	if len(s.breakouts) == 0 {
+		return nil
+	}
+	modes := srlBreakoutModes[s.cfg.NodeType]
+	for _, b := range s.breakouts {
+		if _, ok := utils.StringInSlice(modes, b.mode); !ok {
+			if len(modes) == 0 {
+				return fmt.Errorf("node %s: type %s has no breakout capable ports, port %s can't be set to %s", s.cfg.ShortName, s.cfg.NodeType, b.Name, b.mode)
+			}
+			return fmt.Errorf("node %s: type %s doesn't support breakout mode %s of port %s, should be any of %s",
+				s.cfg.ShortName, s.cfg.NodeType, b.mode, b.Name, strings.Join(modes, ", "))
+		}
+	}
+	return nil
+}
+
+// CheckEndpoints returns an error if a link endpoint of the node doesn't match the breakout of its port:
+// the ports that are broken out can only be connected by their breakout ports, eX-Y-Z,
+// within the number of breakout ports of the mode.
+func (s *srl) CheckEndpoints() error {
+	if len(s.breakouts) == 0 {
+		return nil
+	}
+	bs := make(map[string]interfaceBreakout, len(s.breakouts))
+	for _, b := range s.breakouts {
+		bs[b.Name] = b
+	}
+	for _, e := range s.cfg.Endpoints {
+		name := srlInterfaceName(e.EndpointName)
+		if name == "" {
+			continue
+		}
+		if b, ok := bs[name]; ok {
+			return fmt.Errorf("node %s: port %s is broken out to %s, its breakout ports should be connected instead, e.g. %s-1",
+				s.cfg.ShortName, e.EndpointName, b.mode, e.EndpointName)
+		}
+		if strings.Count(name, "/") != 2 {
+			continue
+		}
+		i := strings.LastIndex(name, "/")
+		b, ok := bs[name[:i]]
+		if !ok {
+			continue
+		}
+		if p, _ := strconv.Atoi(name[i+1:]); p < 1 || p > b.Ports {
+			return fmt.Errorf("node %s: interface %s is out of the %d breakout ports of port %s", s.cfg.ShortName, e.EndpointName, b.Ports, b.Name)
+		}
+	}
+	return nil
+}
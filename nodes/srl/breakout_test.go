@@ -0,0 +1,106 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestParseBreakouts(t *testing.T) {
+	b, err := parseBreakouts("e1-4:4x10G, e1-3:4x25G")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[{ethernet-1/3 4 25G 4x25G} {ethernet-1/4 4 10G 4x10G}]"
+	if got := fmt.Sprint(b); got != want {
+		t.Fatalf("wanted %s, got %s", want, got)
+	}
+
+	for _, v := range []string{"e1-3", "e1-3-1:4x25G", "ethernet-1/3:4x25G", "e1-3:4x25", "e1-3:4x25G,e1-3:4x10G"} {
+		if _, err := parseBreakouts(v); err == nil {
+			t.Fatalf("wanted an error for %q, got nil", v)
+		}
+	}
+}
+
+func TestBreakoutModes(t *testing.T) {
+	tests := map[string]struct {
+		nodeType string
+		breakout string
+		wantErr  bool
+	}{
+		"supported": {
+			nodeType: "ixrd3",
+			breakout: "e1-3:4x25G",
+		},
+		"unsupported-mode": {
+			nodeType: "ixrd3",
+			breakout: "e1-3:4x100G",
+			wantErr:  true,
+		},
+		"no-breakout-ports": {
+			nodeType: "ixrd1",
+			breakout: "e1-3:4x10G",
+			wantErr:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := new(srl).Init(&types.NodeConfig{
+				ShortName: "srl1",
+				NodeType:  tc.nodeType,
+				Labels:    map[string]string{breakoutLabel: tc.breakout},
+				Sysctls:   map[string]string{},
+			})
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckEndpoints(t *testing.T) {
+	tests := map[string]struct {
+		endpoint string
+		wantErr  bool
+	}{
+		"breakout-port":     {endpoint: "e1-3-4"},
+		"not-broken-out":    {endpoint: "e1-4"},
+		"other-breakout":    {endpoint: "e1-4-1"},
+		"broken-out-port":   {endpoint: "e1-3", wantErr: true},
+		"out-of-range":      {endpoint: "e1-3-5", wantErr: true},
+		"non-srl-interface": {endpoint: "eth1"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &types.NodeConfig{
+				ShortName: "srl1",
+				NodeType:  "ixrd3",
+				Labels:    map[string]string{breakoutLabel: "e1-3:4x25G"},
+				Sysctls:   map[string]string{},
+			}
+			s := new(srl)
+			if err := s.Init(cfg); err != nil {
+				t.Fatal(err)
+			}
+			cfg.Endpoints = []*types.Endpoint{{Node: cfg, EndpointName: tc.endpoint}}
+			err := s.CheckEndpoints()
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/cert"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// GenerateCert generates the node certificate signed by the lab CA, unless it is already present in the lab CA dir.
+// with an external CA set, the certificate is signed by it instead, and an existing certificate
+// not signed by the external CA is replaced.
+// no certificate is generated for a node with TLS disabled.
+func (s *srl) GenerateCert(configName, labCADir, labCARoot string) error {
+	s.labCADir, s.labCARoot, s.labName = labCADir, labCARoot, configName
+	if !s.tls {
+		return nil
+	}
+	if s.caCert != "" {
+		if err := validateCA(s.caCert, s.caKey); err != nil {
+			return fmt.Errorf("node %s: external CA set with %s and %s labels: %v", s.cfg.ShortName, caCertLabel, caKeyLabel, err)
+		}
+	}
+
+	nodeCerts, err := cert.RetrieveNodeCertData(s.cfg, labCADir)
+	if s.caCert != "" && nodeCerts != nil && !signedBy(nodeCerts.Cert, s.caCert) {
+		log.Infof("node %s certificate is not signed by the external CA %s, generating a new certificate", s.cfg.ShortName, s.caCert)
+		nodeCerts = nil
+	}
+	// an existing certificate is replaced only when the key type is requested explicitly,
+	// so that user-provided certificates with other key types keep working
+	_, keyTypeSet := s.cfg.Labels[tlsKeyTypeLabel]
+	if keyTypeSet && nodeCerts != nil && !keyTypeMatches(nodeCerts.Key, s.tlsKeyType) {
+		log.Infof("node %s certificate key type differs from %s/%d, generating a new certificate", s.cfg.ShortName, s.tlsKeyType.Algo, s.tlsKeyType.Size)
+		nodeCerts = nil
+	}
+	if err != nil || nodeCerts == nil {
+		nodeCerts, err = s.newCert()
+		if err != nil {
+			return err
+		}
+	}
+	s.cfg.TLSCert = string(nodeCerts.Cert)
+	s.cfg.TLSKey = string(nodeCerts.Key)
+
+	return nil
+}
+
+// GetCertPaths returns the paths of the node certificate, key and the lab root CA certificate.
+// before the certificate is generated the lab CA dirs are derived from the node lab dir.
+// empty strings are returned for a node with TLS disabled.
+func (s *srl) GetCertPaths() (cert, key, ca string) {
+	if !s.tls {
+		return "", "", ""
+	}
+	labCADir, labCARoot := s.labCADir, s.labCARoot
+	if labCADir == "" {
+		if s.cfg.LabDir == "" {
+			return "", "", ""
+		}
+		labCADir = filepath.Join(filepath.Dir(s.cfg.LabDir), "ca")
+	}
+	if labCARoot == "" {
+		labCARoot = filepath.Join(labCADir, "root")
+	}
+	ca = filepath.Join(labCARoot, "root-ca.pem")
+	if s.caCert != "" {
+		ca = s.caCert
+	}
+	certDir := filepath.Join(labCADir, s.cfg.ShortName)
+	return filepath.Join(certDir, s.cfg.ShortName+".pem"),
+		filepath.Join(certDir, s.cfg.ShortName+"-key.pem"),
+		ca
+}
+
+// UsesLabCA returns true when the node certificate is signed by the lab CA,
+// which is not the case for a node with TLS disabled or with the external CA set
+func (s *srl) UsesLabCA() bool {
+	return s.tls && s.caCert == ""
+}
+
+// caPaths returns the paths to the certificate and key of the CA the node certificate is signed with,
+// which is the external CA when set and the lab root CA otherwise
+func (s *srl) caPaths() (caCert, caKey string) {
+	if s.caCert != "" {
+		return s.caCert, s.caKey
+	}
+	return path.Join(s.labCARoot, "root-ca.pem"), path.Join(s.labCARoot, "root-ca-key.pem")
+}
+
+// newCert generates the node certificate signed by the lab CA.
+// the node mgmt addresses known at the time of the call are added to the certificate SANs.
+func (s *srl) newCert() (*cert.Certificates, error) {
+	certTpl, err := template.New("node-cert").Parse(cert.NodeCSRTempl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Node CSR Template: %v", err)
+	}
+	certInput := cert.CertInput{
+		Hosts:    s.mgmtAddrs(),
+		Name:     s.cfg.ShortName,
+		LongName: s.cfg.LongName,
+		Fqdn:     s.cfg.Fqdn,
+		Prefix:   s.labName,
+		KeyAlgo:  s.tlsKeyType.Algo,
+		KeySize:  s.tlsKeyType.Size,
+	}
+	caCert, caKey := s.caPaths()
+	nodeCerts, err := cert.GenerateCert(
+		caCert,
+		caKey,
+		certTpl,
+		certInput,
+		path.Join(s.labCADir, certInput.Name),
+	)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("%s CSR: %s", s.cfg.ShortName, string(nodeCerts.Csr))
+	log.Debugf("%s Cert: %s", s.cfg.ShortName, string(nodeCerts.Cert))
+	log.Debugf("%s Key: %s", s.cfg.ShortName, string(nodeCerts.Key))
+
+	s.certGenerated = true
+
+	return nodeCerts, nil
+}
+
+// keyTypeMatches checks that the PEM encoded private key is of the key type
+func keyTypeMatches(keyPEM []byte, kt cert.KeyType) bool {
+	b, _ := pem.Decode(keyPEM)
+	if b == nil {
+		return false
+	}
+	switch b.Type {
+	case "RSA PRIVATE KEY":
+		k, err := x509.ParsePKCS1PrivateKey(b.Bytes)
+		return err == nil && kt.Algo == "rsa" && k.N.BitLen() == kt.Size
+	case "EC PRIVATE KEY":
+		k, err := x509.ParseECPrivateKey(b.Bytes)
+		return err == nil && kt.Algo == "ecdsa" && k.Curve.Params().BitSize == kt.Size
+	}
+	return false
+}
+
+// assignULAMgmtAddr sets the IPv6 mgmt address of the node from the unique local subnet generated for the mgmt network,
+// so that the address is known when the node certificate is generated. an address set by the user is kept.
+// the subnet is shared by the labs using the same mgmt network, so the interface ID is derived from the lab and the node index:
+// the upper half is a hash of the container name prefix of the lab, unique on the host, and the lower half is the node index
+// above the range the runtime allocates the addresses of the other containers from.
+func (s *srl) assignULAMgmtAddr() error {
+	if s.mgmt == nil || !s.mgmt.IPv6ULA || s.cfg.MgmtIPv6Address != "" || s.cfg.NetworkMode == "host" {
+		return nil
+	}
+	d := sha256.Sum256([]byte(strings.TrimSuffix(s.cfg.LongName, "-"+s.cfg.ShortName)))
+	id := uint64(binary.BigEndian.Uint32(d[:4]))<<32 | (ulaMgmtAddrBase + uint64(s.cfg.Index) + 1)
+	a, err := utils.IPv6SubnetAddr(s.mgmt.IPv6Subnet, id)
+	if err != nil {
+		return fmt.Errorf("node %s: failed to assign IPv6 mgmt address: %v", s.cfg.ShortName, err)
+	}
+	s.cfg.MgmtIPv6Address = a
+	return nil
+}
+
+// mgmtAddrs returns the IPv4 and IPv6 mgmt addresses of the node that are set
+func (s *srl) mgmtAddrs() []string {
+	var addrs []string
+	for _, a := range []string{s.cfg.MgmtIPv4Address, s.cfg.MgmtIPv6Address} {
+		if a != "" {
+			addrs = append(addrs, a)
+		}
+	}
+	return addrs
+}
+
+// ensureCertMgmtAddrs makes sure the node certificate has the node mgmt addresses in its SANs,
+// so that gNMI/JSON-RPC clients can verify the node when connecting by IP, including IPv6-only mgmt networks.
+// a certificate generated by clab is re-generated if an address is missing,
+// a user-provided certificate is never overwritten.
+func (s *srl) ensureCertMgmtAddrs() error {
+	missing := missingCertIPs(s.cfg.TLSCert, s.mgmtAddrs())
+	if len(missing) == 0 {
+		return nil
+	}
+	if !s.certGenerated {
+		log.Warnf("node %s: certificate does not include mgmt address(es) %v in its SANs", s.cfg.ShortName, missing)
+		return nil
+	}
+
+	log.Debugf("node %s: re-generating certificate to include mgmt address(es) %v", s.cfg.ShortName, missing)
+	nodeCerts, err := s.newCert()
+	if err != nil {
+		return fmt.Errorf("failed to generate certificates for node %s: %v", s.cfg.ShortName, err)
+	}
+	s.cfg.TLSCert = string(nodeCerts.Cert)
+	s.cfg.TLSKey = string(nodeCerts.Key)
+
+	return nil
+}
+
+// writeTLSProfileFiles writes the node certificate and key to the config dir of the node,
+// where the clab-profile server profile of the default config references them, so that the key is not part of the config.
+// the key is readable by its owner only.
+func (s *srl) writeTLSProfileFiles() error {
+	dir := filepath.Join(s.cfg.LabDir, "config", tlsProfileDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("%s: failed to create TLS dir: %v", s.cfg.ShortName, err)
+	}
+	for _, f := range []struct {
+		name string
+		data string
+		perm os.FileMode
+	}{
+		{tlsProfileCertFile, s.cfg.TLSCert, 0644},
+		{tlsProfileKeyFile, s.cfg.TLSKey, 0600},
+	} {
+		p := filepath.Join(dir, f.name)
+		if err := os.WriteFile(p, []byte(f.data), f.perm); err != nil {
+			return fmt.Errorf("%s: failed to write TLS file: %v", s.cfg.ShortName, err)
+		}
+		// WriteFile doesn't change permissions of an existing file
+		if err := os.Chmod(p, f.perm); err != nil {
+			return err
+		}
+		s.chownToUser(p)
+	}
+	return nil
+}
+
+// missingCertIPs returns the addrs that are not present in the IP SANs of the PEM encoded certificate c.
+// if c can't be parsed, nil is returned.
+func missingCertIPs(c string, addrs []string) []string {
+	b, _ := pem.Decode([]byte(c))
+	if b == nil {
+		return nil
+	}
+	crt, err := x509.ParseCertificate(b.Bytes)
+	if err != nil {
+		return nil
+	}
+
+	var missing []string
+	for _, a := range addrs {
+		ip := net.ParseIP(a)
+		found := false
+		for _, certIP := range crt.IPAddresses {
+			if certIP.Equal(ip) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, a)
+		}
+	}
+	return missing
+}
+
+// loadTLSAnchor sets the CA certificate the node certificate is signed with as the trust anchor for client certificates
+func (s *srl) loadTLSAnchor() error {
+	caCert, _ := s.caPaths()
+	ca, err := utils.ReadFileContent(caCert)
+	if err != nil {
+		return fmt.Errorf("node %s: failed to read root CA for mTLS: %v", s.cfg.ShortName, err)
+	}
+	s.cfg.TLSAnchor = strings.TrimSpace(string(ca))
+	return nil
+}
+
+// validateCA checks that the PEM encoded certificate at certPath is a CA certificate that can sign certificates
+// and that the PEM encoded private key at keyPath matches it
+func validateCA(certPath, keyPath string) error {
+	crt, err := readCertificate(certPath)
+	if err != nil {
+		return err
+	}
+	if !crt.BasicConstraintsValid || !crt.IsCA {
+		return fmt.Errorf("%s is not a CA certificate", certPath)
+	}
+	if crt.KeyUsage != 0 && crt.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return fmt.Errorf("%s key usage doesn't allow signing certificates", certPath)
+	}
+	if time.Now().After(crt.NotAfter) {
+		return fmt.Errorf("%s expired on %s", certPath, crt.NotAfter.Format(time.RFC3339))
+	}
+
+	b, err := os.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+	p, _ := pem.Decode(b)
+	if p == nil {
+		return fmt.Errorf("failed to decode PEM private key %s", keyPath)
+	}
+	key, err := parsePrivateKey(p.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse private key %s: %v", keyPath, err)
+	}
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(crt.PublicKey) {
+		return fmt.Errorf("private key %s doesn't match certificate %s", keyPath, certPath)
+	}
+	return nil
+}
+
+// parsePrivateKey parses a DER encoded private key in PKCS #1, SEC 1 or PKCS #8 form
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if k, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParseECPrivateKey(der); err == nil {
+		return k, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := k.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", k)
+	}
+	return signer, nil
+}
+
+// readCertificate reads the PEM encoded certificate at path
+func readCertificate(path string) (*x509.Certificate, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, _ := pem.Decode(b)
+	if p == nil {
+		return nil, fmt.Errorf("failed to decode PEM certificate %s", path)
+	}
+	return x509.ParseCertificate(p.Bytes)
+}
+
+// signedBy returns true if the PEM encoded certificate is signed by the CA certificate at caPath
+func signedBy(certPEM []byte, caPath string) bool {
+	ca, err := readCertificate(caPath)
+	if err != nil {
+		return false
+	}
+	p, _ := pem.Decode(certPEM)
+	if p == nil {
+		return false
+	}
+	crt, err := x509.ParseCertificate(p.Bytes)
+	if err != nil {
+		return false
+	}
+	return crt.CheckSignatureFrom(ca) == nil
+}
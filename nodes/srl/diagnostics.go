@@ -0,0 +1,80 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/runtime"
+)
+
+// name of the file the container output is written to by CollectDiagnostics
+const containerLogFile = "container.log"
+
+// techSupportArchiveRe matches the path of the archive reported by the tech-support command
+var techSupportArchiveRe = regexp.MustCompile(`/\S+\.(?:tar\.gz|tgz)`)
+
+// CollectDiagnostics generates the tech-support archive on the node and copies it to destDir,
+// along with the container output when the runtime keeps it.
+// the archive is removed from the node once copied, as it can be large.
+func (s *srl) CollectDiagnostics(ctx context.Context, destDir string) error {
+	if err := os.MkdirAll(destDir, 0750); err != nil {
+		return err
+	}
+
+	stdout, stderr, err := s.runtime.Exec(ctx, s.cfg.LongName, techSupportCmd)
+	if err != nil {
+		return fmt.Errorf("%s: failed to generate tech-support: %v", s.cfg.ShortName, err)
+	}
+	if len(stderr) > 0 {
+		return fmt.Errorf("%s: failed to generate tech-support: %s", s.cfg.ShortName, stderr)
+	}
+	archive := techSupportArchiveRe.FindString(string(stdout))
+	if archive == "" {
+		return fmt.Errorf("%s: tech-support archive path not found in the command output: %s", s.cfg.ShortName, stdout)
+	}
+
+	b, stderr, err := s.runtime.Exec(ctx, s.cfg.LongName, []string{"cat", archive})
+	if err != nil || len(stderr) > 0 {
+		return fmt.Errorf("%s: failed to read tech-support archive %s: %v %s", s.cfg.ShortName, archive, err, stderr)
+	}
+	// the archive holds the node config and keys, so it is readable only by the owner
+	dst := filepath.Join(destDir, filepath.Base(archive))
+	if err := os.WriteFile(dst, b, 0600); err != nil {
+		return err
+	}
+	log.Infof("copied tech-support archive of node %s to %s", s.cfg.ShortName, dst)
+
+	if _, stderr, err := s.runtime.Exec(ctx, s.cfg.LongName, []string{"rm", "-f", archive}); err != nil || len(stderr) > 0 {
+		log.Warnf("node %s: failed to remove tech-support archive %s: %v %s", s.cfg.ShortName, archive, err, stderr)
+	}
+
+	return s.collectContainerLog(ctx, filepath.Join(destDir, containerLogFile))
+}
+
+// collectContainerLog writes the whole container output to dst.
+// the log is skipped if the runtime doesn't keep the containers output.
+func (s *srl) collectContainerLog(ctx context.Context, dst string) error {
+	t, ok := s.runtime.(runtime.LogTailer)
+	if !ok {
+		log.Debugf("node %s: runtime %s doesn't keep the container output, skipping container log", s.cfg.ShortName, s.runtime.GetName())
+		return nil
+	}
+
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := t.TailLogs(ctx, s.cfg.LongName, 0, f); err != nil {
+		return fmt.Errorf("%s: failed to retrieve container log: %v", s.cfg.ShortName, err)
+	}
+	return nil
+}
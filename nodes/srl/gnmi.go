@@ -0,0 +1,165 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// max time to wait for a single gNMI connection attempt
+	gnmiDialTimeout = time.Second * 5
+)
+
+// mgmtServerStatePath is the gNMI path of the mgmt_server application state leaf
+var mgmtServerStatePath = &gnmi.Path{
+	Elem: []*gnmi.PathElem{
+		{Name: "system"},
+		{Name: "app-management"},
+		{Name: "application", Key: map[string]string{"name": "mgmt_server"}},
+		{Name: "state"},
+	},
+}
+
+// commitStatusPath is the gNMI path of the initial commit status leaf
+var commitStatusPath = &gnmi.Path{
+	Elem: []*gnmi.PathElem{
+		{Name: "system"},
+		{Name: "configuration"},
+		{Name: "commit", Key: map[string]string{"id": "1"}},
+		{Name: "status"},
+	},
+}
+
+// gnmiReady dials the gNMI server of the node and waits for the mgmt_server application to report `running` state
+// and for the initial commit to report `complete` status, which is what the cli probe checks as well.
+// returns an error if the states are not reached before ctx expires.
+func (s *srl) gnmiReady(ctx context.Context) error {
+	// lastErr keeps the last dial/subscribe error that was not caused by ctx expiry
+	var lastErr error
+	wait := s.pollInterval()
+	for {
+		err := s.gnmiWaitReady(ctx)
+		if err == nil {
+			log.Debugf("Node %s booted", s.cfg.ShortName)
+			return nil
+		}
+		if ctx.Err() == nil || lastErr == nil {
+			lastErr = err
+		}
+		log.Debugf("node %s not yet ready: %v", s.cfg.ShortName, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for SR Linux node %s to boot: %v", s.cfg.ShortName, lastErr)
+		case <-time.After(wait):
+		}
+		wait = nextPollInterval(wait)
+	}
+}
+
+// gnmiWaitReady subscribes to the mgmt_server state and the initial commit status and returns
+// once the server is reported as running and the commit as complete, or when the subscription fails
+func (s *srl) gnmiWaitReady(ctx context.Context) error {
+	addr := s.cfg.MgmtIPv4Address
+	if addr == "" {
+		addr = s.cfg.MgmtIPv6Address
+	}
+	if addr == "" {
+		return fmt.Errorf("node %s has no management address", s.cfg.ShortName)
+	}
+
+	transportCreds := grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})) // skipcq: GSC-G402
+	if !s.tls {
+		transportCreds = grpc.WithInsecure()
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, gnmiDialTimeout)
+	defer cancel()
+	conn, err := grpc.DialContext(dialCtx, net.JoinHostPort(addr, strconv.Itoa(s.probePort())),
+		transportCreds,
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	user, password := s.probeCredentials()
+	ctx = metadata.AppendToOutgoingContext(ctx, "username", user, "password", password)
+	ctx, cancelSub := context.WithCancel(ctx)
+	defer cancelSub()
+
+	sub, err := gnmi.NewGNMIClient(conn).Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	err = sub.Send(&gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{
+				Mode:     gnmi.SubscriptionList_STREAM,
+				Encoding: gnmi.Encoding_JSON_IETF,
+				Subscription: []*gnmi.Subscription{
+					{
+						Path: mgmtServerStatePath,
+						Mode: gnmi.SubscriptionMode_ON_CHANGE,
+					},
+					{
+						Path: commitStatusPath,
+						Mode: gnmi.SubscriptionMode_ON_CHANGE,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var mgmtServerRunning, commitComplete bool
+	for {
+		rsp, err := sub.Recv()
+		if err != nil {
+			return err
+		}
+		for _, u := range rsp.GetUpdate().GetUpdate() {
+			switch gnmiValue(u.GetVal()) {
+			case "running":
+				mgmtServerRunning = true
+			case "complete":
+				commitComplete = true
+			}
+		}
+		if mgmtServerRunning && commitComplete {
+			return nil
+		}
+	}
+}
+
+// gnmiValue returns a string representation of a scalar gNMI typed value
+func gnmiValue(v *gnmi.TypedValue) string {
+	switch {
+	case v == nil:
+		return ""
+	case v.GetJsonIetfVal() != nil:
+		return strings.Trim(string(v.GetJsonIetfVal()), `"`)
+	case v.GetJsonVal() != nil:
+		return strings.Trim(string(v.GetJsonVal()), `"`)
+	default:
+		return v.GetStringVal()
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// procSysDir is where the kernel exposes the sysctls
+	procSysDir = "/proc/sys"
+
+	// minimum values of the host sysctls shared by all containers.
+	// SR Linux apps watch their config and state files with inotify,
+	// the distribution defaults are exhausted by a few SR Linux nodes and the nodes fail to boot
+	srlHostSysctlMinimums = map[string]int64{
+		"fs.inotify.max_user_instances": 512,
+		"fs.inotify.max_user_watches":   65536,
+	}
+)
+
+// sysctlPath returns the path of the sysctl key under procSysDir, e.g. net/ipv4/ip_forward
+func sysctlPath(key string) string {
+	return filepath.Join(procSysDir, strings.ReplaceAll(key, ".", "/"))
+}
+
+// CheckHost returns the sysctls of the node the host kernel doesn't have, so that they can't be set in the container,
+// and the host sysctls below the minimums SR Linux needs.
+func (s *srl) CheckHost() []string {
+	var issues []string
+
+	keys := make([]string, 0, len(s.cfg.Sysctls))
+	for k := range s.cfg.Sysctls {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, err := os.Stat(sysctlPath(k)); err == nil {
+			continue
+		}
+		if strings.HasPrefix(k, "net.ipv6.") {
+			issues = append(issues, fmt.Sprintf("sysctl %s can't be set, IPv6 is disabled on the host, e.g. with the ipv6.disable=1 kernel parameter. Enable IPv6 or skip the sysctl with the %s label", k, skipSysctlsLabel))
+			continue
+		}
+		issues = append(issues, fmt.Sprintf("sysctl %s can't be set, the host kernel doesn't support it", k))
+	}
+
+	keys = keys[:0]
+	for k := range srlHostSysctlMinimums {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b, err := os.ReadFile(sysctlPath(k))
+		if err != nil {
+			continue
+		}
+		v, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+		if err != nil {
+			continue
+		}
+		if minimum := srlHostSysctlMinimums[k]; v < minimum {
+			issues = append(issues, fmt.Sprintf("host sysctl %s is %d, below the minimum %d SR Linux needs. Raise it with `sysctl -w %s=%d`", k, v, minimum, k, minimum))
+		}
+	}
+	return issues
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestCheckHost(t *testing.T) {
+	tests := map[string]struct {
+		// sysctls of the host with their values
+		host map[string]string
+		// parts of the wanted issues, in order
+		want []string
+	}{
+		"ok": {
+			host: map[string]string{
+				"net.ipv4.ip_forward":           "1",
+				"net.ipv6.conf.all.autoconf":    "1",
+				"fs.inotify.max_user_instances": "8192",
+				"fs.inotify.max_user_watches":   "524288",
+			},
+		},
+		"ipv6-disabled": {
+			host: map[string]string{
+				"net.ipv4.ip_forward":           "1",
+				"fs.inotify.max_user_instances": "8192",
+				"fs.inotify.max_user_watches":   "524288",
+			},
+			want: []string{"sysctl net.ipv6.conf.all.autoconf can't be set, IPv6 is disabled"},
+		},
+		"low-inotify": {
+			host: map[string]string{
+				"net.ipv4.ip_forward":           "1",
+				"net.ipv6.conf.all.autoconf":    "1",
+				"fs.inotify.max_user_instances": "128\n",
+				"fs.inotify.max_user_watches":   "524288",
+			},
+			want: []string{"host sysctl fs.inotify.max_user_instances is 128, below the minimum 512"},
+		},
+		"missing-sysctl-and-low-inotify": {
+			host: map[string]string{
+				"net.ipv6.conf.all.autoconf":    "1",
+				"fs.inotify.max_user_instances": "8192",
+				"fs.inotify.max_user_watches":   "8192",
+			},
+			want: []string{
+				"sysctl net.ipv4.ip_forward can't be set, the host kernel doesn't support it",
+				"host sysctl fs.inotify.max_user_watches is 8192, below the minimum 65536",
+			},
+		},
+	}
+
+	defer func(d string) { procSysDir = d }(procSysDir)
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			procSysDir = t.TempDir()
+			for k, v := range tc.host {
+				p := sysctlPath(k)
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(p, []byte(v), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			s := &srl{cfg: &types.NodeConfig{
+				ShortName: "srl1",
+				Sysctls: map[string]string{
+					"net.ipv4.ip_forward":        "0",
+					"net.ipv6.conf.all.autoconf": "0",
+				},
+			}}
+
+			issues := s.CheckHost()
+			if len(issues) != len(tc.want) {
+				t.Fatalf("wanted %d issues, got %q", len(tc.want), issues)
+			}
+			for i, w := range tc.want {
+				if !strings.Contains(issues[i], w) {
+					t.Errorf("wanted issue containing %q, got %q", w, issues[i])
+				}
+			}
+		})
+	}
+}
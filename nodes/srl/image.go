@@ -0,0 +1,142 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+const (
+	// image label holding the SR Linux version
+	imageVersionLabel = "org.opencontainers.image.version"
+	// image label holding the node type the image is intended for, used for the nodes without an explicit type
+	imageTypeLabel = "com.nokia.srlinux.type"
+)
+
+// srlTypeMinVersions are the first SR Linux releases supporting the node types,
+// the types not listed are supported by all releases
+var srlTypeMinVersions = map[string]srlVersion{
+	"ixrh2": {major: 21, minor: 6},
+	"ixrh3": {major: 21, minor: 6},
+}
+
+// srlVersion is the major.minor release of SR Linux, e.g. 21.6
+type srlVersion struct {
+	major, minor int
+}
+
+func (v srlVersion) String() string { return fmt.Sprintf("%d.%d", v.major, v.minor) }
+
+func (v srlVersion) less(o srlVersion) bool {
+	return v.major < o.major || (v.major == o.major && v.minor < o.minor)
+}
+
+// srlVersionRe matches the release part of SR Linux versions and image tags, e.g. v21.6.2-67 or 21.3.1
+var srlVersionRe = regexp.MustCompile(`^v?(\d+)\.(\d+)`)
+
+// parseSRLVersion returns the release of an SR Linux version string, ok is false if the string is not a version
+func parseSRLVersion(s string) (v srlVersion, ok bool) {
+	m := srlVersionRe.FindStringSubmatch(s)
+	if m == nil {
+		return v, false
+	}
+	v.major, _ = strconv.Atoi(m[1])
+	v.minor, _ = strconv.Atoi(m[2])
+	return v, true
+}
+
+// imageVersion returns the SR Linux release of the node's image taken from the image version label
+// or, if the label is missing, from the image tag
+func (s *srl) imageVersion(ctx context.Context) (srlVersion, bool) {
+	if ii, ok := s.runtime.(runtime.ImageInspector); ok {
+		labels, err := ii.ImageLabels(ctx, s.cfg.Image)
+		if err != nil {
+			log.Debugf("node %s: failed to inspect image %s: %v", s.cfg.ShortName, s.cfg.Image, err)
+		}
+		if v, ok := parseSRLVersion(labels[imageVersionLabel]); ok {
+			return v, true
+		}
+	}
+
+	// images pinned by digest only have no tag to take the release from
+	return parseSRLVersion(utils.ImageTag(s.cfg.Image))
+}
+
+// normalizeType returns the node type matched regardless of the case and hyphens, e.g. IXR-D2 is ixrd2
+func normalizeType(t string) string {
+	return strings.ReplaceAll(strings.ToLower(t), "-", "")
+}
+
+// resolveImageType sets the type of a node without an explicit type from the type label of the node's image.
+// the default type is kept when the image has no label with a known type.
+// returns false when the image can't be inspected.
+func (s *srl) resolveImageType(ctx context.Context) bool {
+	ii, ok := s.runtime.(runtime.ImageInspector)
+	if !ok {
+		log.Debugf("node %s: runtime can't inspect images, using default type %s", s.cfg.ShortName, srlDefaultType)
+		return true
+	}
+	labels, err := ii.ImageLabels(ctx, s.cfg.Image)
+	if err != nil {
+		log.Debugf("node %s: failed to inspect image %s to discover the node type: %v", s.cfg.ShortName, s.cfg.Image, err)
+		return false
+	}
+
+	t, ok := labels[imageTypeLabel]
+	if !ok {
+		log.Infof("node %s: type is not set and image %s has no %s label, using default type %s", s.cfg.ShortName, s.cfg.Image, imageTypeLabel, srlDefaultType)
+		return true
+	}
+	if _, found := srlTypes[normalizeType(t)]; !found {
+		log.Warnf("node %s: image %s label %s has unknown type %q, using default type %s", s.cfg.ShortName, s.cfg.Image, imageTypeLabel, t, srlDefaultType)
+		return true
+	}
+	s.cfg.NodeType = normalizeType(t)
+	log.Infof("node %s: type is not set, using type %s from image %s label %s", s.cfg.ShortName, s.cfg.NodeType, s.cfg.Image, imageTypeLabel)
+	return true
+}
+
+// resolvePendingImageType takes the type of a node without an explicit type from the image
+// and checks the breakout modes and ulimits of the node against that type.
+func (s *srl) resolvePendingImageType(ctx context.Context) error {
+	if !s.imageTypePending {
+		return nil
+	}
+	s.imageTypePending = false
+	if !s.resolveImageType(ctx) {
+		log.Infof("node %s: type is not set and image %s can't be inspected, using default type %s", s.cfg.ShortName, s.cfg.Image, srlDefaultType)
+	}
+	if err := s.checkBreakoutModes(); err != nil {
+		return err
+	}
+	s.checkUlimits()
+	return nil
+}
+
+// CheckImage returns an error when the SR Linux release of the node's image doesn't support the node type.
+// the check is skipped when the release of the image can't be determined, e.g. for the latest tag.
+func (s *srl) CheckImage(ctx context.Context) error {
+	minVer, ok := srlTypeMinVersions[s.cfg.NodeType]
+	if !ok {
+		return nil
+	}
+	v, ok := s.imageVersion(ctx)
+	if !ok {
+		log.Debugf("node %s: SR Linux version of image %s is unknown, skipping type %s compatibility check", s.cfg.ShortName, s.cfg.Image, s.cfg.NodeType)
+		return nil
+	}
+	if v.less(minVer) {
+		return fmt.Errorf("node %s: type %s is supported starting with SR Linux %s, image %s runs SR Linux %s", s.cfg.ShortName, s.cfg.NodeType, minVer, s.cfg.Image, v)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// imageRuntime returns the image labels or an error when the image is not in the store
+type imageRuntime struct {
+	runtime.ContainerRuntime
+	labels map[string]map[string]string
+}
+
+func (r *imageRuntime) ImageLabels(_ context.Context, image string) (map[string]string, error) {
+	l, ok := r.labels[image]
+	if !ok {
+		return nil, errors.New("no such image")
+	}
+	return l, nil
+}
+
+func TestCheckImage(t *testing.T) {
+	r := &imageRuntime{labels: map[string]map[string]string{
+		"srlinux:old-label":       {imageVersionLabel: "21.3.1-410"},
+		"srlinux:new-label":       {imageVersionLabel: "v21.6.2-67"},
+		"srlinux:latest":          {},
+		"srlinux@sha256:0a1b2c3d": {imageVersionLabel: "21.3.1-410"},
+	}}
+
+	tests := map[string]struct {
+		nodeType string
+		image    string
+		wantErr  bool
+	}{
+		"old-image-label":    {nodeType: "ixrh2", image: "srlinux:old-label", wantErr: true},
+		"new-image-label":    {nodeType: "ixrh3", image: "srlinux:new-label"},
+		"old-image-tag":      {nodeType: "ixrh2", image: "ghcr.io/nokia/srlinux:20.10.1", wantErr: true},
+		"new-image-tag":      {nodeType: "ixrh2", image: "ghcr.io/nokia/srlinux:21.11.1"},
+		"unknown-version":    {nodeType: "ixrh2", image: "srlinux:latest"},
+		"registry-port":      {nodeType: "ixrh2", image: "registry:5000/srlinux"},
+		"digest-label":       {nodeType: "ixrh2", image: "srlinux@sha256:0a1b2c3d", wantErr: true},
+		"tag-and-digest":     {nodeType: "ixrh2", image: "ghcr.io/nokia/srlinux:20.10.1@sha256:0a1b2c3d", wantErr: true},
+		"type-without-limit": {nodeType: "ixr6", image: "srlinux:old-label"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &srl{
+				cfg:     &types.NodeConfig{ShortName: "srl1", NodeType: tc.nodeType, Image: tc.image},
+				runtime: r,
+			}
+			err := s.CheckImage(context.Background())
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestImageType(t *testing.T) {
+	r := &imageRuntime{labels: map[string]map[string]string{
+		"srlinux:ixrd3":   {imageTypeLabel: "IXR-D3"},
+		"srlinux:unknown": {imageTypeLabel: "ixr42"},
+		"srlinux:latest":  {},
+	}}
+
+	tests := map[string]struct {
+		nodeType string
+		image    string
+		want     string
+	}{
+		"type-label":    {image: "srlinux:ixrd3", want: "ixrd3"},
+		"explicit-type": {nodeType: "ixr6", image: "srlinux:ixrd3", want: "ixr6"},
+		"no-type-label": {image: "srlinux:latest", want: srlDefaultType},
+		"unknown-type":  {image: "srlinux:unknown", want: srlDefaultType},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := new(srl)
+			err := s.Init(&types.NodeConfig{
+				ShortName: "srl1",
+				NodeType:  tc.nodeType,
+				Image:     tc.image,
+				Sysctls:   map[string]string{},
+			}, nodes.WithRuntime(r))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.ResolveRuntime(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if s.cfg.NodeType != tc.want {
+				t.Fatalf("wanted type %s, got %s", tc.want, s.cfg.NodeType)
+			}
+		})
+	}
+
+	// the type is taken from the image once it is pulled, not when the node is initialized
+	s := new(srl)
+	err := s.Init(&types.NodeConfig{
+		ShortName: "srl1",
+		Image:     "srlinux:pulled",
+		Sysctls:   map[string]string{},
+	}, nodes.WithRuntime(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.cfg.NodeType != srlDefaultType || !s.imageTypePending {
+		t.Fatalf("wanted the default type with the image type pending, got type %s, pending %v", s.cfg.NodeType, s.imageTypePending)
+	}
+	r.labels["srlinux:pulled"] = map[string]string{imageTypeLabel: "ixrh2", imageVersionLabel: "v21.6.2-67"}
+	if err := s.ResolveRuntime(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.cfg.NodeType != "ixrh2" || s.imageTypePending {
+		t.Fatalf("wanted type ixrh2 from the pulled image, got type %s, pending %v", s.cfg.NodeType, s.imageTypePending)
+	}
+}
+
+func TestResolveRuntimeBreakout(t *testing.T) {
+	r := &imageRuntime{labels: map[string]map[string]string{}}
+	s := new(srl)
+	err := s.Init(&types.NodeConfig{
+		ShortName: "srl1",
+		Image:     "srlinux:pulled",
+		Labels:    map[string]string{breakoutLabel: "e1-3:4x25G"},
+		Sysctls:   map[string]string{},
+	}, nodes.WithRuntime(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ixrd1 nodes have no breakout capable ports
+	r.labels["srlinux:pulled"] = map[string]string{imageTypeLabel: "ixrd1"}
+	if err := s.ResolveRuntime(context.Background()); err == nil {
+		t.Fatalf("wanted an error for a breakout mode the image type doesn't support, got nil")
+	}
+}
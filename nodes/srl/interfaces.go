@@ -0,0 +1,74 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// listIfacesCmd lists the network interfaces of the container
+	listIfacesCmd = []string{"ls", "/sys/class/net"}
+	// runningIfacesCmd prints the interfaces config of the running datastore, one command per line
+	runningIfacesCmd = []string{"sr_cli", "-d", "info", "flat", "from", "running", "interface", "*"}
+	// runningIfaceRe matches the names of the interfaces in the flat config output
+	runningIfaceRe = regexp.MustCompile(`interface (ethernet-[0-9/]+)`)
+)
+
+// SyncInterfaces enables the SR Linux interfaces of the container interfaces that have no config in the running datastore,
+// e.g. the interfaces of links added to the running lab. the interfaces that are configured, even disabled, are left untouched.
+// returns the names of the enabled SR Linux interfaces.
+func (s *srl) SyncInterfaces(ctx context.Context) ([]string, error) {
+	stdout, stderr, err := s.runtime.Exec(ctx, s.cfg.LongName, listIfacesCmd)
+	if err != nil || len(stderr) > 0 {
+		return nil, fmt.Errorf("%s: failed to list container interfaces: %v %s", s.cfg.ShortName, err, stderr)
+	}
+	var ifaces []string
+	for _, i := range strings.Fields(string(stdout)) {
+		if n := srlInterfaceName(i); n != "" {
+			ifaces = append(ifaces, n)
+		}
+	}
+	if len(ifaces) == 0 {
+		return nil, nil
+	}
+
+	stdout, stderr, err = s.runtime.Exec(ctx, s.cfg.LongName, runningIfacesCmd)
+	if err != nil || len(stderr) > 0 {
+		return nil, fmt.Errorf("%s: failed to read interfaces config: %v %s", s.cfg.ShortName, err, stderr)
+	}
+	configured := make(map[string]bool)
+	for _, m := range runningIfaceRe.FindAllStringSubmatch(string(stdout), -1) {
+		configured[m[1]] = true
+	}
+
+	var added []string
+	for _, i := range ifaces {
+		if !configured[i] {
+			added = append(added, i)
+		}
+	}
+	if len(added) == 0 {
+		log.Debugf("node %s: all interfaces are configured", s.cfg.ShortName)
+		return nil, nil
+	}
+	sort.Strings(added)
+
+	cmds := make([]string, 0, len(added)+1)
+	for _, i := range added {
+		cmds = append(cmds, fmt.Sprintf("set / interface %s admin-state enable", i))
+	}
+	cmds = append(cmds, s.commitCmd())
+	if err := s.pushCLIConfig(ctx, strings.Join(cmds, "\n")); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// ifacesRuntime lists the container interfaces and the running interfaces config
+// and records the configs applied to the node with the lab dir labDir
+type ifacesRuntime struct {
+	runtime.ContainerRuntime
+	labDir  string
+	ifaces  string
+	running string
+	configs []string
+}
+
+func (r *ifacesRuntime) Exec(_ context.Context, _ string, cmd []string) ([]byte, []byte, error) {
+	c := strings.Join(cmd, " ")
+	switch {
+	case c == strings.Join(listIfacesCmd, " "):
+		return []byte(r.ifaces), nil, nil
+	case c == strings.Join(runningIfacesCmd, " "):
+		return []byte(r.running), nil, nil
+	}
+	if cfg, ok := appliedConfig(r.labDir, cmd); ok {
+		r.configs = append(r.configs, cfg)
+	}
+	return nil, nil, nil
+}
+
+func (r *ifacesRuntime) ExecWithResult(ctx context.Context, id string, cmd []string) (*runtime.ExecResult, error) {
+	return execResult(r.Exec(ctx, id, cmd))
+}
+
+func TestSyncInterfaces(t *testing.T) {
+	tests := map[string]struct {
+		ifaces  string
+		running string
+		want    []string
+	}{
+		"new interfaces": {
+			ifaces: "e1-1  e1-2\ne1-10\ne1-3-1\ngway-2800\nlo\nmgmt0\n",
+			running: `set / interface ethernet-1/1 admin-state enable
+set / interface ethernet-1/2 admin-state disable
+set / interface mgmt0 admin-state enable
+`,
+			want: []string{"ethernet-1/10", "ethernet-1/3/1"},
+		},
+		"all configured": {
+			ifaces:  "e1-1\nmgmt0\n",
+			running: "set / interface ethernet-1/1 mtu 9000\n",
+		},
+		"no data interfaces": {
+			ifaces: "lo\nmgmt0\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			labDir := newLabDir(t)
+			r := &ifacesRuntime{labDir: labDir, ifaces: tc.ifaces, running: tc.running}
+			s := &srl{
+				cfg:        &types.NodeConfig{ShortName: "srl1", LongName: "clab-lab-srl1", LabDir: labDir},
+				runtime:    r,
+				commitMode: commitModeSave,
+			}
+			got, err := s.SyncInterfaces(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Fatalf("wanted enabled interfaces %v, got %v", tc.want, got)
+			}
+			if len(tc.want) == 0 {
+				if len(r.configs) != 0 {
+					t.Fatalf("wanted no config push, got %v", r.configs)
+				}
+				return
+			}
+			if len(r.configs) != 1 {
+				t.Fatalf("wanted 1 config push, got %d", len(r.configs))
+			}
+			for _, i := range tc.want {
+				if l := "set / interface " + i + " admin-state enable\n"; !strings.Contains(r.configs[0], l) {
+					t.Errorf("pushed config doesn't have %q:\n%s", l, r.configs[0])
+				}
+			}
+		})
+	}
+}
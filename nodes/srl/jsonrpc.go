@@ -0,0 +1,69 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// max time to wait for a single JSON-RPC request
+const jsonRPCRequestTimeout = time.Second * 5
+
+// jsonRPCReady waits for the JSON-RPC https server of the node to complete a TLS handshake and answer an HTTP request.
+// the http server is checked instead when the node runs without TLS.
+// any HTTP response is considered a success, since the server is up at that point.
+// returns an error if the server is not ready before ctx expires.
+func (s *srl) jsonRPCReady(ctx context.Context) error {
+	addr := s.cfg.MgmtIPv4Address
+	if addr == "" {
+		addr = s.cfg.MgmtIPv6Address
+	}
+	if addr == "" {
+		return fmt.Errorf("node %s has no management address", s.cfg.ShortName)
+	}
+	url := "https://" + net.JoinHostPort(addr, strconv.Itoa(s.jsonRPCHTTPSPort)) + "/jsonrpc"
+	if !s.tls {
+		url = "http://" + net.JoinHostPort(addr, strconv.Itoa(s.jsonRPCHTTPPort)) + "/jsonrpc"
+	}
+
+	c := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // skipcq: GSC-G402
+		},
+		Timeout: jsonRPCRequestTimeout,
+	}
+
+	var lastErr error
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		rsp, err := c.Do(req)
+		if err == nil {
+			rsp.Body.Close()
+			log.Debugf("node %s JSON-RPC server is ready", s.cfg.ShortName)
+			return nil
+		}
+		if ctx.Err() == nil || lastErr == nil {
+			lastErr = err
+		}
+		log.Debugf("node %s JSON-RPC server not yet ready: %v", s.cfg.ShortName, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for SR Linux node %s JSON-RPC server: %v", s.cfg.ShortName, lastErr)
+		case <-time.After(retryTimer):
+		}
+	}
+}
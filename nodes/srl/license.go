@@ -0,0 +1,70 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// initLicenseDir sets the license pool dir and the selection strategy from the license-dir and license-select labels
+func (s *srl) initLicenseDir() error {
+	s.licenseSelect = licenseSelectIndex
+	if v, ok := s.cfg.Labels[licenseSelectLabel]; ok {
+		if v != licenseSelectIndex && v != licenseSelectMAC {
+			return fmt.Errorf("node %s: wrong license selection %q set with %s label, should be %s or %s",
+				s.cfg.ShortName, v, licenseSelectLabel, licenseSelectIndex, licenseSelectMAC)
+		}
+		s.licenseSelect = v
+	}
+
+	dir := s.cfg.Labels[licenseDirLabel]
+	if dir == "" {
+		return nil
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("node %s: license dir set with %s label: %v", s.cfg.ShortName, licenseDirLabel, err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("node %s: license dir %s set with %s label is not a directory", s.cfg.ShortName, dir, licenseDirLabel)
+	}
+	s.licenseDir = dir
+	return nil
+}
+
+// licenseFromDir returns the path to the node license selected from the license dir.
+// in index mode the license files sorted by name are assigned to the lab nodes by the node index,
+// in mac mode the license file is named after the node base mac without the colons, e.g. 02ab1c000000.key.
+func (s *srl) licenseFromDir(baseMAC string) (string, error) {
+	entries, err := os.ReadDir(s.licenseDir)
+	if err != nil {
+		return "", err
+	}
+	// the entries are sorted by name, hidden files are skipped
+	var files []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") {
+			files = append(files, e.Name())
+		}
+	}
+
+	if s.licenseSelect == licenseSelectMAC {
+		want := strings.ReplaceAll(baseMAC, ":", "")
+		for _, f := range files {
+			if strings.EqualFold(strings.TrimSuffix(f, filepath.Ext(f)), want) {
+				return filepath.Join(s.licenseDir, f), nil
+			}
+		}
+		return "", fmt.Errorf("node %s: no license for base mac %s found in %s, expected a %s.key file", s.cfg.ShortName, baseMAC, s.licenseDir, want)
+	}
+
+	if s.cfg.Index >= len(files) {
+		return "", fmt.Errorf("node %s: no license for node index %d found in %s, the dir has %d license files", s.cfg.ShortName, s.cfg.Index, s.licenseDir, len(files))
+	}
+	return filepath.Join(s.licenseDir, files[s.cfg.Index]), nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// subinterface the link test addresses are configured on
+	testSubinterface = "0"
+	// linux netns of the default network-instance, the link test pings are sent from it
+	defaultNetnsName = "srbase-default"
+)
+
+// ipv4AdminStateCmd checks whether the image requires the ipv4 admin-state of a subinterface to be enabled
+var ipv4AdminStateCmd = []string{"sr_cli", "-d", "info", "interface", "*", "subinterface", "*", "ipv4", "admin-state"}
+
+// AddTestAddrs configures the IPv4 prefixes on subinterface 0 of the SR Linux interfaces mapped to the
+// container interfaces in addrs and attaches them to the default network-instance in a single commit.
+// the interfaces that already have subinterface 0 configured are not touched and an error is returned,
+// so that the link test never changes the user config.
+func (s *srl) AddTestAddrs(ctx context.Context, addrs map[string]string) error {
+	ifaces := make([]string, 0, len(addrs))
+	for i := range addrs {
+		ifaces = append(ifaces, i)
+	}
+	sort.Strings(ifaces)
+
+	var cmds []string
+	for _, i := range ifaces {
+		name := srlInterfaceName(i)
+		if name == "" {
+			return fmt.Errorf("%s: interface %s doesn't follow the eX-Y naming", s.cfg.ShortName, i)
+		}
+		stdout, stderr, err := s.runtime.Exec(ctx, s.cfg.LongName, []string{
+			"sr_cli", "-d", "info", "interface", name, "subinterface", testSubinterface,
+		})
+		if err != nil || len(stderr) > 0 {
+			return fmt.Errorf("%s: failed to read %s config: %v %s", s.cfg.ShortName, name, err, stderr)
+		}
+		if strings.TrimSpace(string(stdout)) != "" {
+			return fmt.Errorf("%s: subinterface %s.%s is already configured", s.cfg.ShortName, name, testSubinterface)
+		}
+
+		sub := fmt.Sprintf("interface %s subinterface %s", name, testSubinterface)
+		cmds = append(cmds,
+			fmt.Sprintf("set / interface %s admin-state enable", name),
+			fmt.Sprintf("set / %s ipv4 address %s", sub, addrs[i]),
+			fmt.Sprintf("set / network-instance default interface %s.%s", name, testSubinterface),
+		)
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	if s.schemaSupported(ctx, ipv4AdminStateCmd) {
+		for _, i := range ifaces {
+			cmds = append(cmds, fmt.Sprintf("set / interface %s subinterface %s ipv4 admin-state enable",
+				srlInterfaceName(i), testSubinterface))
+		}
+	}
+
+	log.Debugf("node %s: adding link test addresses %v", s.cfg.ShortName, addrs)
+	return s.pushCLIConfig(ctx, strings.Join(cmds, "\n")+"\n"+s.commitCmd())
+}
+
+// RemoveTestAddrs deletes the config added by AddTestAddrs for the passed container interfaces
+func (s *srl) RemoveTestAddrs(ctx context.Context, ifaces []string) error {
+	var cmds []string
+	for _, i := range ifaces {
+		name := srlInterfaceName(i)
+		if name == "" {
+			continue
+		}
+		cmds = append(cmds,
+			fmt.Sprintf("delete / network-instance default interface %s.%s", name, testSubinterface),
+			fmt.Sprintf("delete / interface %s subinterface %s", name, testSubinterface),
+		)
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	log.Debugf("node %s: removing link test addresses from %v", s.cfg.ShortName, ifaces)
+	return s.pushCLIConfig(ctx, strings.Join(cmds, "\n")+"\n"+s.commitCmd())
+}
+
+// Ping sends ICMP echo requests to addr from the default network-instance.
+// an error is returned if none of the requests is answered.
+func (s *srl) Ping(ctx context.Context, addr string) error {
+	res, err := s.runtime.ExecWithResult(ctx, s.cfg.LongName, []string{
+		"ip", "netns", "exec", defaultNetnsName, "ping", "-c", "3", "-W", "1", "-q", addr,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: failed to ping %s: %v", s.cfg.ShortName, addr, err)
+	}
+	// ping exits with a non-zero code when no reply is received
+	if res.ExitCode != 0 {
+		return fmt.Errorf("%s: no reply from %s: %s", s.cfg.ShortName, addr,
+			strings.TrimSpace(res.Stdout+"\n"+res.Stderr))
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// linkRuntime reports the subinterfaces in configured as present, answers pings to reachable addresses
+// and records the configs applied to the node with the lab dir labDir
+type linkRuntime struct {
+	runtime.ContainerRuntime
+	labDir     string
+	configured map[string]bool
+	reachable  map[string]bool
+	configs    []string
+}
+
+func (r *linkRuntime) Exec(_ context.Context, _ string, cmd []string) ([]byte, []byte, error) {
+	c := strings.Join(cmd, " ")
+	if cfg, ok := appliedConfig(r.labDir, cmd); ok {
+		r.configs = append(r.configs, cfg)
+	}
+	if strings.HasPrefix(c, "sr_cli -d info interface ethernet") && r.configured[cmd[4]] {
+		return []byte("subinterface 0 {\n}\n"), nil, nil
+	}
+	return nil, nil, nil
+}
+
+func (r *linkRuntime) ExecWithResult(ctx context.Context, id string, cmd []string) (*runtime.ExecResult, error) {
+	if cmd[0] != "ip" {
+		return execResult(r.Exec(ctx, id, cmd))
+	}
+	if r.reachable[cmd[len(cmd)-1]] {
+		return &runtime.ExecResult{}, nil
+	}
+	return &runtime.ExecResult{Stdout: "3 packets transmitted, 0 received, 100% packet loss", ExitCode: 1}, nil
+}
+
+func TestAddTestAddrs(t *testing.T) {
+	tests := map[string]struct {
+		configured map[string]bool
+		addrs      map[string]string
+		wantErr    bool
+		want       []string
+	}{
+		"two interfaces": {
+			addrs: map[string]string{"e1-2": "100.64.0.2/31", "e1-1": "100.64.0.0/31"},
+			want: []string{
+				"set / interface ethernet-1/1 admin-state enable",
+				"set / interface ethernet-1/1 subinterface 0 ipv4 address 100.64.0.0/31",
+				"set / network-instance default interface ethernet-1/1.0",
+				"set / interface ethernet-1/2 admin-state enable",
+				"set / interface ethernet-1/2 subinterface 0 ipv4 address 100.64.0.2/31",
+				"set / network-instance default interface ethernet-1/2.0",
+				"set / interface ethernet-1/1 subinterface 0 ipv4 admin-state enable",
+				"set / interface ethernet-1/2 subinterface 0 ipv4 admin-state enable",
+				"commit now",
+			},
+		},
+		"subinterface configured": {
+			configured: map[string]bool{"ethernet-1/2": true},
+			addrs:      map[string]string{"e1-1": "100.64.0.0/31", "e1-2": "100.64.0.2/31"},
+			wantErr:    true,
+		},
+		"unmapped interface": {
+			addrs:   map[string]string{"eth1": "100.64.0.0/31"},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			labDir := newLabDir(t)
+			r := &linkRuntime{labDir: labDir, configured: tc.configured}
+			s := &srl{
+				cfg:        &types.NodeConfig{ShortName: "srl1", LongName: "clab-lab-srl1", LabDir: labDir},
+				runtime:    r,
+				commitMode: commitModeNow,
+			}
+			err := s.AddTestAddrs(context.Background(), tc.addrs)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if len(r.configs) != 0 {
+					t.Errorf("config pushed despite the error: %v", r.configs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(r.configs) != 1 {
+				t.Fatalf("want 1 config push, got %d", len(r.configs))
+			}
+			for _, l := range tc.want {
+				if !strings.Contains(r.configs[0], l+"\n") {
+					t.Errorf("pushed config doesn't have %q:\n%s", l, r.configs[0])
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveTestAddrs(t *testing.T) {
+	labDir := newLabDir(t)
+	r := &linkRuntime{labDir: labDir}
+	s := &srl{
+		cfg:        &types.NodeConfig{ShortName: "srl1", LongName: "clab-lab-srl1", LabDir: labDir},
+		runtime:    r,
+		commitMode: commitModeNow,
+	}
+	if err := s.RemoveTestAddrs(context.Background(), []string{"e1-1", "eth0"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.configs) != 1 {
+		t.Fatalf("want 1 config push, got %d", len(r.configs))
+	}
+	for _, l := range []string{
+		"delete / network-instance default interface ethernet-1/1.0",
+		"delete / interface ethernet-1/1 subinterface 0",
+	} {
+		if !strings.Contains(r.configs[0], l+"\n") {
+			t.Errorf("pushed config doesn't have %q:\n%s", l, r.configs[0])
+		}
+	}
+}
+
+func TestPing(t *testing.T) {
+	s := &srl{
+		cfg:     &types.NodeConfig{ShortName: "srl1", LongName: "clab-lab-srl1"},
+		runtime: &linkRuntime{reachable: map[string]bool{"100.64.0.1": true}},
+	}
+	if err := s.Ping(context.Background(), "100.64.0.1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := s.Ping(context.Background(), "100.64.0.3"); err == nil {
+		t.Error("expected an error for an unreachable address")
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/docker/go-connections/nat"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/srl-labs/containerlab/nodes"
+)
+
+// default ports of the management servers
+const (
+	defaultGNMIPort         = 57400
+	defaultJSONRPCHTTPPort  = 80
+	defaultJSONRPCHTTPSPort = 443
+	// the gRIBI and P4Runtime servers are enabled on their default ports
+	gribiPort = 57401
+	p4rtPort  = 9559
+)
+
+// mgmtPort is a port of a management server
+type mgmtPort struct {
+	server string
+	port   int
+	// default port of the server
+	def int
+}
+
+// initPorts sets the ports of the gNMI and JSON-RPC servers from the port labels
+// and returns an error if a port is out of range or the enabled servers share a port.
+func (s *srl) initPorts() error {
+	for _, p := range []struct {
+		label string
+		port  *int
+		def   int
+	}{
+		{gnmiPortLabel, &s.gnmiPort, defaultGNMIPort},
+		{jsonRPCHTTPPortLabel, &s.jsonRPCHTTPPort, defaultJSONRPCHTTPPort},
+		{jsonRPCHTTPSPortLabel, &s.jsonRPCHTTPSPort, defaultJSONRPCHTTPSPort},
+	} {
+		*p.port = p.def
+		v, ok := s.cfg.Labels[p.label]
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("wrong value %q set with %s label, should be a port number in the 1-65535 range", v, p.label)
+		}
+		*p.port = n
+	}
+
+	seen := make(map[int]string)
+	for _, p := range s.mgmtPorts() {
+		if other, ok := seen[p.port]; ok {
+			return fmt.Errorf("node %s: %s and %s servers can't both use port %d", s.cfg.ShortName, other, p.server, p.port)
+		}
+		seen[p.port] = p.server
+	}
+	return nil
+}
+
+// mgmtPorts returns the ports of the management servers enabled by the default config
+func (s *srl) mgmtPorts() []mgmtPort {
+	var ports []mgmtPort
+	if s.gnmi {
+		ports = append(ports, mgmtPort{"gNMI", s.gnmiPort, defaultGNMIPort})
+	}
+	if s.jsonRPC {
+		ports = append(ports, mgmtPort{"JSON-RPC http", s.jsonRPCHTTPPort, defaultJSONRPCHTTPPort})
+		if s.tls {
+			ports = append(ports, mgmtPort{"JSON-RPC https", s.jsonRPCHTTPSPort, defaultJSONRPCHTTPSPort})
+		}
+	}
+	if s.gribi {
+		ports = append(ports, mgmtPort{"gRIBI", gribiPort, gribiPort})
+	}
+	if s.p4rt {
+		ports = append(ports, mgmtPort{"P4Runtime", p4rtPort, p4rtPort})
+	}
+	return ports
+}
+
+// GetMgmtPorts returns the ports of the gNMI and JSON-RPC servers enabled by the default config
+func (s *srl) GetMgmtPorts() nodes.MgmtPorts {
+	var p nodes.MgmtPorts
+	if s.gnmi {
+		p.GNMI = s.gnmiPort
+	}
+	if s.jsonRPC {
+		p.JSONRPCHTTP = s.jsonRPCHTTPPort
+		if s.tls {
+			p.JSONRPCHTTPS = s.jsonRPCHTTPSPort
+		}
+	}
+	return p
+}
+
+// exposeMgmtPorts adds the ports of the management servers to the exposed container ports,
+// and warns about the published container ports the servers were moved away from
+func (s *srl) exposeMgmtPorts() {
+	for _, p := range s.mgmtPorts() {
+		if s.cfg.PortSet == nil {
+			s.cfg.PortSet = make(nat.PortSet)
+		}
+		s.cfg.PortSet[nat.Port(fmt.Sprintf("%d/tcp", p.port))] = struct{}{}
+		if p.port == p.def {
+			continue
+		}
+		if _, ok := s.cfg.PortBindings[nat.Port(fmt.Sprintf("%d/tcp", p.def))]; ok {
+			log.Warnf("node %s: container port %d is published, but the %s server listens on port %d", s.cfg.ShortName, p.def, p.server, p.port)
+		}
+	}
+}
+
+// nonDefault returns the port when it is not the default one and 0 otherwise,
+// the default config sets the ports of the servers only when they are changed
+func nonDefault(port, def int) int {
+	if port == def {
+		return 0
+	}
+	return port
+}
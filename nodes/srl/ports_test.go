@@ -0,0 +1,78 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestInitPorts(t *testing.T) {
+	tests := map[string]struct {
+		labels  map[string]string
+		wantErr bool
+		// exposed container ports
+		want []string
+	}{
+		"default": {
+			want: []string{"57400/tcp", "80/tcp", "443/tcp"},
+		},
+		"changed": {
+			labels: map[string]string{gnmiPortLabel: "50052", jsonRPCHTTPSPortLabel: "8443"},
+			want:   []string{"50052/tcp", "80/tcp", "8443/tcp"},
+		},
+		"json-rpc disabled": {
+			labels: map[string]string{jsonRPCLabel: "false", jsonRPCHTTPPortLabel: "57400"},
+			want:   []string{"57400/tcp"},
+		},
+		"out of range": {
+			labels:  map[string]string{gnmiPortLabel: "65536"},
+			wantErr: true,
+		},
+		"not a number": {
+			labels:  map[string]string{jsonRPCHTTPPortLabel: "http"},
+			wantErr: true,
+		},
+		"conflict": {
+			labels:  map[string]string{jsonRPCHTTPPortLabel: "443"},
+			wantErr: true,
+		},
+		"gribi conflict": {
+			labels:  map[string]string{gribiLabel: "true", gnmiPortLabel: "57401"},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := new(srl)
+			err := s.Init(&types.NodeConfig{
+				ShortName: "srl1",
+				Labels:    tc.labels,
+				Sysctls:   map[string]string{},
+			})
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("wanted an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(s.cfg.PortSet) != len(tc.want) {
+				t.Fatalf("wanted exposed ports %v, got %v", tc.want, s.cfg.PortSet)
+			}
+			for _, p := range tc.want {
+				if _, ok := s.cfg.PortSet[nat.Port(p)]; !ok {
+					t.Fatalf("wanted exposed ports %v, got %v", tc.want, s.cfg.PortSet)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+// readyCheck is a boot status check of the cli ready probe, which is passed when the state leaf reported by cmd has the value
+type readyCheck struct {
+	cmd   []string
+	leaf  string
+	value string
+}
+
+// srlReadyChecks are the checks of the cli ready probe keyed by the first SR Linux release they apply to.
+// the node is checked with the checks of the latest release not newer than the release of its image,
+// or of the latest release listed if the release of the image is unknown.
+// when the state paths change in a new release, the checks of that release are added here.
+var srlReadyChecks = map[srlVersion][]readyCheck{
+	{}: {
+		// the mgmt_server is running
+		{cmd: mgmtServerRdyCmd, leaf: "state", value: "running"},
+		// and the initial commit completed
+		{cmd: commitCompleteCmd, leaf: "status", value: "complete"},
+	},
+}
+
+// releaseReadyChecks returns the checks of the cli ready probe for the SR Linux release v, known is false if the release is unknown
+func releaseReadyChecks(v srlVersion, known bool) []readyCheck {
+	var since srlVersion
+	var checks []readyCheck
+	for r, c := range srlReadyChecks {
+		if known && v.less(r) {
+			continue
+		}
+		if checks == nil || since.less(r) {
+			since, checks = r, c
+		}
+	}
+	return checks
+}
+
+// parseReadyChecks parses the checks set with the ready-check label,
+// a semicolon separated list of the state leaves with their expected values, e.g. system app-management application mgmt_server state=running.
+// the last element of the path is the leaf checked in the output of `sr_cli -d info from state <path>`.
+func parseReadyChecks(v string) ([]readyCheck, error) {
+	var checks []readyCheck
+	for _, c := range strings.Split(v, ";") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		i := strings.LastIndex(c, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("ready check %q should be a state leaf path with the expected value, e.g. system app-management application mgmt_server state=running", c)
+		}
+		path, err := shlex.Split(c[:i])
+		if err != nil {
+			return nil, fmt.Errorf("ready check %q: %v", c, err)
+		}
+		value := strings.TrimSpace(c[i+1:])
+		if len(path) == 0 || value == "" {
+			return nil, fmt.Errorf("ready check %q should be a state leaf path with the expected value, e.g. system app-management application mgmt_server state=running", c)
+		}
+		checks = append(checks, readyCheck{
+			cmd:   append([]string{"sr_cli", "-d", "info", "from", "state"}, path...),
+			leaf:  path[len(path)-1],
+			value: value,
+		})
+	}
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("no ready checks set")
+	}
+	return checks, nil
+}
+
+// bootChecks returns the checks of the cli ready probe: the checks set with the ready-check label
+// or the checks of the SR Linux release of the node's image
+func (s *srl) bootChecks(ctx context.Context) []readyCheck {
+	if s.readyChecks != nil {
+		return s.readyChecks
+	}
+	return releaseReadyChecks(s.imageVersion(ctx))
+}
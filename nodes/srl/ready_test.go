@@ -0,0 +1,188 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestParseReadyChecks(t *testing.T) {
+	tests := map[string]struct {
+		label   string
+		want    []string
+		wantErr bool
+	}{
+		"single": {
+			label: "system app-management application mgmt_server state=running",
+			want:  []string{"sr_cli -d info from state system app-management application mgmt_server state|state|running"},
+		},
+		"several": {
+			label: "system app-management application mgmt_server state=running; system configuration commit 1 status = complete;",
+			want: []string{
+				"sr_cli -d info from state system app-management application mgmt_server state|state|running",
+				"sr_cli -d info from state system configuration commit 1 status|status|complete",
+			},
+		},
+		"quoted-key": {
+			label: `interface "ethernet-1/1" oper-state=up`,
+			want:  []string{"sr_cli -d info from state interface ethernet-1/1 oper-state|oper-state|up"},
+		},
+		"no-value":  {label: "system app-management application mgmt_server state", wantErr: true},
+		"no-path":   {label: "=running", wantErr: true},
+		"empty":     {label: " ; ", wantErr: true},
+		"unclosed":  {label: `interface "ethernet-1/1 oper-state=up`, wantErr: true},
+		"empty-val": {label: "system information version=", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			checks, err := parseReadyChecks(tc.label)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("wanted an error, got %v", checks)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([]string, 0, len(checks))
+			for _, c := range checks {
+				got = append(got, strings.Join(c.cmd, " ")+"|"+c.leaf+"|"+c.value)
+			}
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Fatalf("wanted %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseReadyChecks(t *testing.T) {
+	defer func(c map[srlVersion][]readyCheck) { srlReadyChecks = c }(srlReadyChecks)
+	srlReadyChecks = map[srlVersion][]readyCheck{
+		{}:                    {{leaf: "default"}},
+		{major: 22, minor: 3}: {{leaf: "22.3"}},
+		{major: 22, minor: 6}: {{leaf: "22.6"}},
+	}
+
+	tests := map[string]struct {
+		version srlVersion
+		known   bool
+		want    string
+	}{
+		"old-release":     {version: srlVersion{major: 21, minor: 6}, known: true, want: "default"},
+		"first-release":   {version: srlVersion{major: 22, minor: 3}, known: true, want: "22.3"},
+		"between":         {version: srlVersion{major: 22, minor: 4}, known: true, want: "22.3"},
+		"newer-release":   {version: srlVersion{major: 23, minor: 3}, known: true, want: "22.6"},
+		"unknown-release": {want: "22.6"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := releaseReadyChecks(tc.version, tc.known)
+			if len(got) != 1 || got[0].leaf != tc.want {
+				t.Fatalf("wanted the checks of %s, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+// leafRuntime reports the state leaves of the sr_cli state commands
+type leafRuntime struct {
+	runtime.ContainerRuntime
+	// outputs keyed by the sr_cli state path
+	out map[string]string
+}
+
+func (r *leafRuntime) Exec(_ context.Context, _ string, cmd []string) ([]byte, []byte, error) {
+	return []byte(r.out[strings.Join(cmd[5:], " ")]), nil, nil
+}
+
+func (r *leafRuntime) ExecWithResult(ctx context.Context, id string, cmd []string) (*runtime.ExecResult, error) {
+	return execResult(r.Exec(ctx, id, cmd))
+}
+
+func TestReadyCheckLabel(t *testing.T) {
+	tests := map[string]struct {
+		out  map[string]string
+		want nodes.NodeStatus
+	}{
+		"custom-leaf-down": {
+			out: map[string]string{
+				"system app-management application mgmt_server state": "state running",
+				"system app-management application idb_server state":  "state starting",
+			},
+			want: nodes.NodeStatusBooting,
+		},
+		"custom-leaf-up": {
+			// the default commit check is not run
+			out: map[string]string{
+				"system app-management application mgmt_server state": "state running",
+				"system app-management application idb_server state":  "state running",
+			},
+			want: nodes.NodeStatusReady,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := new(srl)
+			err := s.Init(&types.NodeConfig{
+				ShortName: "srl1",
+				Labels: map[string]string{
+					readyCheckLabel: "system app-management application mgmt_server state=running;" +
+						"system app-management application idb_server state=running",
+				},
+				Sysctls: map[string]string{},
+			}, nodes.WithRuntime(&leafRuntime{out: tc.out}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.Status(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Fatalf("wanted status %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	err := new(srl).Init(&types.NodeConfig{
+		ShortName: "srl1",
+		Labels:    map[string]string{readyCheckLabel: "system app-management application mgmt_server state"},
+		Sysctls:   map[string]string{},
+	})
+	if err == nil {
+		t.Fatalf("wanted an error for a ready check without a value, got nil")
+	}
+}
+
+func TestCLIReadyTimeout(t *testing.T) {
+	s := &srl{
+		cfg:     &types.NodeConfig{ShortName: "srl1"},
+		runtime: &leafRuntime{out: map[string]string{}},
+		// the node is not ready and the next check is far beyond the boot timeout
+		readyPollInterval: time.Hour,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := s.cliReady(ctx)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("wanted a timeout error, got %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("wanted the probe to stop once the context expired, returned after %s", d)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Reconcile compares the config rendered from the node's startup-config with the running config
+// and returns the diff reported by SR Linux, which is empty when the running config matches.
+// a full startup config is loaded into the candidate config in place of the running config,
+// while a startup config in merge mode is applied on top of it, like when the node is deployed.
+// with apply set the diff is committed, the candidate config is discarded otherwise.
+func (s *srl) Reconcile(ctx context.Context, apply bool) (string, error) {
+	if s.cfg.StartupConfig == "" {
+		return "", fmt.Errorf("%s: node has no startup-config to reconcile the running config with", s.cfg.ShortName)
+	}
+	if s.configReadOnly {
+		return "", fmt.Errorf("%s: config directory is read-only as set with %s label, running config can't be reconciled", s.cfg.ShortName, configReadOnlyLabel)
+	}
+
+	tpl, err := s.startupConfigTemplate()
+	if err != nil {
+		return "", err
+	}
+	desired, err := s.cfg.RenderConfigTemplate(tpl, s.startupConfigData())
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to render startup-config: %v", s.cfg.ShortName, err)
+	}
+
+	// the CLI commands changing the candidate config to the desired config
+	var edit string
+	if s.cfg.StartupConfigMode == startupConfigModeMerge {
+		edit = strings.TrimRight(string(desired), "\n")
+	} else {
+		p := filepath.Join(s.cfg.LabDir, "config", desiredConfigFile)
+		// the desired config might hold secrets, e.g. the TLS key, so it is readable by the container user only
+		if err := os.WriteFile(p, desired, 0600); err != nil {
+			return "", fmt.Errorf("%s: failed to write desired config: %v", s.cfg.ShortName, err)
+		}
+		defer os.Remove(p)
+		if err := os.Chmod(p, 0600); err != nil {
+			return "", err
+		}
+		s.chownToUser(p)
+		edit = "load file " + path.Join(srlConfigDir, desiredConfigFile)
+	}
+
+	diff, err := s.diffCLIConfig(ctx, edit)
+	if err != nil {
+		return "", err
+	}
+	if diff == "" || !apply {
+		return diff, nil
+	}
+
+	log.Infof("Applying the startup-config diff to Nokia SR Linux '%s' node", s.cfg.ShortName)
+	if err := s.pushCLIConfig(ctx, edit+"\n"+s.commitCmd()); err != nil {
+		return "", err
+	}
+	return diff, nil
+}
+
+// diffCLIConfig applies the CLI commands to the candidate config and returns the diff
+// of the candidate config against the running config, the candidate config is discarded.
+func (s *srl) diffCLIConfig(ctx context.Context, edit string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.saveTimeout)
+	defer cancel()
+
+	p := filepath.Join(s.cfg.LabDir, "config", cliConfigFile)
+	if err := os.WriteFile(p, []byte(edit+"\ndiff\ndiscard now\n"), 0600); err != nil {
+		return "", fmt.Errorf("%s: failed to write config file: %v", s.cfg.ShortName, err)
+	}
+	defer os.Remove(p)
+	// WriteFile doesn't change permissions of an existing file
+	if err := os.Chmod(p, 0600); err != nil {
+		return "", err
+	}
+	s.chownToUser(p)
+
+	res, err := s.runtime.ExecWithResult(ctx, s.cfg.LongName, []string{
+		"bash",
+		"-c",
+		"sr_cli -ed < " + path.Join(srlConfigDir, cliConfigFile),
+	})
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("%s: config diff timed out after %s", s.cfg.ShortName, s.saveTimeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to execute cmd: %v", s.cfg.ShortName, err)
+	}
+	if res.ExitCode != 0 {
+		stderr := strings.TrimSpace(res.Stderr)
+		if stderr == "" {
+			stderr = fmt.Sprintf("sr_cli exited with code %d", res.ExitCode)
+		}
+		return "", fmt.Errorf("%s: failed to diff config: %s", s.cfg.ShortName, s.redact(stderr))
+	}
+	return strings.TrimSpace(s.redact(res.Stdout)), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// reconcileRuntime reports diff for the CLI configs ending with the diff command
+// and records the other CLI configs applied to the node, as well as the desired config loaded by the configs
+type reconcileRuntime struct {
+	runtime.ContainerRuntime
+	labDir  string
+	diff    string
+	diffs   []string
+	configs []string
+	desired string
+}
+
+func (r *reconcileRuntime) ExecWithResult(_ context.Context, _ string, cmd []string) (*runtime.ExecResult, error) {
+	cfg, ok := appliedConfig(r.labDir, cmd)
+	if !ok {
+		return &runtime.ExecResult{Stderr: "unexpected cmd", ExitCode: 1}, nil
+	}
+	if b, err := os.ReadFile(filepath.Join(r.labDir, "config", desiredConfigFile)); err == nil {
+		r.desired = string(b)
+	}
+	if strings.HasSuffix(cfg, "diff\ndiscard now\n") {
+		r.diffs = append(r.diffs, cfg)
+		return &runtime.ExecResult{Stdout: r.diff}, nil
+	}
+	r.configs = append(r.configs, cfg)
+	return &runtime.ExecResult{}, nil
+}
+
+func TestReconcile(t *testing.T) {
+	tests := map[string]struct {
+		mode        string
+		startup     string
+		diff        string
+		apply       bool
+		wantDiff    string
+		wantDesired string
+		wantConfigs []string
+	}{
+		"replace-diff": {
+			startup:     `{"system": {"name": {"host-name": "{{ .ShortName }}"}}}`,
+			diff:        "      system {\n+         name {\n",
+			wantDiff:    "system {\n+         name {",
+			wantDesired: `{"system": {"name": {"host-name": "srl1"}}}`,
+		},
+		"replace-apply": {
+			startup:     `{"system": {"name": {"host-name": "{{ .ShortName }}"}}}`,
+			diff:        "+ name",
+			apply:       true,
+			wantDiff:    "+ name",
+			wantDesired: `{"system": {"name": {"host-name": "srl1"}}}`,
+			wantConfigs: []string{"load file /etc/opt/srlinux/.clab-desired-config\ncommit save\n"},
+		},
+		"merge-apply": {
+			mode:        startupConfigModeMerge,
+			startup:     "set / system name host-name {{ .ShortName }}\n",
+			diff:        "+ name",
+			apply:       true,
+			wantDiff:    "+ name",
+			wantConfigs: []string{"set / system name host-name srl1\ncommit save\n"},
+		},
+		"no-diff": {
+			mode:    startupConfigModeMerge,
+			startup: "set / system name host-name {{ .ShortName }}\n",
+			apply:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			labDir := newLabDir(t)
+			startup := filepath.Join(t.TempDir(), "startup.json")
+			if tc.mode == startupConfigModeMerge {
+				startup = filepath.Join(t.TempDir(), "startup.cli")
+			}
+			if err := os.WriteFile(startup, []byte(tc.startup), 0644); err != nil {
+				t.Fatal(err)
+			}
+			r := &reconcileRuntime{labDir: labDir, diff: tc.diff}
+			s := &srl{
+				cfg: &types.NodeConfig{
+					ShortName:         "srl1",
+					LongName:          "clab-lab-srl1",
+					LabDir:            labDir,
+					StartupConfig:     startup,
+					StartupConfigMode: tc.mode,
+				},
+				runtime:     r,
+				commitMode:  commitModeSave,
+				saveTimeout: time.Second,
+			}
+
+			diff, err := s.Reconcile(context.Background(), tc.apply)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff != tc.wantDiff {
+				t.Fatalf("wanted diff %q, got %q", tc.wantDiff, diff)
+			}
+			if len(r.diffs) != 1 {
+				t.Fatalf("wanted the config to be diffed once, got %d diffs", len(r.diffs))
+			}
+			if r.desired != tc.wantDesired {
+				t.Fatalf("wanted desired config %q, got %q", tc.wantDesired, r.desired)
+			}
+			if fmt.Sprint(r.configs) != fmt.Sprint(tc.wantConfigs) {
+				t.Fatalf("wanted applied configs %q, got %q", tc.wantConfigs, r.configs)
+			}
+			for _, f := range []string{desiredConfigFile, cliConfigFile} {
+				if utils.FileExists(filepath.Join(labDir, "config", f)) {
+					t.Fatalf("wanted %s to be removed", f)
+				}
+			}
+		})
+	}
+
+	s := &srl{cfg: &types.NodeConfig{ShortName: "srl1"}}
+	if _, err := s.Reconcile(context.Background(), false); err == nil {
+		t.Fatalf("wanted an error for a node without a startup-config, got nil")
+	}
+}
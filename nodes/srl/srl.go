@@ -6,14 +6,23 @@ package srl
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"embed"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -31,24 +40,221 @@ import (
 const (
 	srlDefaultType = "ixrd2"
 
-	readyTimeout = time.Minute * 2 // max wait time for node to boot
+	readyTimeout = time.Minute * 2 // default max wait time for node to boot
 	retryTimer   = time.Second
+	// the readiness poll interval doubles after each check up to that cap
+	maxReadyPollInterval = time.Second * 4
+
+	fetchTimeout = time.Second * 30 // default max time to download a remote startup-config
+	saveTimeout  = time.Minute      // default max time to save the node config
+
+	// name of the CLI config file written to the node config dir, which is bind mounted to the container
+	cliConfigFile = ".clab-config"
+	// node config dir sub dir the certificate and key of the clab-profile TLS server profile are written to, and their names
+	tlsProfileDir      = "tls"
+	tlsProfileCertFile = "clab-profile.pem"
+	tlsProfileKeyFile  = "clab-profile-key.pem"
+	// name of the file the running config is written to in the node config dir by GetRunningConfig
+	runningConfigFile = ".clab-running-config"
+	// max size of the running config returned by GetRunningConfig
+	maxRunningConfigSize = 64 << 20
+	// name of the file the config rendered from the startup-config is written to in the node config dir by Reconcile
+	desiredConfigFile = ".clab-desired-config"
+
+	// lab dir sub dir the post-deploy scripts are copied to and its mount path in the container
+	postDeployScriptsDir      = "post-deploy-scripts"
+	postDeployScriptsMountDir = "/tmp/clab-post-deploy-scripts"
+
+	// lab dir file the CLI history is kept in and its mount path, the history file of the container root user
+	cliHistoryFile      = "cli-history"
+	cliHistoryMountPath = "/root/.srlinux_history"
+
+	// lower half of the interface ID the IPv6 mgmt addresses assigned from a generated unique local subnet start from, ::c1ab:0
+	ulaMgmtAddrBase = 0xc1ab0000
+
+	// config push retries on transient commit errors
+	maxPushAttempts     = 5
+	pushRetryBackoff    = time.Second
+	maxPushRetryBackoff = time.Second * 8
+
+	// readyProbeLabel is a node label that selects the probe used to detect that the node has booted
+	readyProbeLabel = "clab.srl.ready-probe"
+	// readiness probes: cli execs sr_cli inside the container, gnmi subscribes to the mgmt_server state
+	readyProbeCLI  = "cli"
+	readyProbeGNMI = "gnmi"
+	// startup config modes. in replace mode the startup config is used as config.json,
+	// in merge mode it is a CLI snippet applied on top of the default config
+	startupConfigModeReplace = "replace"
+	startupConfigModeMerge   = "merge"
+	// name of the rendered startup-config snippet used in merge mode
+	mergeConfigFile = "startup-config.cli"
+	// extension of the gzipped startup-config files
+	gzipExt = ".gz"
+
+	// skipDefaultConfigLabel is a node label that disables the default config provisioning in PostDeploy
+	skipDefaultConfigLabel = "clab.srl.skip-default-config"
+	// deterministicMACLabel is a node label that makes the chassis base mac derived from the node name
+	deterministicMACLabel = "clab.srl.deterministic-mac"
+	// fetchTimeoutLabel is a node label that sets the timeout for downloading a remote startup-config
+	fetchTimeoutLabel = "clab.srl.startup-config-fetch-timeout"
+	// savedConfigLabel is a node label that sets the lab dir file name the saved config is copied to
+	savedConfigLabel = "clab.srl.saved-config-file"
+	// saveTimeoutLabel is a node label that sets the timeout for saving the node config
+	saveTimeoutLabel = "clab.srl.save-timeout"
+	// default lab dir file name the saved config is copied to by SaveConfig
+	savedConfigFile = "saved-config.json"
+	// mtlsLabel is a node label that enables client certificate authentication with the lab CA as a trust anchor
+	mtlsLabel = "clab.srl.mtls"
+	// waitJSONRPCLabel is a node label that makes PostDeploy wait for the JSON-RPC https server to be ready
+	waitJSONRPCLabel = "clab.srl.wait-json-rpc"
+	// topologyTemplateLabel is a node label that sets the path to a user provided topology file template
+	topologyTemplateLabel = "clab.srl.topology-template"
+	// resetConfigLabel is a node label that makes the node start from a clean config dir on every deployment
+	resetConfigLabel = "clab.srl.reset-config"
+	// idleTimeoutLabel is a node label that sets the CLI sessions idle timeout in seconds, 0 disables it
+	idleTimeoutLabel = "clab.srl.idle-timeout"
+	// default CLI sessions idle timeout in seconds
+	defaultIdleTimeout = 7200
+	// licenseB64Label is a node label that provides the license as a base64 encoded string
+	licenseB64Label = "clab.srl.license-b64"
+	// licenseDirLabel is a node label that sets the dir with a pool of licenses the node license is selected from,
+	// licenseSelectLabel sets how the license is selected: by the node index or by the node base mac
+	licenseDirLabel    = "clab.srl.license-dir"
+	licenseSelectLabel = "clab.srl.license-select"
+	licenseSelectIndex = "index"
+	licenseSelectMAC   = "mac"
+	// rootlessLabel is a node label that overrides the detection of a rootless container runtime
+	rootlessLabel = "clab.srl.rootless"
+	// configReadOnlyLabel is a node label that bind mounts the config dir read-only
+	configReadOnlyLabel = "clab.srl.config-readonly"
+	// commitModeLabel is a node label that sets whether the config applied by containerlab is saved to the startup config
+	commitModeLabel = "clab.srl.commit-mode"
+	commitModeSave  = "save"
+	commitModeNow   = "now"
+	// gribiLabel and p4rtLabel are node labels that enable the gRIBI and P4Runtime servers with the clab-profile
+	gribiLabel = "clab.srl.gribi"
+	p4rtLabel  = "clab.srl.p4rt"
+	// gnmiLabel and jsonRPCLabel are node labels that, when set to false, leave the gNMI and JSON-RPC servers out of the default config
+	gnmiLabel    = "clab.srl.gnmi"
+	jsonRPCLabel = "clab.srl.json-rpc"
+	// gnmiPortLabel, jsonRPCHTTPPortLabel and jsonRPCHTTPSPortLabel are node labels that set the ports of the gNMI and JSON-RPC servers
+	gnmiPortLabel         = "clab.srl.gnmi-port"
+	jsonRPCHTTPPortLabel  = "clab.srl.json-rpc-http-port"
+	jsonRPCHTTPSPortLabel = "clab.srl.json-rpc-https-port"
+	// tlsLabel is a node label that, when set to false, makes the management servers run without TLS
+	tlsLabel = "clab.srl.tls"
+	// autostartLabel is a node label that, when set to false, creates the container without starting SR Linux
+	autostartLabel = "clab.srl.autostart"
+	// tlsKeyTypeLabel is a node label that sets the private key type of the generated node certificate
+	tlsKeyTypeLabel = "clab.srl.tls-key-type"
+	// bootLogLinesLabel is a node label that sets the number of boot log lines reported when the node fails to boot
+	bootLogLinesLabel   = "clab.srl.boot-log-lines"
+	defaultBootLogLines = 20
+	// boot log lines longer than that are truncated
+	bootLogLineLen = 512
+	// adminUserLabel and adminPasswordLabel are node labels that set the credentials of a superuser configured by containerlab,
+	// adminPasswordFileLabel sets the path to a file holding the password instead of the password itself
+	adminUserLabel         = "clab.srl.admin-user"
+	adminPasswordLabel     = "clab.srl.admin-password"
+	adminPasswordFileLabel = "clab.srl.admin-password-file"
+	// mgmtNetworkInstanceLabel is a node label that sets the network-instance the management servers are enabled in
+	mgmtNetworkInstanceLabel   = "clab.srl.mgmt-network-instance"
+	defaultMgmtNetworkInstance = "mgmt"
+	// caCertLabel and caKeyLabel are node labels that set the paths to the certificate and key of an external CA
+	// the node certificate is signed with instead of the lab CA
+	caCertLabel = "clab.srl.ca-cert"
+	caKeyLabel  = "clab.srl.ca-key"
+	// lldpLabel is a node label that, when set to false, leaves LLDP out of the default config
+	lldpLabel = "clab.srl.lldp"
+	// persistCLIHistoryLabel is a node label that keeps the sr_cli history in the lab dir between deployments
+	persistCLIHistoryLabel = "clab.srl.persist-cli-history"
+	// readyPollIntervalLabel is a node label that sets the initial interval of the readiness checks
+	readyPollIntervalLabel = "clab.srl.ready-poll-interval"
+	// readyCheckLabel is a node label that sets the state leaves checked by the cli ready probe instead of the default ones,
+	// e.g. system app-management application mgmt_server state=running
+	readyCheckLabel = "clab.srl.ready-check"
+	// breakoutLabel is a node label that sets the breakout modes of the ports, e.g. e1-3:4x25G,e1-4:4x10G
+	breakoutLabel = "clab.srl.breakout"
+	// volumeLabel is a node label that sets the container path a named runtime volume of the node is mounted at,
+	// the volume keeps its data when the lab is destroyed
+	volumeLabel = "clab.srl.volume"
+	// skipSysctlsLabel is a node label that sets a comma separated list of the default sysctls not applied to the container
+	skipSysctlsLabel = "clab.srl.skip-sysctls"
+	// factory admin user, its password is set under the admin-user container
+	factoryAdminUser = "admin"
+	// replaces the admin password in the logged config and command output
+	redactedPassword = "<redacted>"
+	// max time to retrieve the boot log once the node failed to boot
+	bootLogTimeout = 5 * time.Second
+	// container dir that holds the SR Linux config and is bind mounted from the lab dir
+	srlConfigDir = "/etc/opt/srlinux/"
+	// max number of the DNS servers SR Linux can be configured with
+	maxDNSServers = 3
+	// range of the port MTU values supported by SR Linux ethernet interfaces
+	minInterfaceMTU = 1500
+	maxInterfaceMTU = 9500
+
 	// additional config that clab adds on top of the factory config
-	srlConfigCmdsTpl = `set / system tls server-profile clab-profile
-set / system tls server-profile clab-profile key "{{ .TLSKey }}"
-set / system tls server-profile clab-profile certificate "{{ .TLSCert }}"
-{{- if .TLSAnchor }}
+	srlConfigCmdsTpl = `
+{{- if .TLS -}}
+set / system tls server-profile clab-profile
+set / system tls server-profile clab-profile key "{{ .TLSKeyPath }}"
+set / system tls server-profile clab-profile certificate "{{ .TLSCertPath }}"
+{{ if .TLSAnchor -}}
 set / system tls server-profile clab-profile authenticate-client true
 set / system tls server-profile clab-profile trust-anchor "{{ .TLSAnchor }}"
-{{- else }}
+{{ else -}}
 set / system tls server-profile clab-profile authenticate-client false
-{{- end }}
-set / system gnmi-server admin-state enable network-instance mgmt admin-state enable tls-profile clab-profile
-set / system json-rpc-server admin-state enable network-instance mgmt http admin-state enable
-set / system json-rpc-server admin-state enable network-instance mgmt https admin-state enable tls-profile clab-profile
+{{ end -}}
+{{ end -}}
+{{ if .GNMI -}}
+set / system gnmi-server admin-state enable network-instance {{ .MgmtNetworkInstance }} admin-state enable{{ if .TLS }} tls-profile clab-profile{{ end }}{{ if .GNMIPort }} port {{ .GNMIPort }}{{ end }}
+{{ end -}}
+{{ if .JSONRPC -}}
+set / system json-rpc-server admin-state enable network-instance {{ .MgmtNetworkInstance }} http admin-state enable{{ if .JSONRPCHTTPPort }} port {{ .JSONRPCHTTPPort }}{{ end }}
+{{ if .TLS -}}
+set / system json-rpc-server admin-state enable network-instance {{ .MgmtNetworkInstance }} https admin-state enable tls-profile clab-profile{{ if .JSONRPCHTTPSPort }} port {{ .JSONRPCHTTPSPort }}{{ end }}
+{{ end -}}
+{{ end -}}
+{{ if .GRIBI -}}
+set / system gribi-server admin-state enable network-instance {{ .MgmtNetworkInstance }} admin-state enable{{ if .TLS }} tls-profile clab-profile{{ end }}
+{{ end -}}
+{{ if .P4RT -}}
+set / system p4rt-server admin-state enable network-instance {{ .MgmtNetworkInstance }} admin-state enable{{ if .TLS }} tls-profile clab-profile{{ end }}
+{{ end -}}
+{{ if .LLDP -}}
 set / system lldp admin-state enable
-set / system aaa authentication idle-timeout 7200
-commit save`
+{{ end -}}
+{{ if .DNS -}}
+set / system dns network-instance {{ .MgmtNetworkInstance }}
+{{ if .DNS.Servers -}}
+set / system dns server-list [{{ range .DNS.Servers }} {{ . }}{{ end }} ]
+{{ end -}}
+{{ if .DNS.Search -}}
+set / system dns search-list [{{ range .DNS.Search }} {{ . }}{{ end }} ]
+{{ end -}}
+{{ end -}}
+{{ if .IdleTimeoutSupported -}}
+set / system aaa authentication idle-timeout {{ .IdleTimeout }}
+{{ end -}}
+{{ range .Breakouts -}}
+set / interface {{ .Name }} breakout-mode num-breakout-ports {{ .Ports }} breakout-port-speed {{ .Speed }}
+{{ end -}}
+{{ range .InterfaceMTUs -}}
+set / interface {{ .Name }} mtu {{ .MTU }}
+{{ end -}}
+{{ if eq .AdminUser "admin" -}}
+set / system aaa authentication admin-user password "{{ .AdminPassword }}"
+{{ else if .AdminUser -}}
+set / system aaa authentication user {{ .AdminUser }} password "{{ .AdminPassword }}"
+set / system aaa authentication user {{ .AdminUser }} superuser true
+{{ end -}}
+{{ if .Extras -}}
+{{ range .Extras.SRLDefaultConfigSnippets -}}
+{{ . }}
+{{ end -}}
+{{ end -}}
+{{ .CommitCmd }}`
 )
 
 var (
@@ -77,10 +283,33 @@ var (
 	topologies embed.FS
 
 	saveCmd              = []string{"sr_cli", "-d", "tools", "system", "configuration", "save"}
-	mgmtServerRdyCmd, _  = shlex.Split("sr_cli -d info from state system app-management application mgmt_server state | grep running")
-	commitCompleteCmd, _ = shlex.Split("sr_cli -d info from state system configuration commit 1 status | grep complete")
+	techSupportCmd       = []string{"sr_cli", "-d", "tools", "system", "tech-support"}
+	versionCmd           = []string{"sr_cli", "-d", "info", "from", "state", "system", "information", "version"}
+	idleTimeoutCmd       = []string{"sr_cli", "-d", "info", "system", "aaa", "authentication", "idle-timeout"}
+	gribiServerCmd       = []string{"sr_cli", "-d", "info", "system", "gribi-server"}
+	p4rtServerCmd        = []string{"sr_cli", "-d", "info", "system", "p4rt-server"}
+	mgmtServerRdyCmd, _  = shlex.Split("sr_cli -d info from state system app-management application mgmt_server state")
+	commitCompleteCmd, _ = shlex.Split("sr_cli -d info from state system configuration commit 1 status")
 
 	srlCfgTpl, _ = template.New("srl-tls-profile").Parse(srlConfigCmdsTpl)
+
+	// lower-cased sr_cli errors that are retried when pushing config
+	transientCommitErrs = []string{
+		"commit in progress",
+		"datastore locked",
+		"database is locked",
+		"another commit",
+	}
+
+	// base macs allocated to the nodes keyed by the lab name, the nodes of a lab may be created concurrently.
+	// the macs are released when the nodes are deleted, so that a lab deployed again in the same process gets the same macs
+	baseMACs = struct {
+		sync.Mutex
+		m map[string]map[string]struct{}
+	}{m: map[string]map[string]struct{}{}}
+
+	// randRead fills the random bytes of the base macs, replaced in tests
+	randRead = rand.Read
 )
 
 func init() {
@@ -92,325 +321,2059 @@ func init() {
 type srl struct {
 	cfg     *types.NodeConfig
 	runtime runtime.ContainerRuntime
+	mgmt    *types.MgmtNet
+	// probe used by Ready() to detect that the node has booted
+	readyProbe string
+	// max wait time for node to boot
+	bootTimeout time.Duration
+	// when set, the node type is not set and is taken from the image by ResolveRuntime
+	imageTypePending bool
+	// breakout modes of the ports set with the breakout label, sorted by the port name
+	breakouts []interfaceBreakout
+	// initial interval of the readiness checks, backed off up to maxReadyPollInterval
+	readyPollInterval time.Duration
+	// checks of the cli ready probe set with the ready-check label, nil to use the checks of the image release
+	readyChecks []readyCheck
+	// when set, clab's default config is not applied to the node
+	skipDefaultConfig bool
+	// when set, the chassis base mac is derived from the node name instead of being random
+	deterministicMAC bool
+	// max time to download a startup-config provided as an http(s) URL
+	fetchTimeout time.Duration
+	// max time to save the node config with SaveConfig
+	saveTimeout time.Duration
+
+	// lab CA paths and lab name used to (re)generate the node certificate
+	labCADir  string
+	labCARoot string
+	labName   string
+	// base mac allocated to the node in baseMACs, released when the node is deleted
+	allocatedMAC string
+	// set when the node certificate was generated by clab during this deployment
+	certGenerated bool
+	// when unset, the management servers run without TLS and no certificate is generated
+	tls bool
+	// when set, clients must present a certificate signed by the lab CA
+	mtls bool
+	// when set, the deployment waits for the JSON-RPC https server to be ready
+	waitJSONRPC bool
+	// when set, the gRIBI and P4Runtime servers are enabled by the default config
+	gribi bool
+	p4rt  bool
+	// when unset, the gNMI and JSON-RPC servers are not enabled by the default config
+	gnmi    bool
+	jsonRPC bool
+	// ports of the gNMI and JSON-RPC servers
+	gnmiPort         int
+	jsonRPCHTTPPort  int
+	jsonRPCHTTPSPort int
+	// absolute path to a user provided topology file template, used instead of the embedded one
+	topologyTemplate string
+	// when set, the topology file generated by a previous deployment is kept if the node type and template are unchanged
+	preserveTopology bool
+	// when set, the config dir saved by a previous deployment is removed
+	resetConfig bool
+	// SR Linux version reported by the running node, cached by RunningVersion
+	version string
+	// CLI sessions idle timeout in seconds set by the default config
+	idleTimeout uint64
+	// license decoded from the license-b64 label
+	license []byte
+	// dir with the license pool and the license selection strategy
+	licenseDir    string
+	licenseSelect string
+	// when set, SR Linux is started without sudo as the runtime runs rootless
+	rootless bool
+	// when set, the node runs the SR Linux startup command, which depends on whether the runtime runs rootless
+	defaultCmd bool
+	// when set, the config dir is mounted read-only and the config can't be saved
+	configReadOnly bool
+	// commit mode of the config applied by containerlab, save or now
+	commitMode string
+	// number of the boot log lines added to the error when the node fails to boot
+	bootLogLines int
+	// private key type of the generated node certificate
+	tlsKeyType cert.KeyType
+	// when false, the container is created with SR Linux not started
+	autostart bool
+	// credentials of the superuser configured by containerlab, factory credentials are kept when unset
+	adminUser     string
+	adminPassword string
+	// absolute path to the file the admin password is read from, empty when the password is set with a label
+	adminPasswordFile string
+	// set once the default config with the admin credentials is applied to the running node
+	defaultConfigApplied bool
+	// network-instance the management servers are enabled in by the default config
+	mgmtNetworkInstance string
+	// when false, the default config leaves the LLDP settings of the image untouched
+	lldp bool
+	// paths to the external CA the node certificate is signed with, empty when the lab CA is used
+	caCert, caKey string
+	// when set, the CLI history file is bind mounted from the lab dir
+	persistCLIHistory bool
+	// container path the node's volume is mounted at, no volume is mounted when empty
+	volumePath string
+	// hook notified of the lifecycle stages, nil when no hook is registered
+	lifecycleHook nodes.LifecycleHook
+	// the ready stage is reported once, even though the readiness is checked multiple times
+	readyReported sync.Once
+}
+
+// srlCmd returns the command that starts SR Linux in the container.
+// on rootless runtimes the container root user maps to an unprivileged host user without sudo rights,
+// so SR Linux is started without sudo.
+func srlCmd(rootless bool) string {
+	// the addition touch is needed to support non docker runtimes
+	cmd := "bash -c 'touch /.dockerenv && /opt/srlinux/bin/sr_linux'"
+	if rootless {
+		return cmd
+	}
+	return "sudo " + cmd
 }
 
+// srlHoldCmd keeps the container running without starting SR Linux,
+// the /.dockerenv file is created for SR Linux to be started manually later
+const srlHoldCmd = "bash -c 'touch /.dockerenv && sleep infinity'"
+
 func (s *srl) Init(cfg *types.NodeConfig, opts ...nodes.NodeOption) error {
 	s.cfg = cfg
 	for _, o := range opts {
 		o(s)
 	}
 
+	// without an explicit type, the type is taken from the image once it is pulled
 	if s.cfg.NodeType == "" {
 		s.cfg.NodeType = srlDefaultType
+		s.imageTypePending = true
 	}
 
-	if _, found := srlTypes[s.cfg.NodeType]; !found {
+	nodeType := normalizeType(s.cfg.NodeType)
+	if _, found := srlTypes[nodeType]; !found {
 		keys := make([]string, 0, len(srlTypes))
 		for key := range srlTypes {
 			keys = append(keys, key)
 		}
-		return fmt.Errorf("wrong node type. '%s' doesn't exist. should be any of %s", s.cfg.NodeType, strings.Join(keys, ", "))
+		sort.Strings(keys)
+		return fmt.Errorf("wrong node type. '%s' doesn't exist, did you mean %s? should be any of %s",
+			s.cfg.NodeType, closestMatch(nodeType, keys), strings.Join(keys, ", "))
 	}
+	s.cfg.NodeType = nodeType
 
-	// the addition touch is needed to support non docker runtimes
-	s.cfg.Cmd = "sudo bash -c 'touch /.dockerenv && /opt/srlinux/bin/sr_linux'"
-
-	s.cfg.Env = utils.MergeStringMaps(srlEnv, s.cfg.Env)
-
-	// if user was not initialized to a value, use root
-	if s.cfg.User == "" {
-		s.cfg.User = "0:0"
+	if v, ok := s.cfg.Labels[breakoutLabel]; ok {
+		b, err := parseBreakouts(v)
+		if err != nil {
+			return fmt.Errorf("node %s: %v", s.cfg.ShortName, err)
+		}
+		s.breakouts = b
 	}
-	for k, v := range srlSysctl {
-		s.cfg.Sysctls[k] = v
+	if err := s.initUlimits(); err != nil {
+		return err
 	}
-
-	if s.cfg.License != "" {
-		// we mount a fixed path node.Labdir/license.key as the license referenced in topo file will be copied to that path
-		s.cfg.Binds = append(s.cfg.Binds, fmt.Sprint(filepath.Join(s.cfg.LabDir, "license.key"), ":/opt/srlinux/etc/license.key:ro"))
+	// the breakout modes and ulimits of a node with the type taken from the image are checked once the type is known
+	if !s.imageTypePending {
+		if err := s.checkBreakoutModes(); err != nil {
+			return err
+		}
+		s.checkUlimits()
 	}
 
-	// mount config directory
-	cfgPath := filepath.Join(s.cfg.LabDir, "config")
-	s.cfg.Binds = append(s.cfg.Binds, fmt.Sprint(cfgPath, ":/etc/opt/srlinux/:rw"))
-
-	// mount srlinux topology
-	topoPath := filepath.Join(s.cfg.LabDir, "topology.yml")
-	s.cfg.Binds = append(s.cfg.Binds, fmt.Sprint(topoPath, ":/tmp/topology.yml:ro"))
-
-	return nil
-}
-
-func (s *srl) Config() *types.NodeConfig { return s.cfg }
+	if err := s.cfg.ValidateResources(); err != nil {
+		return err
+	}
+	if s.cfg.DNS != nil && len(s.cfg.DNS.Servers) > maxDNSServers {
+		return fmt.Errorf("node %s: SR Linux supports up to %d dns servers, got %d", s.cfg.ShortName, maxDNSServers, len(s.cfg.DNS.Servers))
+	}
 
-func (s *srl) PreDeploy(configName, labCADir, labCARoot string) error {
-	utils.CreateDirectory(s.cfg.LabDir, 0777)
-	// retrieve node certificates
-	nodeCerts, err := cert.RetrieveNodeCertData(s.cfg, labCADir)
-	// if not available on disk, create cert in next step
-	if err != nil {
-		// create CERT
-		certTpl, err := template.New("node-cert").Parse(cert.NodeCSRTempl)
-		if err != nil {
-			log.Errorf("failed to parse Node CSR Template: %v", err)
-		}
-		certInput := cert.CertInput{
-			Name:     s.cfg.ShortName,
-			LongName: s.cfg.LongName,
-			Fqdn:     s.cfg.Fqdn,
-			Prefix:   configName,
-		}
-		nodeCerts, err = cert.GenerateCert(
-			path.Join(labCARoot, "root-ca.pem"),
-			path.Join(labCARoot, "root-ca-key.pem"),
-			certTpl,
-			certInput,
-			path.Join(labCADir, certInput.Name),
-		)
-		if err != nil {
-			log.Errorf("failed to generate certificates for node %s: %v", s.cfg.ShortName, err)
+	s.readyProbe = readyProbeCLI
+	if p, ok := s.cfg.Labels[readyProbeLabel]; ok {
+		switch p {
+		case readyProbeCLI, readyProbeGNMI:
+			s.readyProbe = p
+		default:
+			return fmt.Errorf("wrong ready probe %q set with %s label. should be any of %s, %s", p, readyProbeLabel, readyProbeCLI, readyProbeGNMI)
 		}
-		log.Debugf("%s CSR: %s", s.cfg.ShortName, string(nodeCerts.Csr))
-		log.Debugf("%s Cert: %s", s.cfg.ShortName, string(nodeCerts.Cert))
-		log.Debugf("%s Key: %s", s.cfg.ShortName, string(nodeCerts.Key))
 	}
-	s.cfg.TLSCert = string(nodeCerts.Cert)
-	s.cfg.TLSKey = string(nodeCerts.Key)
 
-	// Create appmgr subdir for agent specs and copy files, if needed
-	if s.cfg.Extras != nil && len(s.cfg.Extras.SRLAgents) != 0 {
-		agents := s.cfg.Extras.SRLAgents
-		appmgr := filepath.Join(s.cfg.LabDir, "config/appmgr/")
-		utils.CreateDirectory(appmgr, 0777)
-
-		for _, fullpath := range agents {
-			basename := filepath.Base(fullpath)
-			dst := filepath.Join(appmgr, basename)
-			if err := utils.CopyFile(fullpath, dst, 0644); err != nil {
-				return fmt.Errorf("agent copy src %s -> dst %s failed %v", fullpath, dst, err)
-			}
+	var err error
+	if s.skipDefaultConfig, err = labelBool(s.cfg.Labels, skipDefaultConfigLabel); err != nil {
+		return err
+	}
+	if s.deterministicMAC, err = labelBool(s.cfg.Labels, deterministicMACLabel); err != nil {
+		return err
+	}
+	if s.mtls, err = labelBool(s.cfg.Labels, mtlsLabel); err != nil {
+		return err
+	}
+	if s.waitJSONRPC, err = labelBool(s.cfg.Labels, waitJSONRPCLabel); err != nil {
+		return err
+	}
+	s.tls = true
+	if _, ok := s.cfg.Labels[tlsLabel]; ok {
+		if s.tls, err = labelBool(s.cfg.Labels, tlsLabel); err != nil {
+			return err
+		}
+	}
+	if !s.tls && s.mtls {
+		return fmt.Errorf("node %s: %s label requires TLS, which is disabled with %s label", s.cfg.ShortName, mtlsLabel, tlsLabel)
+	}
+	if s.gribi, err = labelBool(s.cfg.Labels, gribiLabel); err != nil {
+		return err
+	}
+	if s.p4rt, err = labelBool(s.cfg.Labels, p4rtLabel); err != nil {
+		return err
+	}
+	s.gnmi = true
+	if _, ok := s.cfg.Labels[gnmiLabel]; ok {
+		if s.gnmi, err = labelBool(s.cfg.Labels, gnmiLabel); err != nil {
+			return err
+		}
+	}
+	s.jsonRPC = true
+	if _, ok := s.cfg.Labels[jsonRPCLabel]; ok {
+		if s.jsonRPC, err = labelBool(s.cfg.Labels, jsonRPCLabel); err != nil {
+			return err
 		}
 	}
+	if !s.gnmi && s.readyProbe == readyProbeGNMI {
+		return fmt.Errorf("node %s: %s probe set with %s label requires the gNMI server, which is disabled with %s label", s.cfg.ShortName, readyProbeGNMI, readyProbeLabel, gnmiLabel)
+	}
+	if !s.jsonRPC && s.waitJSONRPC {
+		return fmt.Errorf("node %s: %s label requires the JSON-RPC server, which is disabled with %s label", s.cfg.ShortName, waitJSONRPCLabel, jsonRPCLabel)
+	}
+	if err := s.initPorts(); err != nil {
+		return err
+	}
+	s.exposeMgmtPorts()
+	if s.resetConfig, err = labelBool(s.cfg.Labels, resetConfigLabel); err != nil {
+		return err
+	}
+	if s.configReadOnly, err = labelBool(s.cfg.Labels, configReadOnlyLabel); err != nil {
+		return err
+	}
 
-	return createSRLFiles(s.cfg)
-}
+	// the config can't be saved to a read-only config dir
+	s.commitMode = commitModeSave
+	if s.configReadOnly {
+		s.commitMode = commitModeNow
+	}
+	if v, ok := s.cfg.Labels[commitModeLabel]; ok {
+		switch {
+		case v != commitModeSave && v != commitModeNow:
+			return fmt.Errorf("wrong commit mode %q set with %s label. should be any of %s, %s", v, commitModeLabel, commitModeSave, commitModeNow)
+		case v == commitModeSave && s.configReadOnly:
+			return fmt.Errorf("node %s: commit mode %s can't be used with a read-only config set with %s label", s.cfg.ShortName, commitModeSave, configReadOnlyLabel)
+		}
+		s.commitMode = v
+	}
 
-func (s *srl) Deploy(ctx context.Context) error {
-	_, err := s.runtime.CreateContainer(ctx, s.cfg)
-	return err
-}
+	if err := s.initAdminCredentials(); err != nil {
+		return err
+	}
 
-func (s *srl) PostDeploy(ctx context.Context, _ map[string]nodes.Node) error {
-	// only perform postdeploy additional config provisioning if there is not startup nor existing config
-	if s.cfg.StartupConfig != "" || utils.FileExists(filepath.Join(s.cfg.LabDir, "config", "config.json")) {
-		return nil
+	switch s.cfg.StartupConfigMode {
+	case "", startupConfigModeReplace:
+	case startupConfigModeMerge:
+		if s.cfg.StartupConfig != "" && !isCLIConfig(s.cfg.StartupConfig) {
+			return fmt.Errorf("startup-config %s of node %s must be a CLI snippet with .cli or .cli.gz extension when startup-config-mode is %s",
+				s.cfg.StartupConfig, s.cfg.ShortName, startupConfigModeMerge)
+		}
+	default:
+		return fmt.Errorf("wrong startup-config-mode %q. should be any of %s, %s", s.cfg.StartupConfigMode, startupConfigModeReplace, startupConfigModeMerge)
 	}
 
-	log.Infof("Running postdeploy actions for Nokia SR Linux '%s' node", s.cfg.ShortName)
+	s.bootTimeout = readyTimeout
+	if s.cfg.BootTimeout != "" {
+		d, err := time.ParseDuration(s.cfg.BootTimeout)
+		if err != nil || d <= 0 {
+			log.Warnf("node %s: invalid boot-timeout value %q, using the default of %s", s.cfg.ShortName, s.cfg.BootTimeout, readyTimeout)
+		} else {
+			s.bootTimeout = d
+		}
+	}
 
-	return s.addDefaultConfig(ctx)
-}
+	s.readyPollInterval = retryTimer
+	if v, ok := s.cfg.Labels[readyPollIntervalLabel]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("wrong value %q set with %s label, should be a positive duration", v, readyPollIntervalLabel)
+		}
+		s.readyPollInterval = d
+	}
 
-func (s *srl) GetImages() map[string]string {
-	return map[string]string{
-		nodes.ImageKey: s.cfg.Image,
+	if v, ok := s.cfg.Labels[readyCheckLabel]; ok {
+		if s.readyChecks, err = parseReadyChecks(v); err != nil {
+			return fmt.Errorf("wrong value %q set with %s label: %v", v, readyCheckLabel, err)
+		}
 	}
-}
 
-func (*srl) WithMgmtNet(*types.MgmtNet)               {}
-func (s *srl) WithRuntime(r runtime.ContainerRuntime) { s.runtime = r }
-func (s *srl) GetRuntime() runtime.ContainerRuntime   { return s.runtime }
+	if p, ok := s.cfg.Labels[topologyTemplateLabel]; ok && p != "" {
+		p, err = filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		if !utils.FileExists(p) {
+			return fmt.Errorf("node %s: topology template %s set with %s label does not exist", s.cfg.ShortName, p, topologyTemplateLabel)
+		}
+		s.topologyTemplate = p
+	}
 
-func (s *srl) Delete(ctx context.Context) error {
-	return s.runtime.DeleteContainer(ctx, s.Config().LongName)
-}
+	if v, ok := s.cfg.Labels[licenseB64Label]; ok && v != "" {
+		if s.license, err = base64.StdEncoding.DecodeString(strings.TrimSpace(v)); err != nil {
+			return fmt.Errorf("node %s: failed to decode license set with %s label: %v", s.cfg.ShortName, licenseB64Label, err)
+		}
+	}
 
-func (s *srl) SaveConfig(ctx context.Context) error {
-	stdout, stderr, err := s.runtime.Exec(ctx, s.cfg.LongName, saveCmd)
-	if err != nil {
-		return fmt.Errorf("%s: failed to execute cmd: %v", s.cfg.ShortName, err)
+	if err := s.initLicenseDir(); err != nil {
+		return err
 	}
 
-	if len(stderr) > 0 {
-		return fmt.Errorf("%s errors: %s", s.cfg.ShortName, string(stderr))
+	for _, d := range []struct{ field, digest string }{
+		{"startup-config-sha256", s.cfg.StartupConfigSHA256},
+		{"license-sha256", s.cfg.LicenseSHA256},
+	} {
+		if d.digest != "" && !sha256Re.MatchString(d.digest) {
+			return fmt.Errorf("node %s: wrong %s value %q, should be a hex encoded SHA-256 digest", s.cfg.ShortName, d.field, d.digest)
+		}
+	}
+	if s.cfg.LicenseSHA256 != "" && s.cfg.License == "" && s.licenseDir == "" && s.license == nil {
+		log.Warnf("node %s: license-sha256 is set without a license, the digest is not verified", s.cfg.ShortName)
 	}
 
-	log.Infof("saved SR Linux configuration from %s node. Output:\n%s", s.cfg.ShortName, string(stdout))
+	s.idleTimeout = defaultIdleTimeout
+	if v, ok := s.cfg.Labels[idleTimeoutLabel]; ok {
+		if s.idleTimeout, err = strconv.ParseUint(v, 10, 32); err != nil {
+			return fmt.Errorf("wrong value %q set with %s label, should be a non-negative number of seconds", v, idleTimeoutLabel)
+		}
+	}
 
-	return nil
-}
+	s.mgmtNetworkInstance = defaultMgmtNetworkInstance
+	if v, ok := s.cfg.Labels[mgmtNetworkInstanceLabel]; ok {
+		if !networkInstanceRe.MatchString(v) {
+			return fmt.Errorf("node %s: wrong network-instance name %q set with %s label", s.cfg.ShortName, v, mgmtNetworkInstanceLabel)
+		}
+		s.mgmtNetworkInstance = v
+	}
 
-// Ready returns when the node boot sequence reached the stage when it is ready to accept config commands
-// returns an error if not ready by the expiry of the timer readyTimeout.
-func (s *srl) Ready(ctx context.Context) error {
-	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
-	defer cancel()
-	var stdout, stderr []byte
-	var err error
+	s.caCert, s.caKey = s.cfg.Labels[caCertLabel], s.cfg.Labels[caKeyLabel]
+	if (s.caCert == "") != (s.caKey == "") {
+		return fmt.Errorf("node %s: %s and %s labels must be set together", s.cfg.ShortName, caCertLabel, caKeyLabel)
+	}
 
-	log.Debugf("Waiting for SR Linux node %q to boot...", s.cfg.ShortName)
-	for {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("timed out waiting for SR Linux node %s to boot: %v", s.cfg.ShortName, err)
-		default:
-			// two commands are checked, first if the mgmt_server is running
-			stdout, stderr, err = s.GetRuntime().Exec(ctx, s.cfg.LongName, mgmtServerRdyCmd)
-			if err != nil {
-				time.Sleep(retryTimer)
-				continue
-			}
-			if len(stderr) != 0 {
-				log.Debugf("error during checking SR Linux boot status: %s", string(stderr))
-				time.Sleep(retryTimer)
-				continue
-			}
-			if !bytes.Contains(stdout, []byte("running")) {
-				time.Sleep(retryTimer)
-				continue
-			}
+	s.lldp = true
+	if _, ok := s.cfg.Labels[lldpLabel]; ok {
+		if s.lldp, err = labelBool(s.cfg.Labels, lldpLabel); err != nil {
+			return err
+		}
+	}
+	if s.persistCLIHistory, err = labelBool(s.cfg.Labels, persistCLIHistoryLabel); err != nil {
+		return err
+	}
+	if err := s.initVolume(); err != nil {
+		return err
+	}
+	if err := s.assignULAMgmtAddr(); err != nil {
+		return err
+	}
 
-			// and then if the initial commit completes
-			stdout, stderr, err = s.GetRuntime().Exec(ctx, s.cfg.LongName, commitCompleteCmd)
-			if err != nil {
-				time.Sleep(retryTimer)
-				continue
+	s.tlsKeyType = cert.KeyTypes[cert.DefaultKeyType]
+	if v, ok := s.cfg.Labels[tlsKeyTypeLabel]; ok {
+		kt, ok := cert.KeyTypes[v]
+		if !ok {
+			keyTypes := make([]string, 0, len(cert.KeyTypes))
+			for k := range cert.KeyTypes {
+				keyTypes = append(keyTypes, k)
 			}
+			sort.Strings(keyTypes)
+			return fmt.Errorf("wrong TLS key type %q set with %s label. should be any of %s", v, tlsKeyTypeLabel, strings.Join(keyTypes, ", "))
+		}
+		s.tlsKeyType = kt
+	}
 
-			if len(stderr) != 0 {
-				log.Debugf("error during checking SR Linux boot status: %s", string(stderr))
-				time.Sleep(retryTimer)
-				continue
-			}
+	s.bootLogLines = defaultBootLogLines
+	if v, ok := s.cfg.Labels[bootLogLinesLabel]; ok {
+		n, err := strconv.ParseUint(v, 10, 16)
+		if err != nil {
+			return fmt.Errorf("wrong value %q set with %s label, should be a non-negative number of lines", v, bootLogLinesLabel)
+		}
+		s.bootLogLines = int(n)
+	}
 
-			if !bytes.Contains(stdout, []byte("complete")) {
-				log.Debugf("node %s not yet ready", s.cfg.ShortName)
-				time.Sleep(retryTimer)
-				continue
-			}
-			log.Debugf("Node %s booted", s.cfg.ShortName)
-			return nil
+	s.fetchTimeout = fetchTimeout
+	if v, ok := s.cfg.Labels[fetchTimeoutLabel]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("wrong value %q set with %s label, should be a positive duration", v, fetchTimeoutLabel)
 		}
+		s.fetchTimeout = d
 	}
-}
 
-//
+	s.saveTimeout = saveTimeout
+	if v, ok := s.cfg.Labels[saveTimeoutLabel]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("wrong value %q set with %s label, should be a positive duration", v, saveTimeoutLabel)
+		}
+		s.saveTimeout = d
+	}
 
-func createSRLFiles(nodeCfg *types.NodeConfig) error {
-	log.Debugf("Creating directory structure for SRL container: %s", nodeCfg.ShortName)
-	var src string
-	var dst string
+	// without the label, the runtime is checked by ResolveRuntime
+	if _, ok := s.cfg.Labels[rootlessLabel]; ok {
+		if s.rootless, err = labelBool(s.cfg.Labels, rootlessLabel); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Cmd != "" && strings.TrimSpace(s.cfg.Cmd) == "" {
+		return fmt.Errorf("node %s: cmd must not be blank", s.cfg.ShortName)
+	}
 
-	if nodeCfg.License != "" {
-		// copy license file to node specific directory in lab
-		src = nodeCfg.License
-		dst = filepath.Join(nodeCfg.LabDir, "license.key")
-		if err := utils.CopyFile(src, dst, 0644); err != nil {
-			return fmt.Errorf("CopyFile src %s -> dst %s failed %v", src, dst, err)
+	s.autostart = true
+	if _, ok := s.cfg.Labels[autostartLabel]; ok {
+		if s.autostart, err = labelBool(s.cfg.Labels, autostartLabel); err != nil {
+			return err
 		}
-		log.Debugf("CopyFile src %s -> dst %s succeeded", src, dst)
+	}
+	// the cmd set in the topology takes precedence over the default startup command
+	switch {
+	case !s.autostart:
+		s.cfg.Cmd = srlHoldCmd
+	case s.cfg.Cmd != "":
+		log.Warnf("node %s: cmd %q overrides the SR Linux startup command, the readiness checks assume sr_linux is the main process of the container", s.cfg.ShortName, s.cfg.Cmd)
+	default:
+		s.defaultCmd = true
+		s.cfg.Cmd = srlCmd(s.rootless)
 	}
 
-	// generate SRL topology file
-	err := generateSRLTopologyFile(nodeCfg.NodeType, nodeCfg.LabDir, nodeCfg.Index)
+	// the env vars SR Linux relies on can't be overridden by the user
+	s.cfg.Env = utils.MergeStringMaps(s.cfg.Env, srlEnv)
+
+	// if user was not initialized to a value, use root
+	if s.cfg.User == "" {
+		s.cfg.User = "0:0"
+	}
+	// user defined sysctls take precedence over the defaults, the skipped defaults are not applied
+	skip, err := skippedSysctls(s.cfg.Labels)
 	if err != nil {
-		return err
+		return fmt.Errorf("node %s: %v", s.cfg.ShortName, err)
+	}
+	for k, v := range srlSysctl {
+		if _, ok := skip[k]; ok {
+			continue
+		}
+		if _, ok := s.cfg.Sysctls[k]; !ok {
+			s.cfg.Sysctls[k] = v
+		}
 	}
 
-	utils.CreateDirectory(path.Join(nodeCfg.LabDir, "config"), 0777)
+	// the binds defined in the topology file are appended after the clab-managed binds
+	userBinds := s.cfg.Binds
+	var binds []string
 
-	// generate a startup config file
-	// if the node has a `startup-config:` statement, the file specified in that section
-	// will be used as a template in GenerateConfig()
-	if nodeCfg.StartupConfig != "" {
-		dst = filepath.Join(nodeCfg.LabDir, "config", "config.json")
+	if s.cfg.License != "" || s.licenseDir != "" || s.license != nil {
+		// we mount a fixed path node.Labdir/license.key as the license referenced in topo file will be copied to that path
+		binds = append(binds, fmt.Sprint(filepath.Join(s.cfg.LabDir, "license.key"), ":/opt/srlinux/etc/license.key:ro"))
+	}
 
-		log.Debugf("Reading startup-config %s", nodeCfg.StartupConfig)
+	// mount config directory
+	cfgPath := filepath.Join(s.cfg.LabDir, "config")
+	cfgMode := "rw"
+	if s.configReadOnly {
+		cfgMode = "ro"
+	}
+	binds = append(binds, fmt.Sprint(cfgPath, ":", srlConfigDir, ":", cfgMode))
 
-		c, err := os.ReadFile(nodeCfg.StartupConfig)
-		if err != nil {
-			return err
-		}
+	// mount srlinux topology
+	topoPath := filepath.Join(s.cfg.LabDir, "topology.yml")
+	binds = append(binds, fmt.Sprint(topoPath, ":/tmp/topology.yml:ro"))
 
-		cfgTemplate := string(c)
+	// mount post-deploy scripts copied to the lab dir
+	if len(s.postDeployScripts()) != 0 {
+		scriptsPath := filepath.Join(s.cfg.LabDir, postDeployScriptsDir)
+		binds = append(binds, fmt.Sprint(scriptsPath, ":", postDeployScriptsMountDir, ":ro"))
+	}
 
-		err = nodeCfg.GenerateConfig(dst, cfgTemplate)
-		if err != nil {
-			log.Errorf("node=%s, failed to generate config: %v", nodeCfg.ShortName, err)
-		}
+	// mount CLI history kept between deployments
+	if s.persistCLIHistory {
+		binds = append(binds, fmt.Sprint(filepath.Join(s.cfg.LabDir, cliHistoryFile), ":", cliHistoryMountPath, ":rw"))
 	}
 
-	return err
-}
+	// mount the named volume of the node
+	if s.volumePath != "" {
+		binds = append(binds, fmt.Sprint(s.volumeName(), ":", s.volumePath, ":rw"))
+	}
 
-type mac struct {
-	MAC string
+	if err := validateUserBinds(userBinds, binds, filepath.Dir(s.cfg.LabDir)); err != nil {
+		return fmt.Errorf("node %s: %v", s.cfg.ShortName, err)
+	}
+	s.cfg.Binds = append(binds, userBinds...)
+
+	nodes.NotifyStage(s.lifecycleHook, s.cfg.ShortName, nodes.StageInit)
+	return nil
 }
 
-func generateSRLTopologyFile(nodeType, labDir string, _ int) error {
-	dst := filepath.Join(labDir, "topology.yml")
+func (s *srl) Config() *types.NodeConfig { return s.cfg }
 
-	tpl, err := template.ParseFS(topologies, "topology/"+srlTypes[nodeType])
-	if err != nil {
-		return errors.Wrap(err, "failed to get srl topology file")
+// validateUserBinds checks that the sources of the user-defined binds exist and that the binds
+// don't override the targets of the clab-managed binds.
+// sources in the lab dir are not checked as they may be created by containerlab later, e.g. ansible-inventory.yml.
+func validateUserBinds(userBinds, managedBinds []string, labDir string) error {
+	managed := make(map[string]struct{}, len(managedBinds))
+	for _, b := range managedBinds {
+		managed[filepath.Clean(strings.Split(b, ":")[1])] = struct{}{}
 	}
 
-	// generate random bytes to use in the 2-3rd bytes of a base mac
-	// this ensures that different srl nodes will have different macs for their ports
-	buf := make([]byte, 2)
-	_, err = rand.Read(buf)
-	if err != nil {
-		return err
+	for _, b := range userBinds {
+		parts := strings.Split(b, ":")
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("bind %q must be in the host-path:container-path[:options] format", b)
+		}
+		if _, ok := managed[filepath.Clean(parts[1])]; ok {
+			return fmt.Errorf("bind %q overrides the %s mount managed by containerlab", b, parts[1])
+		}
+		if labDir != "" && strings.HasPrefix(filepath.Clean(parts[0]), filepath.Clean(labDir)+string(filepath.Separator)) {
+			continue
+		}
+		if _, err := os.Stat(parts[0]); err != nil {
+			return fmt.Errorf("bind %q source: %v", b, err)
+		}
 	}
-	m := fmt.Sprintf("02:%02x:%02x:00:00:00", buf[0], buf[1])
+	return nil
+}
 
-	mac := mac{
-		MAC: m,
+// ResolveRuntime sets the settings of the node that depend on the container runtime:
+// whether the runtime runs rootless, unless set with the rootless label, and the type of a node without an explicit type from the image.
+func (s *srl) ResolveRuntime(ctx context.Context) error {
+	if _, ok := s.cfg.Labels[rootlessLabel]; !ok {
+		if rc, ok := s.runtime.(runtime.RootlessChecker); ok {
+			s.rootless = rc.IsRootless(ctx)
+		}
 	}
-	log.Debug(mac, dst)
-	f, err := os.Create(dst)
-	if err != nil {
-		return err
+	if s.defaultCmd {
+		s.cfg.Cmd = srlCmd(s.rootless)
 	}
-	defer f.Close()
-	return tpl.Execute(f, mac)
+	return s.resolvePendingImageType(ctx)
 }
 
-// addDefaultConfig adds srl default configuration such as tls certs and gnmi/json-rpc
-func (s *srl) addDefaultConfig(ctx context.Context) error {
-	// start waiting for initial commit and mgmt server ready
-	if err := s.Ready(ctx); err != nil {
-		return err
+func (s *srl) PreDeploy(configName, labCADir, labCARoot string) error {
+	s.labName = configName
+	utils.CreateDirectory(s.cfg.LabDir, 0777)
+	// certificates are normally generated for all nodes concurrently before the nodes are deployed
+	if s.cfg.TLSCert == "" {
+		if err := s.GenerateCert(configName, labCADir, labCARoot); err != nil {
+			log.Errorf("failed to generate certificates for node %s: %v", s.cfg.ShortName, err)
+		}
 	}
 
-	buf := new(bytes.Buffer)
-	err := srlCfgTpl.Execute(buf, s.cfg)
-	if err != nil {
-		return err
+	if s.mtls {
+		if err := s.loadTLSAnchor(); err != nil {
+			return err
+		}
 	}
 
-	log.Debugf("Node %q additional config:\n%s", s.cfg.ShortName, buf.String())
-	_, _, err = s.runtime.Exec(ctx, s.cfg.LongName, []string{
-		"bash",
-		"-c",
-		fmt.Sprintf("echo '%s' > /tmp/clab-config", buf.String()),
+	// the config dir is removed before any file is copied into it
+	if s.resetConfig {
+		if err := s.resetConfigDir(); err != nil {
+			return err
+		}
+	}
+
+	// Create appmgr subdir for agent specs and copy files, if needed
+	if s.cfg.Extras != nil && len(s.cfg.Extras.SRLAgents) != 0 {
+		if err := s.copyAgents(s.cfg.Extras.SRLAgents); err != nil {
+			return err
+		}
+	}
+
+	if scripts := s.postDeployScripts(); len(scripts) != 0 {
+		if err := s.copyPostDeployScripts(scripts); err != nil {
+			return err
+		}
+	}
+
+	if s.persistCLIHistory {
+		if err := s.createCLIHistory(); err != nil {
+			return err
+		}
+	}
+
+	// the interface MTUs are validated before the node is created, as they are configured only after it boots
+	if _, err := s.interfaceMTUs(); err != nil {
+		return err
+	}
+
+	if err := s.createSRLFiles(); err != nil {
+		return err
+	}
+	nodes.NotifyStage(s.lifecycleHook, s.cfg.ShortName, nodes.StagePreDeploy)
+	return nil
+}
+
+// createCLIHistory creates the CLI history file in the lab dir unless it is kept from a previous deployment.
+// the file must exist before the container is created, otherwise the runtime creates a directory in its place.
+// it is owned by the container user, so that sr_cli can write the history when the node runs as a non-root user.
+func (s *srl) createCLIHistory() error {
+	p := filepath.Join(s.cfg.LabDir, cliHistoryFile)
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("node %s: failed to create CLI history file: %v", s.cfg.ShortName, err)
+	}
+	f.Close()
+
+	s.chownToUser(p)
+	return nil
+}
+
+// chownToUser makes the container user the owner of the host file p, so that the file is accessible
+// when the node runs as a non-root user. the ownership is left as is on rootless runtimes,
+// where the container users are mapped to host user ids that are not known here, and for users set by name.
+// failures are logged only, as the file can still be accessible.
+func (s *srl) chownToUser(p string) {
+	if s.rootless {
+		return
+	}
+	uid, gid, ok := numericUser(s.cfg.User)
+	if !ok {
+		log.Debugf("node %s: user %q is not numeric, %s ownership is left as is", s.cfg.ShortName, s.cfg.User, p)
+		return
+	}
+	if err := os.Chown(p, uid, gid); err != nil {
+		log.Warnf("node %s: failed to set %s ownership to %d:%d: %v", s.cfg.ShortName, p, uid, gid, err)
+	}
+}
+
+// numericUser parses the uid[:gid] container user, the gid defaults to the uid.
+// returns false if the user is set by name, as the names can't be resolved on the host.
+func numericUser(user string) (int, int, bool) {
+	parts := strings.SplitN(user, ":", 2)
+	uid, err := strconv.ParseUint(parts[0], 10, 31)
+	if err != nil {
+		return 0, 0, false
+	}
+	gid := uid
+	if len(parts) == 2 {
+		if gid, err = strconv.ParseUint(parts[1], 10, 31); err != nil {
+			return 0, 0, false
+		}
+	}
+	return int(uid), int(gid), true
+}
+
+// resetConfigDir removes the node config dir left by a previous deployment,
+// so that the node boots with a freshly generated config
+func (s *srl) resetConfigDir() error {
+	cfgDir := filepath.Join(s.cfg.LabDir, "config")
+	if _, err := os.Stat(cfgDir); os.IsNotExist(err) {
+		return nil
+	}
+	log.Infof("Removing config directory %s of node %s", cfgDir, s.cfg.ShortName)
+	if err := os.RemoveAll(cfgDir); err != nil {
+		return fmt.Errorf("node %s: failed to remove config directory %s: %v", s.cfg.ShortName, cfgDir, err)
+	}
+	return nil
+}
+
+// copyAgents copies the agents spec files to the appmgr dir of the node config.
+// agent binary and config files are copied to the appmgr/<agent> dir, where agent is the spec file name without extension.
+func (s *srl) copyAgents(agents []types.SRLAgent) error {
+	// validate all the files first so that a typo doesn't leave a partially copied set of agents
+	for _, a := range agents {
+		for _, f := range []string{a.Spec, a.Binary, a.Config} {
+			if f != "" && !utils.IsHTTPURL(f) && !utils.FileExists(f) {
+				return fmt.Errorf("node %s: agent file %s does not exist", s.cfg.ShortName, f)
+			}
+		}
+	}
+
+	appmgr := filepath.Join(s.cfg.LabDir, "config/appmgr/")
+	utils.CreateDirectory(appmgr, 0777)
+
+	for _, a := range agents {
+		dst := filepath.Join(appmgr, filepath.Base(a.Spec))
+		if err := utils.CopyFile(a.Spec, dst, 0644); err != nil {
+			return fmt.Errorf("agent copy src %s -> dst %s failed %v", a.Spec, dst, err)
+		}
+
+		if a.Binary == "" && a.Config == "" {
+			continue
+		}
+		agentDir := filepath.Join(appmgr, strings.TrimSuffix(filepath.Base(a.Spec), filepath.Ext(a.Spec)))
+		utils.CreateDirectory(agentDir, 0777)
+
+		files := []struct {
+			src  string
+			mode os.FileMode
+		}{
+			{a.Binary, 0755},
+			{a.Config, 0644},
+		}
+		for _, f := range files {
+			if f.src == "" {
+				continue
+			}
+			dst := filepath.Join(agentDir, filepath.Base(f.src))
+			if err := utils.CopyFile(f.src, dst, f.mode); err != nil {
+				return fmt.Errorf("agent copy src %s -> dst %s failed %v", f.src, dst, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *srl) Deploy(ctx context.Context) error {
+	if err := s.createVolume(ctx); err != nil {
+		return err
+	}
+	_, err := s.runtime.CreateContainer(ctx, s.cfg)
+	if err != nil {
+		return err
+	}
+	nodes.NotifyStage(s.lifecycleHook, s.cfg.ShortName, nodes.StageDeploy)
+	return nil
+}
+
+func (s *srl) PostDeploy(ctx context.Context, _ map[string]nodes.Node) error {
+	if !s.autostart {
+		log.Infof("SR Linux is not started on node %s as %s label is set to false, skipping postdeploy actions", s.cfg.ShortName, autostartLabel)
+		nodes.NotifyStage(s.lifecycleHook, s.cfg.ShortName, nodes.StagePostDeploy)
+		return nil
+	}
+
+	if err := s.provisionConfig(ctx); err != nil {
+		return err
+	}
+
+	if err := s.runPostDeployScripts(ctx); err != nil {
+		return err
+	}
+
+	// the JSON-RPC server is enabled by the default config, so it can only be waited for once the config is applied
+	if s.waitJSONRPC {
+		ctx, cancel := context.WithTimeout(ctx, s.bootTimeout)
+		defer cancel()
+		if err := s.jsonRPCReady(ctx); err != nil {
+			return err
+		}
+	}
+
+	nodes.NotifyStage(s.lifecycleHook, s.cfg.ShortName, nodes.StagePostDeploy)
+	return nil
+}
+
+// postDeployScripts returns the post-deploy scripts defined for the node
+func (s *srl) postDeployScripts() []types.SRLPostDeployScript {
+	if s.cfg.Extras == nil {
+		return nil
+	}
+	return s.cfg.Extras.SRLPostDeployScripts
+}
+
+// postDeployScriptName returns the name of the i-th post-deploy script copied to the lab dir,
+// the index prefix keeps scripts with the same file name apart
+func postDeployScriptName(i int, path string) string {
+	return fmt.Sprintf("%02d-%s", i, filepath.Base(path))
+}
+
+// copyPostDeployScripts copies the post-deploy scripts to the lab dir that is mounted to the container.
+// scripts left by a previous deployment are removed.
+func (s *srl) copyPostDeployScripts(scripts []types.SRLPostDeployScript) error {
+	for _, ps := range scripts {
+		if !utils.FileExists(ps.Path) {
+			return fmt.Errorf("node %s: post-deploy script %s does not exist", s.cfg.ShortName, ps.Path)
+		}
+	}
+
+	dir := filepath.Join(s.cfg.LabDir, postDeployScriptsDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	utils.CreateDirectory(dir, 0755)
+
+	for i, ps := range scripts {
+		dst := filepath.Join(dir, postDeployScriptName(i, ps.Path))
+		if err := utils.CopyFile(ps.Path, dst, 0755); err != nil {
+			return fmt.Errorf("post-deploy script copy src %s -> dst %s failed %v", ps.Path, dst, err)
+		}
+	}
+	return nil
+}
+
+// runPostDeployScripts executes the post-deploy scripts with bash inside the node in the order they are defined.
+// a script exiting with a non-zero code fails the post-deploy stage, unless its errors are ignored.
+func (s *srl) runPostDeployScripts(ctx context.Context) error {
+	scripts := s.postDeployScripts()
+	if len(scripts) == 0 {
+		return nil
+	}
+
+	// the default config is not applied with a startup config, so the node might not be booted yet
+	if err := s.Ready(ctx); err != nil {
+		return err
+	}
+
+	for i, ps := range scripts {
+		log.Infof("Running post-deploy script %s on Nokia SR Linux '%s' node", ps.Path, s.cfg.ShortName)
+		res, err := s.runtime.ExecWithResult(ctx, s.cfg.LongName, []string{
+			"bash",
+			path.Join(postDeployScriptsMountDir, postDeployScriptName(i, ps.Path)),
+		})
+		if err != nil {
+			return fmt.Errorf("node %s: failed to execute post-deploy script %s: %v", s.cfg.ShortName, ps.Path, err)
+		}
+
+		if res.Stdout != "" {
+			log.Infof("node %s: post-deploy script %s stdout:\n%s", s.cfg.ShortName, ps.Path, res.Stdout)
+		}
+		if res.Stderr != "" {
+			log.Infof("node %s: post-deploy script %s stderr:\n%s", s.cfg.ShortName, ps.Path, res.Stderr)
+		}
+		if res.ExitCode == 0 {
+			continue
+		}
+		if ps.IgnoreErrors {
+			log.Warnf("node %s: post-deploy script %s exited with code %d, ignoring", s.cfg.ShortName, ps.Path, res.ExitCode)
+			continue
+		}
+		return fmt.Errorf("node %s: post-deploy script %s exited with code %d", s.cfg.ShortName, ps.Path, res.ExitCode)
+	}
+	return nil
+}
+
+// provisionConfig applies the default config and the startup config snippet in merge mode to the node
+func (s *srl) provisionConfig(ctx context.Context) error {
+	// startup config in merge mode is applied on top of the default config
+	merge := s.cfg.StartupConfig != "" && s.cfg.StartupConfigMode == startupConfigModeMerge
+
+	// only perform postdeploy additional config provisioning if there is not startup nor existing config
+	if (s.cfg.StartupConfig != "" && !merge) || utils.FileExists(filepath.Join(s.cfg.LabDir, "config", "config.json")) {
+		// the config saved by a previous deployment holds the default config
+		s.defaultConfigApplied = !s.skipDefaultConfig && (s.cfg.StartupConfig == "" || merge)
+		return nil
+	}
+
+	if s.skipDefaultConfig && !merge {
+		log.Infof("Default config provisioning is disabled for Nokia SR Linux '%s' node", s.cfg.ShortName)
+		return nil
+	}
+
+	log.Infof("Running postdeploy actions for Nokia SR Linux '%s' node", s.cfg.ShortName)
+
+	if s.skipDefaultConfig {
+		if err := s.Ready(ctx); err != nil {
+			return err
+		}
+	} else if err := s.addDefaultConfig(ctx); err != nil {
+		return err
+	}
+
+	if merge {
+		return s.mergeStartupConfig(ctx)
+	}
+
+	return nil
+}
+
+// InterfaceMap maps the container interface names to SR Linux interface names,
+// e.g. e1-1 to ethernet-1/1 and breakout interface e1-3-1 to ethernet-1/3/1.
+// interfaces that don't follow the eX-Y naming are skipped.
+func (*srl) InterfaceMap(ifaces []string) map[string]string {
+	m := make(map[string]string, len(ifaces))
+	for _, i := range ifaces {
+		if n := srlInterfaceName(i); n != "" {
+			m[i] = n
+		}
+	}
+	return m
+}
+
+// srlInterfaceName returns the SR Linux name of a container interface named eX-Y or eX-Y-Z,
+// or empty string if the name doesn't follow that format
+func srlInterfaceName(iface string) string {
+	if !strings.HasPrefix(iface, "e") {
+		return ""
+	}
+	parts := strings.Split(strings.TrimPrefix(iface, "e"), "-")
+	if len(parts) < 2 || len(parts) > 3 {
+		return ""
+	}
+	for _, p := range parts {
+		if _, err := strconv.ParseUint(p, 10, 16); err != nil {
+			return ""
+		}
+	}
+	return "ethernet-" + strings.Join(parts, "/")
+}
+
+// skippedSysctls returns the set of the default sysctls listed in the skip-sysctls label.
+// a key that is not one of the default sysctls is reported as an error, so that a typo doesn't go unnoticed.
+func skippedSysctls(labels map[string]string) (map[string]struct{}, error) {
+	v, ok := labels[skipSysctlsLabel]
+	if !ok {
+		return nil, nil
+	}
+	skip := make(map[string]struct{})
+	for _, k := range strings.Split(v, ",") {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		if _, ok := srlSysctl[k]; !ok {
+			return nil, fmt.Errorf("%s label: %q is not one of the sysctls set by containerlab", skipSysctlsLabel, k)
+		}
+		skip[k] = struct{}{}
+	}
+	return skip, nil
+}
+
+// interfaceMTUs returns the MTUs of the node's interfaces connected to the links with the MTU set, sorted by the interface name.
+// an error is returned if an MTU is out of the range supported by SR Linux.
+func (s *srl) interfaceMTUs() ([]interfaceMTU, error) {
+	var mtus []interfaceMTU
+	for _, e := range s.cfg.Endpoints {
+		if e.MTU == 0 {
+			continue
+		}
+		if e.MTU < minInterfaceMTU || e.MTU > maxInterfaceMTU {
+			return nil, fmt.Errorf("node %s: interface %s mtu %d must be in the range %d-%d", s.cfg.ShortName, e.EndpointName, e.MTU, minInterfaceMTU, maxInterfaceMTU)
+		}
+		name := srlInterfaceName(e.EndpointName)
+		if name == "" {
+			log.Debugf("node %s: interface %s is not an SR Linux interface, its mtu is set only on the veth", s.cfg.ShortName, e.EndpointName)
+			continue
+		}
+		mtus = append(mtus, interfaceMTU{Name: name, MTU: e.MTU})
+	}
+	sort.Slice(mtus, func(i, j int) bool {
+		return mtus[i].Name < mtus[j].Name
 	})
+	return mtus, nil
+}
+
+// RunningVersion returns the SR Linux version of the running node.
+// the version is retrieved once and cached, an empty string is returned if it can't be retrieved.
+func (s *srl) RunningVersion(ctx context.Context) string {
+	if s.version != "" {
+		return s.version
+	}
+
+	stdout, stderr, err := s.runtime.Exec(ctx, s.cfg.LongName, versionCmd)
+	if err != nil || len(stderr) > 0 {
+		log.Debugf("node %s: failed to retrieve version: %v %s", s.cfg.ShortName, err, stderr)
+		return ""
+	}
+	s.version = parseVersion(string(stdout))
+
+	return s.version
+}
+
+// parseVersion extracts the version from the output of the `info from state system information version` command,
+// which has the form of `version v21.6.2-67-g5a2a0ef9f6`, possibly preceded by the command context.
+func parseVersion(out string) string {
+	for _, l := range strings.Split(out, "\n") {
+		fields := strings.Fields(l)
+		if len(fields) == 2 && fields[0] == "version" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+func (s *srl) GetImages() map[string]string {
+	return map[string]string{
+		nodes.ImageKey: s.cfg.Image,
+	}
+}
+
+func (s *srl) WithMgmtNet(m *types.MgmtNet)           { s.mgmt = m }
+func (s *srl) WithRuntime(r runtime.ContainerRuntime) { s.runtime = r }
+func (s *srl) GetRuntime() runtime.ContainerRuntime   { return s.runtime }
+
+// WithLifecycleHook registers the hook notified of the node lifecycle stages
+func (s *srl) WithLifecycleHook(h nodes.LifecycleHook) { s.lifecycleHook = h }
+
+func (s *srl) Delete(ctx context.Context) error {
+	if err := s.runtime.DeleteContainer(ctx, s.Config().LongName); err != nil {
+		return err
+	}
+	s.releaseBaseMAC()
+	return nil
+}
+
+func (s *srl) SaveConfig(ctx context.Context) error {
+	if s.configReadOnly {
+		return fmt.Errorf("%s: config directory is read-only as set with %s label, config can't be saved", s.cfg.ShortName, configReadOnlyLabel)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.saveTimeout)
+	defer cancel()
+
+	stdout, stderr, err := s.runtime.Exec(ctx, s.cfg.LongName, saveCmd)
+	// the saved config might be incomplete, so it is not copied over the last good copy in the lab dir
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s: config save timed out after %s", s.cfg.ShortName, s.saveTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to execute cmd: %v", s.cfg.ShortName, err)
+	}
+
+	if len(stderr) > 0 {
+		return fmt.Errorf("%s errors: %s", s.cfg.ShortName, string(stderr))
+	}
+
+	log.Infof("saved SR Linux configuration from %s node. Output:\n%s", s.cfg.ShortName, string(stdout))
+
+	return s.copySavedConfig()
+}
+
+// savedConfigPath returns the host path of the config.json saved by the node in the config dir bind mount,
+// the path is empty if the config dir is not bind mounted
+func (s *srl) savedConfigPath() string {
+	var cfgDir string
+	for _, b := range s.cfg.Binds {
+		parts := strings.Split(b, ":")
+		if len(parts) >= 2 && filepath.Clean(parts[1]) == filepath.Clean(srlConfigDir) {
+			cfgDir = parts[0]
+		}
+	}
+	if cfgDir == "" {
+		return ""
+	}
+	return filepath.Join(cfgDir, "config.json")
+}
+
+// copySavedConfig copies the config.json saved by the node from the config dir bind mount
+// to a file in the node's lab dir. The copy is skipped if the config dir is not bind mounted.
+func (s *srl) copySavedConfig() error {
+	src := s.savedConfigPath()
+	if src == "" {
+		log.Debugf("node %s: %s is not bind mounted, skipping saved config copy", s.cfg.ShortName, srlConfigDir)
+		return nil
+	}
+
+	if !utils.FileExists(src) {
+		log.Warnf("node %s: saved config %s not found, skipping copy", s.cfg.ShortName, src)
+		return nil
+	}
+
+	name := savedConfigFile
+	if v, ok := s.cfg.Labels[savedConfigLabel]; ok && v != "" {
+		name = v
+	}
+	dst := filepath.Join(s.cfg.LabDir, name)
+	// the copy is written atomically, so that a failed copy doesn't leave a truncated config in the lab dir
+	b, err := os.ReadFile(src)
+	if err == nil {
+		err = utils.WriteFileAtomic(dst, b, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to copy saved config %s -> %s: %v", s.cfg.ShortName, src, dst, err)
+	}
+	log.Infof("copied saved SR Linux configuration of %s node to %s", s.cfg.ShortName, dst)
+
+	return nil
+}
+
+// ExportConfig saves the node config and writes the saved config.json to <destDir>/<node name>.json
+func (s *srl) ExportConfig(ctx context.Context, destDir string) (string, error) {
+	if err := s.SaveConfig(ctx); err != nil {
+		return "", err
+	}
+	src := s.savedConfigPath()
+	if src == "" {
+		return "", fmt.Errorf("%s: %s is not bind mounted, the saved config can't be exported", s.cfg.ShortName, srlConfigDir)
+	}
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to read saved config: %v", s.cfg.ShortName, err)
+	}
+	utils.CreateDirectory(destDir, 0755)
+	dst := filepath.Join(destDir, s.cfg.ShortName+".json")
+	if err := utils.WriteFileAtomic(dst, b, 0644); err != nil {
+		return "", fmt.Errorf("%s: failed to export saved config to %s: %v", s.cfg.ShortName, dst, err)
+	}
+	log.Infof("exported SR Linux configuration of %s node to %s", s.cfg.ShortName, dst)
+	return dst, nil
+}
+
+// Capabilities returns the features supported by the node with its settings:
+// the config can't be saved with a read-only config dir and the gNMI and JSON-RPC servers
+// are reported only when they are enabled by the default config
+func (s *srl) Capabilities() []nodes.NodeCapability {
+	caps := []nodes.NodeCapability{nodes.CapabilityExec}
+	if !s.configReadOnly {
+		caps = append(caps, nodes.CapabilitySaveConfig)
+	}
+	if !s.skipDefaultConfig {
+		if s.gnmi {
+			caps = append(caps, nodes.CapabilityGNMI)
+		}
+		if s.jsonRPC {
+			caps = append(caps, nodes.CapabilityJSONRPC)
+		}
+	}
+	return caps
+}
+
+// GetRunningConfig returns the running config of the node in the CLI format.
+// sr_cli writes the config to the config dir bind mount, and it is read from the lab dir rather than buffered
+// in the exec output, configs larger than maxRunningConfigSize are rejected.
+func (s *srl) GetRunningConfig(ctx context.Context) (string, error) {
+	if s.configReadOnly {
+		return "", fmt.Errorf("%s: config directory is read-only as set with %s label, running config can't be retrieved", s.cfg.ShortName, configReadOnlyLabel)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.saveTimeout)
+	defer cancel()
+
+	p := filepath.Join(s.cfg.LabDir, "config", runningConfigFile)
+	defer os.Remove(p)
+
+	res, err := s.runtime.ExecWithResult(ctx, s.cfg.LongName, []string{
+		"bash",
+		"-c",
+		"sr_cli -d info from running / > " + path.Join(srlConfigDir, runningConfigFile),
+	})
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("%s: running config retrieval timed out after %s", s.cfg.ShortName, s.saveTimeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to execute cmd: %v", s.cfg.ShortName, err)
+	}
+	if res.ExitCode != 0 {
+		stderr := strings.TrimSpace(res.Stderr)
+		if stderr == "" {
+			stderr = fmt.Sprintf("sr_cli exited with code %d", res.ExitCode)
+		}
+		return "", fmt.Errorf("%s: failed to retrieve running config: %s", s.cfg.ShortName, stderr)
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to read running config: %v", s.cfg.ShortName, err)
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	n, err := io.Copy(&b, io.LimitReader(f, maxRunningConfigSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to read running config: %v", s.cfg.ShortName, err)
+	}
+	if n > maxRunningConfigSize {
+		return "", fmt.Errorf("%s: running config exceeds %d bytes", s.cfg.ShortName, maxRunningConfigSize)
+	}
+
+	return b.String(), nil
+}
+
+// Ready returns when the node boot sequence reached the stage when it is ready to accept config commands
+// returns an error if not ready by the expiry of the node's boot timeout.
+// a node with SR Linux not started automatically is reported ready right away.
+func (s *srl) Ready(ctx context.Context) error {
+	if !s.autostart {
+		return nil
+	}
+	return s.waitBoot(ctx)
+}
+
+// waitBoot waits for SR Linux to boot up to the node's boot timeout
+func (s *srl) waitBoot(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.bootTimeout)
+	defer cancel()
+
+	log.Debugf("Waiting for SR Linux node %q to boot...", s.cfg.ShortName)
+	exitCh := s.watchExit(ctx, cancel)
+	var err error
+	if s.readyProbe == readyProbeGNMI {
+		err = s.gnmiReady(ctx)
+	} else {
+		err = s.cliReady(ctx)
+	}
+	if err != nil {
+		// the probe error is a timeout when the wait was cut short by the container exit
+		select {
+		case exitErr := <-exitCh:
+			err = exitErr
+		default:
+		}
+		return s.bootLogErr(err)
+	}
+
+	s.readyReported.Do(func() { nodes.NotifyStage(s.lifecycleHook, s.cfg.ShortName, nodes.StageReady) })
+	return nil
+}
+
+// watchExit polls the state of the node's container until ctx is done.
+// once the container exits, the error with its exit code is sent to the returned channel and the boot wait is cancelled.
+// the returned channel is nil if the runtime can't report the container state.
+func (s *srl) watchExit(ctx context.Context, cancel context.CancelFunc) <-chan error {
+	si, ok := s.runtime.(runtime.StatusInspector)
+	if !ok {
+		return nil
+	}
+	exitCh := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryTimer):
+			}
+			st, err := si.ContainerStatus(ctx, s.cfg.LongName)
+			if err != nil {
+				log.Debugf("failed to retrieve the container state of node %s: %v", s.cfg.ShortName, err)
+				continue
+			}
+			if st.Exited() {
+				exitCh <- fmt.Errorf("container of SR Linux node %s %s with exit code %d while booting", s.cfg.ShortName, st.State, st.ExitCode)
+				cancel()
+				return
+			}
+		}
+	}()
+	return exitCh
+}
+
+// bootLogErr adds the last lines of the node's boot log to the error returned when the node fails to boot.
+// the error is returned as is if the runtime doesn't keep the containers output.
+func (s *srl) bootLogErr(err error) error {
+	t, ok := s.runtime.(runtime.LogTailer)
+	if !ok || s.bootLogLines == 0 {
+		return err
+	}
+
+	// the ready context is likely done already
+	ctx, cancel := context.WithTimeout(context.Background(), bootLogTimeout)
+	defer cancel()
+
+	buf := utils.NewTailBuffer(s.bootLogLines, bootLogLineLen)
+	if lerr := t.TailLogs(ctx, s.cfg.LongName, s.bootLogLines, buf); lerr != nil {
+		log.Debugf("failed to retrieve the boot log of node %s: %v", s.cfg.ShortName, lerr)
+		return err
+	}
+	if buf.String() == "" {
+		return err
+	}
+	return fmt.Errorf("%v\nlast %d lines of node %s boot log:\n%s", err, s.bootLogLines, s.cfg.ShortName, buf.String())
+}
+
+// cliReady checks the node boot status by executing sr_cli commands inside the container.
+// the checks are backed off, so that the nodes of large labs booting at once don't overload the runtime with execs.
+func (s *srl) cliReady(ctx context.Context) error {
+	var err error
+	wait := s.pollInterval()
+	checks := s.bootChecks(ctx)
+	for {
+		var booted bool
+		if booted, err = s.cliBooted(ctx, checks); booted {
+			log.Debugf("Node %s booted", s.cfg.ShortName)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for SR Linux node %s to boot: %v", s.cfg.ShortName, err)
+		case <-time.After(wait):
+		}
+		wait = nextPollInterval(wait)
+	}
+}
+
+// pollInterval returns the initial interval of the readiness checks, defaults to retryTimer
+func (s *srl) pollInterval() time.Duration {
+	if s.readyPollInterval == 0 {
+		return retryTimer
+	}
+	return s.readyPollInterval
+}
+
+// nextPollInterval doubles the readiness poll interval d up to maxReadyPollInterval.
+// an interval set above the cap is kept as is.
+func nextPollInterval(d time.Duration) time.Duration {
+	if d >= maxReadyPollInterval {
+		return d
+	}
+	if d *= 2; d > maxReadyPollInterval {
+		return maxReadyPollInterval
+	}
+	return d
+}
+
+// cliBooted checks once with sr_cli whether the node finished booting, i.e. it passes all the checks in order.
+// an error is returned if the check commands fail to execute.
+func (s *srl) cliBooted(ctx context.Context, checks []readyCheck) (bool, error) {
+	for _, c := range checks {
+		v, err := s.bootState(ctx, c.cmd, c.leaf)
+		if err != nil {
+			return false, err
+		}
+		if v != c.value {
+			log.Debugf("node %s not yet ready, %s is %q", s.cfg.ShortName, c.leaf, v)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// bootState executes the sr_cli state command cmd and returns the value of its leaf.
+// an empty value is returned if sr_cli exits with a non-zero code, e.g. when the management server is not started yet.
+func (s *srl) bootState(ctx context.Context, cmd []string, leaf string) (string, error) {
+	res, err := s.GetRuntime().ExecWithResult(ctx, s.cfg.LongName, cmd)
+	if err != nil {
+		return "", err
+	}
+	if res.ExitCode != 0 {
+		log.Debugf("error during checking SR Linux boot status, exit code %d: %s", res.ExitCode, res.Stderr)
+		return "", nil
+	}
+	return leafValue(res.Stdout, leaf), nil
+}
+
+// leafValue returns the value of the leaf in the sr_cli info output,
+// so that the values merely containing the expected one are not matched.
+// returns an empty string if the leaf is not found.
+func leafValue(out, leaf string) string {
+	for _, l := range strings.Split(out, "\n") {
+		if f := strings.Fields(l); len(f) == 2 && f[0] == leaf {
+			return f[1]
+		}
+	}
+	return ""
+}
+
+// Status reports the node status without waiting for it to change.
+// the container state is checked first, when the container is running
+// the boot status is checked once with the sr_cli commands of the cli ready probe, whatever the node's probe is.
+func (s *srl) Status(ctx context.Context) (nodes.NodeStatus, error) {
+	if si, ok := s.runtime.(runtime.StatusInspector); ok {
+		st, err := si.ContainerStatus(ctx, s.cfg.LongName)
+		if err != nil {
+			return nodes.NodeStatusUnknown, err
+		}
+		switch {
+		case st.State == runtime.ContainerStateCreated:
+			return nodes.NodeStatusCreating, nil
+		case st.State == runtime.ContainerStateExited && st.ExitCode == 0:
+			return nodes.NodeStatusStopped, nil
+		case st.Exited():
+			return nodes.NodeStatusFailed, nil
+		}
+	}
+
+	if !s.autostart {
+		return nodes.NodeStatusReady, nil
+	}
+	booted, err := s.cliBooted(ctx, s.bootChecks(ctx))
+	if err != nil {
+		return nodes.NodeStatusUnknown, fmt.Errorf("node %s: failed to check boot status: %v", s.cfg.ShortName, err)
+	}
+	if !booted {
+		return nodes.NodeStatusBooting, nil
+	}
+	return nodes.NodeStatusReady, nil
+}
+
+func (s *srl) createSRLFiles() error {
+	nodeCfg := s.cfg
+	log.Debugf("Creating directory structure for SRL container: %s", nodeCfg.ShortName)
+	var src string
+	var dst string
+
+	// the base mac is generated first, as the license can be selected by it.
+	// the base mac of a preserved topology file is kept, so the file is not rendered again
+	var err error
+	m, preserved := s.preservedBaseMAC()
+	if !preserved {
+		if m, err = s.baseMAC(); err != nil {
+			return err
+		}
+	}
+
+	// the license selected from the license dir takes precedence over the license file
+	src = nodeCfg.License
+	if s.licenseDir != "" {
+		if src, err = s.licenseFromDir(m); err != nil {
+			return err
+		}
+	}
+	if src != "" {
+		if nodeCfg.LicenseSHA256 != "" && s.license == nil {
+			b, err := os.ReadFile(src)
+			if err != nil {
+				return err
+			}
+			if err := verifySHA256(b, nodeCfg.LicenseSHA256); err != nil {
+				return fmt.Errorf("node %s: license %s: %v", nodeCfg.ShortName, src, err)
+			}
+		}
+		// copy license file to node specific directory in lab
+		dst = filepath.Join(nodeCfg.LabDir, "license.key")
+		if err := utils.CopyFile(src, dst, 0644); err != nil {
+			return fmt.Errorf("CopyFile src %s -> dst %s failed %v", src, dst, err)
+		}
+		log.Debugf("CopyFile src %s -> dst %s succeeded", src, dst)
+	}
+
+	if s.license != nil {
+		if nodeCfg.LicenseSHA256 != "" {
+			if err := verifySHA256(s.license, nodeCfg.LicenseSHA256); err != nil {
+				return fmt.Errorf("node %s: license set with %s label: %v", nodeCfg.ShortName, licenseB64Label, err)
+			}
+		}
+		// the license provided inline takes precedence over the license file
+		dst = filepath.Join(nodeCfg.LabDir, "license.key")
+		if err := os.WriteFile(dst, s.license, 0600); err != nil {
+			return fmt.Errorf("failed to write license to %s: %v", dst, err)
+		}
+		// WriteFile doesn't change permissions of an existing file
+		if err := os.Chmod(dst, 0600); err != nil {
+			return err
+		}
+	}
+
+	// generate SRL topology file
+	if !preserved {
+		err = generateSRLTopologyFile(nodeCfg.NodeType, s.topologyTemplate, nodeCfg.LabDir, m)
+		if err != nil {
+			return err
+		}
+	}
+
+	utils.CreateDirectory(path.Join(nodeCfg.LabDir, "config"), 0777)
+
+	// generate a startup config file
+	// if the node has a `startup-config:` statement, the file specified in that section
+	// will be used as a template in GenerateConfig(), rendered with the node config and its links
+	if nodeCfg.StartupConfig != "" {
+		return s.generateStartupConfig()
+	}
+
+	return nil
+}
+
+// generateStartupConfig renders the startup config of the node to its lab dir.
+// in merge mode the startup config is a CLI snippet that is rendered outside of the config dir
+// and applied on top of the default config in PostDeploy
+func (s *srl) generateStartupConfig() error {
+	nodeCfg := s.cfg
+	dst := filepath.Join(nodeCfg.LabDir, "config", "config.json")
+	if nodeCfg.StartupConfigMode == startupConfigModeMerge {
+		dst = filepath.Join(nodeCfg.LabDir, mergeConfigFile)
+	}
 
+	cfgTemplate, err := s.startupConfigTemplate()
 	if err != nil {
 		return err
 	}
 
-	stdout, stderr, err := s.runtime.Exec(ctx, s.cfg.LongName, []string{
-		"bash",
-		"-c",
-		"sr_cli -ed < tmp/clab-config",
+	err = nodeCfg.GenerateConfigWithData(dst, cfgTemplate, s.startupConfigData())
+	if err != nil {
+		log.Errorf("node=%s, failed to generate config: %v", nodeCfg.ShortName, err)
+		return err
+	}
+
+	// the CLI snippets are not JSON, they are checked by the node when applied
+	if nodeCfg.StartupConfigMode == startupConfigModeMerge || isCLIConfig(nodeCfg.StartupConfig) {
+		return nil
+	}
+	return s.validateJSONConfig(dst)
+}
+
+// startupConfigTemplate returns the startup config template of the node read from the file or fetched from the URL.
+// the template is verified against the expected digest and decompressed if it is gzipped.
+func (s *srl) startupConfigTemplate() (string, error) {
+	nodeCfg := s.cfg
+	var c []byte
+	var err error
+	if utils.IsHTTPURL(nodeCfg.StartupConfig) {
+		log.Debugf("Fetching startup-config %s", nodeCfg.StartupConfig)
+		c, err = utils.FetchHTTP(nodeCfg.StartupConfig, s.fetchTimeout)
+		if err != nil {
+			return "", fmt.Errorf("node %s: startup-config: %w", nodeCfg.ShortName, err)
+		}
+	} else {
+		log.Debugf("Reading startup-config %s", nodeCfg.StartupConfig)
+		c, err = os.ReadFile(nodeCfg.StartupConfig)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if nodeCfg.StartupConfigSHA256 != "" {
+		if err := verifySHA256(c, nodeCfg.StartupConfigSHA256); err != nil {
+			return "", fmt.Errorf("node %s: startup-config %s: %v", nodeCfg.ShortName, nodeCfg.StartupConfig, err)
+		}
+	}
+
+	// a gzipped startup config is decompressed before templating and written in plain text
+	if isGzipped(nodeCfg.StartupConfig) {
+		if c, err = gunzip(c); err != nil {
+			return "", fmt.Errorf("node %s: startup-config %s is not a valid gzip file: %v", nodeCfg.ShortName, nodeCfg.StartupConfig, err)
+		}
+	}
+
+	return string(c), nil
+}
+
+type mac struct {
+	MAC string
+}
+
+// defaultConfig is the data the default config template is rendered with
+type defaultConfig struct {
+	*types.NodeConfig
+	IdleTimeout          uint64
+	IdleTimeoutSupported bool
+	// when unset, the servers are enabled without the clab-profile TLS server profile,
+	// which is also left out when none of the servers is enabled
+	TLS bool
+	// paths in the container to the certificate and key of the clab-profile, which are not inlined in the config
+	TLSCertPath string
+	TLSKeyPath  string
+	// gNMI and JSON-RPC servers are enabled unless disabled with the gnmi and json-rpc labels
+	GNMI    bool
+	JSONRPC bool
+	// ports of the gNMI and JSON-RPC servers, not configured when 0 and the servers listen on the default ports
+	GNMIPort         int
+	JSONRPCHTTPPort  int
+	JSONRPCHTTPSPort int
+	// LLDP is enabled unless disabled with the lldp label
+	LLDP bool
+	// gRIBI and P4Runtime servers are enabled when requested and supported by the image
+	GRIBI     bool
+	P4RT      bool
+	CommitCmd string
+	// breakout modes of the ports, configured before the breakout ports
+	Breakouts []interfaceBreakout
+	// MTUs of the interfaces connected to the links with the MTU set
+	InterfaceMTUs []interfaceMTU
+	// network-instance the management servers are enabled in
+	MgmtNetworkInstance string
+	// credentials of the superuser, not configured when AdminUser is empty
+	AdminUser     string
+	AdminPassword string
+}
+
+// interfaceMTU is the MTU of an SR Linux interface
+type interfaceMTU struct {
+	Name string
+	MTU  int
+}
+
+// imageFeatures are the optional default config settings supported by the node's image
+type imageFeatures struct {
+	idleTimeout bool
+	gribi       bool
+	p4rt        bool
+}
+
+// allFeatures is used when the config is rendered without access to the node
+var allFeatures = imageFeatures{idleTimeout: true, gribi: true, p4rt: true}
+
+// commitCmd returns the CLI command committing the candidate config.
+// in the now commit mode, which is used with a read-only config dir, the config is not saved to the startup config.
+func (s *srl) commitCmd() string {
+	return "commit " + s.commitMode
+}
+
+// schemaSupported checks that the node's image has the config path queried by cmd in its schema,
+// sr_cli reports an error for unknown config paths
+func (s *srl) schemaSupported(ctx context.Context, cmd []string) bool {
+	_, stderr, err := s.runtime.Exec(ctx, s.cfg.LongName, cmd)
+	if err != nil || len(stderr) > 0 {
+		log.Debugf("node %s: %s is not supported: %v %s", s.cfg.ShortName, strings.Join(cmd[3:], " "), err, stderr)
+		return false
+	}
+	return true
+}
+
+// imageFeatures checks which of the optional default config settings are supported by the node's image.
+// only the settings enabled for the node are checked, the gRIBI and P4Runtime servers are skipped with a warning.
+func (s *srl) imageFeatures(ctx context.Context) imageFeatures {
+	f := imageFeatures{idleTimeout: s.schemaSupported(ctx, idleTimeoutCmd)}
+	if s.gribi {
+		if f.gribi = s.schemaSupported(ctx, gribiServerCmd); !f.gribi {
+			log.Warnf("node %s: image doesn't support the gRIBI server, %s label is ignored", s.cfg.ShortName, gribiLabel)
+		}
+	}
+	if s.p4rt {
+		if f.p4rt = s.schemaSupported(ctx, p4rtServerCmd); !f.p4rt {
+			log.Warnf("node %s: image doesn't support the P4Runtime server, %s label is ignored", s.cfg.ShortName, p4rtLabel)
+		}
+	}
+	return f
+}
+
+// baseMAC returns the base mac for the node chassis, distinct from the base macs of the other lab nodes.
+// by default the 2-3rd bytes of a base mac are random,
+// with deterministic-mac label set they are derived from the hash of the node's long name.
+func (s *srl) baseMAC() (string, error) {
+	baseMACs.Lock()
+	defer baseMACs.Unlock()
+
+	// the 2-3rd bytes allow for 65536 distinct macs
+	if n := len(baseMACs.m[s.labName]); n >= 1<<16 {
+		return "", fmt.Errorf("node %s: all %d base macs are allocated", s.cfg.ShortName, n)
+	}
+
+	if !s.deterministicMAC {
+		// generate random bytes to use in the 2-3rd bytes of a base mac
+		// this ensures that different srl nodes will have different macs for their ports.
+		// on collision with a mac already allocated to another node, new random bytes are generated
+		buf := make([]byte, 2)
+		for {
+			if _, err := randRead(buf); err != nil {
+				return "", err
+			}
+			m := fmt.Sprintf("02:%02x:%02x:00:00:00", buf[0], buf[1])
+			if s.allocateBaseMAC(m) {
+				return m, nil
+			}
+			log.Debugf("node %s: random base mac %s is already allocated, retrying", s.cfg.ShortName, m)
+		}
+	}
+
+	// the long name contains the lab name, so the same node name yields different macs in different labs.
+	// on collision with a mac already allocated to another node, the hash is rehashed until a free mac is found
+	h := sha256.Sum256([]byte(s.cfg.LongName))
+	for {
+		m := fmt.Sprintf("02:%02x:%02x:00:00:00", h[0], h[1])
+		if s.allocateBaseMAC(m) {
+			return m, nil
+		}
+		h = sha256.Sum256(h[:])
+	}
+}
+
+// allocateBaseMAC allocates the base mac m to the node, returns false if m is allocated to another node of the lab.
+// baseMACs must be locked by the caller.
+func (s *srl) allocateBaseMAC(m string) bool {
+	macs, ok := baseMACs.m[s.labName]
+	if !ok {
+		macs = map[string]struct{}{}
+		baseMACs.m[s.labName] = macs
+	}
+	if _, ok := macs[m]; ok {
+		return false
+	}
+	macs[m] = struct{}{}
+	s.allocatedMAC = m
+	return true
+}
+
+// releaseBaseMAC releases the base mac allocated to the node
+func (s *srl) releaseBaseMAC() {
+	baseMACs.Lock()
+	defer baseMACs.Unlock()
+	if s.allocatedMAC == "" {
+		return
+	}
+	delete(baseMACs.m[s.labName], s.allocatedMAC)
+	if len(baseMACs.m[s.labName]) == 0 {
+		delete(baseMACs.m, s.labName)
+	}
+	s.allocatedMAC = ""
+}
+
+// generateSRLTopologyFile renders the topology file for the node type to the lab dir.
+// if tplFile is set, it is used as a template instead of the embedded topology file of the node type.
+// the checksum of the template and the base mac are recorded next to the file, so that it can be preserved on redeploy.
+func generateSRLTopologyFile(nodeType, tplFile, labDir, baseMAC string) error {
+	dst := filepath.Join(labDir, "topology.yml")
+
+	b, err := readTopologyTemplate(nodeType, tplFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to get srl topology file")
+	}
+	tpl, err := template.New("topology.yml").Parse(string(b))
+	if err != nil {
+		return errors.Wrap(err, "failed to get srl topology file")
+	}
+
+	mac := mac{
+		MAC: baseMAC,
+	}
+	log.Debug(mac, dst)
+	// the file is rendered in memory first, so that a failed render doesn't leave a truncated topology file
+	buf := new(bytes.Buffer)
+	if err := tpl.Execute(buf, mac); err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(dst, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return writeTopologySum(labDir, topologySum(nodeType, b), baseMAC)
+}
+
+// addDefaultConfig adds srl default configuration such as tls certs and gnmi/json-rpc
+func (s *srl) addDefaultConfig(ctx context.Context) error {
+	// start waiting for initial commit and mgmt server ready, bounded by the node boot timeout.
+	// SR Linux might have been started manually on a node that doesn't start it automatically
+	if err := s.waitBoot(ctx); err != nil {
+		return err
+	}
+
+	// mgmt addresses assigned by the runtime are known only after the container is created
+	if err := s.ensureCertMgmtAddrs(); err != nil {
+		return err
+	}
+	if s.tls {
+		if err := s.writeTLSProfileFiles(); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := s.renderDefaultConfig(s.imageFeatures(ctx))
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Node %q additional config:\n%s", s.cfg.ShortName, s.redact(cfg))
+
+	if !s.tls {
+		log.Warnf("node %s: TLS is disabled with %s label, gNMI and JSON-RPC servers are running without TLS", s.cfg.ShortName, tlsLabel)
+	}
+
+	if err := s.pushCLIConfig(ctx, cfg); err != nil {
+		return err
+	}
+	s.defaultConfigApplied = true
+	return nil
+}
+
+// ReConfigure re-applies the default config to the running node,
+// e.g. to restore the gNMI server config deleted by a user.
+// the config is made of set commands on the fixed clab-profile server profile, so it is not duplicated.
+// the node certificate must be loaded with GenerateCert beforehand.
+func (s *srl) ReConfigure(ctx context.Context) error {
+	if s.skipDefaultConfig {
+		return fmt.Errorf("node %s: default config provisioning is disabled with %s label", s.cfg.ShortName, skipDefaultConfigLabel)
+	}
+	if s.mtls {
+		if err := s.loadTLSAnchor(); err != nil {
+			return err
+		}
+	}
+
+	// the default config was applied when the node was deployed
+	s.defaultConfigApplied = true
+
+	log.Infof("Re-applying default config to Nokia SR Linux '%s' node", s.cfg.ShortName)
+
+	return s.addDefaultConfig(ctx)
+}
+
+// renderDefaultConfig renders the default config CLI commands of the node with the settings supported by its image
+func (s *srl) renderDefaultConfig(f imageFeatures) (string, error) {
+	mtus, err := s.interfaceMTUs()
+	if err != nil {
+		return "", err
+	}
+	gribi, p4rt := s.gribi && f.gribi, s.p4rt && f.p4rt
+	buf := new(bytes.Buffer)
+	err = srlCfgTpl.Execute(buf, defaultConfig{
+		NodeConfig:           s.cfg,
+		IdleTimeout:          s.idleTimeout,
+		IdleTimeoutSupported: f.idleTimeout,
+		TLS:                  s.tls && (s.gnmi || s.jsonRPC || gribi || p4rt),
+		TLSCertPath:          path.Join(srlConfigDir, tlsProfileDir, tlsProfileCertFile),
+		TLSKeyPath:           path.Join(srlConfigDir, tlsProfileDir, tlsProfileKeyFile),
+		GNMI:                 s.gnmi,
+		JSONRPC:              s.jsonRPC,
+		GNMIPort:             nonDefault(s.gnmiPort, defaultGNMIPort),
+		JSONRPCHTTPPort:      nonDefault(s.jsonRPCHTTPPort, defaultJSONRPCHTTPPort),
+		JSONRPCHTTPSPort:     nonDefault(s.jsonRPCHTTPSPort, defaultJSONRPCHTTPSPort),
+		GRIBI:                gribi,
+		P4RT:                 p4rt,
+		Breakouts:            s.breakouts,
+		InterfaceMTUs:        mtus,
+		AdminUser:            s.adminUser,
+		AdminPassword:        s.adminPassword,
+		MgmtNetworkInstance:  s.mgmtNetworkInstance,
+		LLDP:                 s.lldp,
+		CommitCmd:            s.commitCmd(),
 	})
+	return buf.String(), err
+}
+
+// RenderConfig writes the default config that is applied to the node after it boots to w.
+// the config is rendered without access to the node, so all the settings are assumed to be supported by the image.
+// the topology and startup config files are rendered to the node's lab dir by PreDeploy.
+func (s *srl) RenderConfig(w io.Writer) error {
+	cfg, err := s.renderDefaultConfig(allFeatures)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, s.redact(cfg)+"\n")
+	return err
+}
+
+// isGzipped returns true if the startup config file or URL path has the .gz extension
+func isGzipped(p string) bool {
+	if utils.IsHTTPURL(p) {
+		if u, err := url.Parse(p); err == nil {
+			p = u.Path
+		}
+	}
+	return path.Ext(p) == gzipExt
+}
+
+// gunzip decompresses the gzip stream b
+func gunzip(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// sha256Re matches a hex encoded SHA-256 digest
+var sha256Re = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// verifySHA256 checks that b has the hex encoded SHA-256 digest want
+func verifySHA256(b []byte, want string) error {
+	got := fmt.Sprintf("%x", sha256.Sum256(b))
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("SHA-256 digest mismatch, expected %s, got %s", strings.ToLower(want), got)
+	}
+	return nil
+}
+
+// networkInstanceRe matches the network-instance names that can be used in the default config commands unquoted
+var networkInstanceRe = regexp.MustCompile(`^[a-zA-Z0-9_.:-]+$`)
+
+// adminUserRe matches the user names accepted by SR Linux
+var adminUserRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.-]*$`)
+
+// initAdminCredentials sets the superuser credentials from the admin-user and admin-password(-file) labels.
+// the factory credentials are left untouched when no password is set.
+// the password is never included in the returned errors.
+func (s *srl) initAdminCredentials() error {
+	user, userSet := s.cfg.Labels[adminUserLabel]
+	pw, pwSet := s.cfg.Labels[adminPasswordLabel]
+	pwFile, pwFileSet := s.cfg.Labels[adminPasswordFileLabel]
+
+	switch {
+	case pwSet && pwFileSet:
+		return fmt.Errorf("node %s: only one of %s and %s labels can be set", s.cfg.ShortName, adminPasswordLabel, adminPasswordFileLabel)
+	case pwFileSet:
+		b, err := os.ReadFile(pwFile)
+		if err != nil {
+			return fmt.Errorf("node %s: failed to read the admin password file set with %s label: %v", s.cfg.ShortName, adminPasswordFileLabel, err)
+		}
+		pw = strings.TrimSpace(string(b))
+	case !pwSet:
+		if userSet {
+			return fmt.Errorf("node %s: %s label requires the password set with %s or %s label", s.cfg.ShortName, adminUserLabel, adminPasswordLabel, adminPasswordFileLabel)
+		}
+		return nil
+	}
+
+	// the password is set in a double-quoted argument of the CLI config file fed to sr_cli, which can't escape the quotes
+	if pw == "" || strings.ContainsAny(pw, "'\"\\\r\n") {
+		return fmt.Errorf("node %s: admin password must not be empty and must not contain quotes, backslashes or line breaks", s.cfg.ShortName)
+	}
+	if user == "" {
+		user = factoryAdminUser
+	}
+	if !adminUserRe.MatchString(user) {
+		return fmt.Errorf("node %s: wrong user name %q set with %s label", s.cfg.ShortName, user, adminUserLabel)
+	}
+	s.adminUser, s.adminPassword = user, pw
+	if pwFileSet {
+		p, err := filepath.Abs(pwFile)
+		if err != nil {
+			return err
+		}
+		s.adminPasswordFile = p
+	}
+	return nil
+}
+
+// GetCredentials returns the credentials of the superuser configured by containerlab or the factory credentials.
+// a password set with the admin-password label is left out, so that it is not written to the generated files.
+func (s *srl) GetCredentials() nodes.Credentials {
+	if s.adminPassword == "" {
+		creds := nodes.DefaultCredentials[nodes.NodeKindSRL]
+		return nodes.Credentials{Username: creds[0], Password: creds[1]}
+	}
+	return nodes.Credentials{Username: s.adminUser, PasswordFile: s.adminPasswordFile}
+}
+
+// probeCredentials returns the credentials the readiness probes log in with,
+// the factory credentials are used until the default config sets the admin credentials
+func (s *srl) probeCredentials() (user, password string) {
+	if s.adminPassword != "" && s.defaultConfigApplied {
+		return s.adminUser, s.adminPassword
+	}
+	creds := nodes.DefaultCredentials[nodes.NodeKindSRL]
+	return creds[0], creds[1]
+}
+
+// probePort returns the port the gNMI readiness probe dials: the default port of the gNMI server enabled at boot
+// until the default config that moves the server to the configured port is applied
+func (s *srl) probePort() int {
+	if s.defaultConfigApplied {
+		return s.gnmiPort
+	}
+	return defaultGNMIPort
+}
 
+// redact replaces the admin password in out, so that it is not logged
+func (s *srl) redact(out string) string {
+	if s.adminPassword == "" {
+		return out
+	}
+	return strings.ReplaceAll(out, s.adminPassword, redactedPassword)
+}
+
+// mergeStartupConfig applies the rendered startup-config CLI snippet on top of the running config
+func (s *srl) mergeStartupConfig(ctx context.Context) error {
+	c, err := os.ReadFile(filepath.Join(s.cfg.LabDir, mergeConfigFile))
 	if err != nil {
 		return err
 	}
 
-	log.Debugf("node %s. stdout: %s, stderr: %s", s.cfg.ShortName, stdout, stderr)
+	log.Debugf("Node %q merged startup config:\n%s", s.cfg.ShortName, c)
+
+	return s.pushCLIConfig(ctx, strings.TrimRight(string(c), "\n")+"\n"+s.commitCmd())
+}
+
+// pushCLIConfig writes CLI commands to the node config dir and executes them with sr_cli in candidate mode.
+// the config reaches the container through the config dir bind mount rather than an exec command line,
+// so the node's TLS key it holds doesn't show up in the container process list.
+// the file is readable by the container user only, it is removed once the config is applied
+// and kept for debugging otherwise.
+func (s *srl) pushCLIConfig(ctx context.Context, cfg string) error {
+	p := filepath.Join(s.cfg.LabDir, "config", cliConfigFile)
+	if err := os.WriteFile(p, []byte(cfg+"\n"), 0600); err != nil {
+		return fmt.Errorf("%s: failed to write config file: %v", s.cfg.ShortName, err)
+	}
+	// WriteFile doesn't change permissions of an existing file
+	if err := os.Chmod(p, 0600); err != nil {
+		return err
+	}
+	s.chownToUser(p)
+
+	if err := s.applyCLIConfig(ctx); err != nil {
+		log.Warnf("node %s: failed to apply config, the config file is kept at %s", s.cfg.ShortName, p)
+		return err
+	}
+
+	if err := os.Remove(p); err != nil {
+		log.Warnf("node %s: failed to remove %s: %v", s.cfg.ShortName, p, err)
+	}
 
 	return nil
 }
+
+// applyCLIConfig executes the CLI commands copied to the node with sr_cli,
+// retrying on transient commit errors
+func (s *srl) applyCLIConfig(ctx context.Context) error {
+	backoff := pushRetryBackoff
+	for attempt := 1; ; attempt++ {
+		res, err := s.runtime.ExecWithResult(ctx, s.cfg.LongName, []string{
+			"bash",
+			"-c",
+			"sr_cli -ed < " + path.Join(srlConfigDir, cliConfigFile),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		log.Debugf("node %s. attempt %d. exit code: %d, stdout: %s, stderr: %s", s.cfg.ShortName, attempt, res.ExitCode, s.redact(res.Stdout), s.redact(res.Stderr))
+
+		// sr_cli exits with a non-zero code if a command or the commit fails
+		if res.ExitCode == 0 {
+			return nil
+		}
+		// the errors might echo the commands with the admin password
+		stderr := s.redact(strings.TrimSpace(res.Stderr))
+		if stderr == "" {
+			stderr = fmt.Sprintf("sr_cli exited with code %d", res.ExitCode)
+		}
+		if !isTransientCommitErr(stderr) {
+			return fmt.Errorf("%s: failed to apply config: %s", s.cfg.ShortName, stderr)
+		}
+		if attempt == maxPushAttempts {
+			return fmt.Errorf("%s: failed to apply config after %d attempts: %s", s.cfg.ShortName, attempt, stderr)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: failed to apply config: %v", s.cfg.ShortName, ctx.Err())
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxPushRetryBackoff {
+			backoff = maxPushRetryBackoff
+		}
+	}
+}
+
+// isTransientCommitErr returns true if the sr_cli error output reports a commit failure
+// that is likely to succeed when retried, such as a concurrent commit
+func isTransientCommitErr(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	for _, e := range transientCommitErrs {
+		if strings.Contains(stderr, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// labelBool parses the boolean value of the node label with the given key.
+// returns false if the label is not set.
+func labelBool(labels map[string]string, key string) (bool, error) {
+	v, ok := labels[key]
+	if !ok {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("wrong value %q set with %s label, should be a boolean", v, key)
+	}
+	return b, nil
+}
+
+// closestMatch returns the element of candidates with the smallest Levenshtein distance to s
+func closestMatch(s string, candidates []string) string {
+	var match string
+	min := -1
+	for _, c := range candidates {
+		if d := levenshtein(s, c); min < 0 || d < min {
+			match, min = c, d
+		}
+	}
+	return match
+}
+
+// levenshtein returns the Levenshtein edit distance between a and b
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(v int, vs ...int) int {
+	for _, x := range vs {
+		if x < v {
+			v = x
+		}
+	}
+	return v
+}
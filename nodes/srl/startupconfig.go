@@ -0,0 +1,158 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/clab/config"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// startupConfig is the data the startup config template is rendered with.
+// the node configuration fields are available as before, e.g. {{ .ShortName }}
+type startupConfig struct {
+	*types.NodeConfig
+	// links of the node, sorted by the local interface name
+	Links []startupConfigLink
+}
+
+// startupConfigLink is a link of the node as seen from the node's end
+type startupConfigLink struct {
+	// name of the node and of its interface as set in the topology file, e.g. e1-1
+	LocalNode      string
+	LocalInterface string
+	// SR Linux name of the local interface, e.g. ethernet-1/1, empty if it is not an SR Linux interface
+	Interface string
+	// name of the node and of its interface at the other end of the link, as set in the topology file
+	RemoteNode      string
+	RemoteInterface string
+	// addresses of both ends in the address/prefix-length format, from the clab_link_ip link variable
+	// or calculated from the clab_system_ip node variables, empty if the link has no addresses
+	LocalIP  string
+	RemoteIP string
+	// MTU of the link set in the topology file, 0 if not set
+	MTU int
+	// variables of the link set in the topology file
+	Vars map[string]interface{}
+}
+
+// startupConfigData returns the data the startup config template is rendered with.
+func (s *srl) startupConfigData() *startupConfig {
+	d := &startupConfig{NodeConfig: s.cfg}
+	for _, e := range s.cfg.Endpoints {
+		if e.Link == nil {
+			continue
+		}
+		local, remote := e.Link.A, e.Link.B
+		if e != local {
+			local, remote = remote, local
+		}
+		l := startupConfigLink{
+			LocalNode:       s.cfg.ShortName,
+			LocalInterface:  e.EndpointName,
+			Interface:       srlInterfaceName(e.EndpointName),
+			RemoteNode:      remote.Node.ShortName,
+			RemoteInterface: remote.EndpointName,
+			MTU:             e.MTU,
+			Vars:            e.Link.Vars,
+		}
+		ipA, ipB, err := config.LinkIPs(e.Link)
+		if err != nil {
+			log.Warnf("node %s: failed to get the addresses of the %s: %v", s.cfg.ShortName, e.Link, err)
+		}
+		l.LocalIP, l.RemoteIP = ipA, ipB
+		if e != e.Link.A {
+			l.LocalIP, l.RemoteIP = ipB, ipA
+		}
+		d.Links = append(d.Links, l)
+	}
+	sort.Slice(d.Links, func(i, j int) bool {
+		return d.Links[i].LocalInterface < d.Links[j].LocalInterface
+	})
+	return d
+}
+
+// isCLIConfig returns true if the startup config at p is a CLI snippet, possibly gzipped
+func isCLIConfig(p string) bool {
+	return filepath.Ext(strings.TrimSuffix(p, gzipExt)) == ".cli"
+}
+
+// validateJSONConfig checks that the config.json rendered from the startup config is well-formed JSON,
+// as SR Linux ignores a malformed config.json and boots without any config.
+// the syntax errors are reported with the line and column they are found at.
+func (s *srl) validateJSONConfig(p string) error {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	var v interface{}
+	err = json.Unmarshal(b, &v)
+	if err == nil {
+		return nil
+	}
+	var serr *json.SyntaxError
+	if errors.As(err, &serr) {
+		line, col := lineColumn(b, serr.Offset)
+		return fmt.Errorf("node %s: startup-config %s rendered to %s is not valid JSON: line %d, column %d: %v",
+			s.cfg.ShortName, s.cfg.StartupConfig, p, line, col, serr)
+	}
+	return fmt.Errorf("node %s: startup-config %s rendered to %s is not valid JSON: %v", s.cfg.ShortName, s.cfg.StartupConfig, p, err)
+}
+
+// lineColumn returns the 1-based line and column of the byte preceding offset in b,
+// which is the byte a json syntax error is found at
+func lineColumn(b []byte, offset int64) (int, int) {
+	if offset > int64(len(b)) {
+		offset = int64(len(b))
+	}
+	// an error found at the end of a line, e.g. an unexpected end of the input, is reported at its last character
+	before := bytes.TrimSuffix(b[:offset], []byte("\n"))
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n') - 1
+	if col < 1 {
+		col = 1
+	}
+	return line, col
+}
+
+// PushStartupConfig re-renders the startup config of the node to its lab dir and, when the node is running,
+// applies it to the node without recreating the container.
+// a full startup config is loaded in place of the running config, while a startup config in merge mode
+// is applied on top of it, like when the node is deployed.
+// a node that is not running boots with the regenerated startup config when its container is started again.
+func (s *srl) PushStartupConfig(ctx context.Context, running bool) error {
+	if s.cfg.StartupConfig == "" {
+		return fmt.Errorf("%s: node has no startup-config to push", s.cfg.ShortName)
+	}
+	merge := s.cfg.StartupConfigMode == startupConfigModeMerge
+	if s.configReadOnly && !merge {
+		return fmt.Errorf("%s: config directory is read-only as set with %s label, startup-config can't be regenerated", s.cfg.ShortName, configReadOnlyLabel)
+	}
+
+	if err := s.generateStartupConfig(); err != nil {
+		return err
+	}
+	if !running {
+		log.Infof("node %s is not running, startup-config regenerated in %s", s.cfg.ShortName, s.cfg.LabDir)
+		return nil
+	}
+
+	log.Infof("Pushing startup-config to Nokia SR Linux '%s' node", s.cfg.ShortName)
+	if merge {
+		return s.mergeStartupConfig(ctx)
+	}
+	return s.pushCLIConfig(ctx, "load file "+path.Join(srlConfigDir, "config.json")+"\n"+s.commitCmd())
+}
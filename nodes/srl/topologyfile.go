@@ -0,0 +1,82 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// name of the file in the lab dir recording the checksum of the topology file template and the base mac it was rendered with
+const topologySumFile = ".topology.yml.sha256"
+
+// WithPreserveTopology keeps the topology file generated by a previous deployment of the node,
+// so that the chassis base mac, and the interface macs derived from it, are stable across redeploys.
+func (s *srl) WithPreserveTopology() { s.preserveTopology = true }
+
+// readTopologyTemplate returns the topology file template of the node type or the user provided template tplFile
+func readTopologyTemplate(nodeType, tplFile string) ([]byte, error) {
+	if tplFile != "" {
+		return os.ReadFile(tplFile)
+	}
+	return topologies.ReadFile("topology/" + srlTypes[nodeType])
+}
+
+// topologySum returns the hex encoded checksum of the topology file template tpl of the node type
+func topologySum(nodeType string, tpl []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(append([]byte(nodeType+"\n"), tpl...)))
+}
+
+// writeTopologySum records the checksum of the topology file template and the base mac in the lab dir
+func writeTopologySum(labDir, sum, baseMAC string) error {
+	return utils.WriteFileAtomic(filepath.Join(labDir, topologySumFile), []byte(sum+" "+baseMAC+"\n"), 0644)
+}
+
+// preservedBaseMAC returns the base mac of the topology file generated by a previous deployment,
+// when the topology is preserved and the file was rendered from the same template for the same node type.
+// the base mac is allocated to the node, it is not preserved if another node of the lab already uses it.
+func (s *srl) preservedBaseMAC() (string, bool) {
+	if !s.preserveTopology || !utils.FileExists(filepath.Join(s.cfg.LabDir, "topology.yml")) {
+		return "", false
+	}
+	b, err := os.ReadFile(filepath.Join(s.cfg.LabDir, topologySumFile))
+	if err != nil {
+		log.Infof("node %s: topology file has no checksum, generating a new one", s.cfg.ShortName)
+		return "", false
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) != 2 {
+		log.Warnf("node %s: malformed topology file checksum %s, generating a new topology file", s.cfg.ShortName, topologySumFile)
+		return "", false
+	}
+	if _, err := net.ParseMAC(fields[1]); err != nil {
+		log.Warnf("node %s: malformed base mac %q in %s, generating a new topology file", s.cfg.ShortName, fields[1], topologySumFile)
+		return "", false
+	}
+	tpl, err := readTopologyTemplate(s.cfg.NodeType, s.topologyTemplate)
+	if err != nil {
+		return "", false
+	}
+	if fields[0] != topologySum(s.cfg.NodeType, tpl) {
+		log.Infof("node %s: node type or topology template changed, generating a new topology file", s.cfg.ShortName)
+		return "", false
+	}
+
+	baseMACs.Lock()
+	defer baseMACs.Unlock()
+	if !s.allocateBaseMAC(fields[1]) {
+		log.Warnf("node %s: base mac %s of the preserved topology file is used by another node, generating a new topology file", s.cfg.ShortName, fields[1])
+		return "", false
+	}
+	log.Debugf("node %s: preserving topology file with base mac %s", s.cfg.ShortName, fields[1])
+	return fields[1], true
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestPreservedBaseMAC(t *testing.T) {
+	tplFile := filepath.Join(t.TempDir(), "custom.yml.tpl")
+	if err := os.WriteFile(tplFile, []byte("chassis_mac: {{ .MAC }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		preserve bool
+		nodeType string
+		tplFile  string
+		// the template is changed after the topology file is generated
+		changeTpl bool
+		// the base mac is allocated to another node
+		taken bool
+		want  bool
+	}{
+		"preserved":        {preserve: true, nodeType: "ixrd2", want: true},
+		"custom-template":  {preserve: true, nodeType: "ixrd2", tplFile: tplFile, want: true},
+		"not-preserved":    {nodeType: "ixrd2"},
+		"type-changed":     {preserve: true, nodeType: "ixrd3"},
+		"template-changed": {preserve: true, nodeType: "ixrd2", tplFile: tplFile, changeTpl: true},
+		"mac-taken":        {preserve: true, nodeType: "ixrd2", taken: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			baseMACs.m = map[string]map[string]struct{}{}
+			labDir := t.TempDir()
+			tpl := tc.tplFile
+			if tc.changeTpl {
+				tpl = filepath.Join(t.TempDir(), "custom.yml.tpl")
+				if err := os.WriteFile(tpl, []byte("chassis_mac: {{ .MAC }}\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := generateSRLTopologyFile("ixrd2", tpl, labDir, "02:aa:bb:00:00:00"); err != nil {
+				t.Fatal(err)
+			}
+			if tc.changeTpl {
+				if err := os.WriteFile(tpl, []byte("base_mac: {{ .MAC }}\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tc.taken {
+				baseMACs.m[""] = map[string]struct{}{"02:aa:bb:00:00:00": {}}
+			}
+
+			s := &srl{
+				cfg:              &types.NodeConfig{ShortName: "srl1", LabDir: labDir, NodeType: tc.nodeType},
+				topologyTemplate: tpl,
+				preserveTopology: tc.preserve,
+			}
+			m, ok := s.preservedBaseMAC()
+			if ok != tc.want {
+				t.Fatalf("wanted the topology file preserved %v, got %v", tc.want, ok)
+			}
+			if !ok {
+				return
+			}
+			if m != "02:aa:bb:00:00:00" {
+				t.Fatalf("wanted the preserved base mac 02:aa:bb:00:00:00, got %s", m)
+			}
+			if _, ok := baseMACs.m[""][m]; !ok {
+				t.Fatalf("wanted the preserved base mac to be allocated")
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/types"
+)
+
+var (
+	// ulimits applied to the SR Linux containers, unless set by the user.
+	// SR Linux processes open many file descriptors and lock memory for the datapath,
+	// the runtime defaults of the host are often too low for the larger types.
+	srlUlimits = map[string]string{
+		"nofile":  "1048576",
+		"nproc":   "unlimited",
+		"memlock": "unlimited",
+	}
+
+	// recommended minimum soft limits of the fixed form factor types
+	srlUlimitMinimums = map[string]int64{
+		"nofile":  65536,
+		"nproc":   16384,
+		"memlock": 64 << 20,
+	}
+
+	// recommended minimum soft limits of the chassis types, which run many more linecard processes
+	srlChassisUlimitMinimums = map[string]int64{
+		"nofile":  262144,
+		"nproc":   65536,
+		"memlock": 256 << 20,
+	}
+
+	srlChassisTypes = map[string]struct{}{
+		"ixr6":  {},
+		"ixr10": {},
+	}
+)
+
+// initUlimits adds the default ulimits not set by the user and validates the node's ulimits
+func (s *srl) initUlimits() error {
+	ulimits := make(map[string]string, len(srlUlimits))
+	for k, v := range srlUlimits {
+		ulimits[k] = v
+	}
+	for k, v := range s.cfg.Ulimits {
+		ulimits[k] = v
+	}
+	s.cfg.Ulimits = ulimits
+	if _, err := types.ParseUlimits(s.cfg.Ulimits); err != nil {
+		return fmt.Errorf("node %s: %v", s.cfg.ShortName, err)
+	}
+	return nil
+}
+
+// ulimitMinimums returns the recommended minimum soft limits of the node type
+func ulimitMinimums(nodeType string) map[string]int64 {
+	if _, ok := srlChassisTypes[nodeType]; ok {
+		return srlChassisUlimitMinimums
+	}
+	return srlUlimitMinimums
+}
+
+// lowUlimits returns the ulimits of the node with the soft limit below the recommended minimum for the node type
+func (s *srl) lowUlimits() []*types.Ulimit {
+	mins := ulimitMinimums(s.cfg.NodeType)
+	// the ulimits are validated when the node is initialized
+	ulimits, _ := types.ParseUlimits(s.cfg.Ulimits)
+	var res []*types.Ulimit
+	for _, u := range ulimits {
+		if u.Soft != types.UlimitUnlimited && u.Soft < mins[u.Name] {
+			res = append(res, u)
+		}
+	}
+	return res
+}
+
+// checkUlimits warns about the ulimits of the node below the recommended minimums for the node type
+func (s *srl) checkUlimits() {
+	mins := ulimitMinimums(s.cfg.NodeType)
+	for _, u := range s.lowUlimits() {
+		log.Warnf("node %s: ulimit %s %d is below the recommended minimum %d for type %s, SR Linux may fail to boot",
+			s.cfg.ShortName, u.Name, u.Soft, mins[u.Name], s.cfg.NodeType)
+	}
+}
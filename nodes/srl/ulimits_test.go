@@ -0,0 +1,73 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestInitUlimits(t *testing.T) {
+	s := new(srl)
+	err := s.Init(&types.NodeConfig{
+		ShortName: "srl1",
+		Ulimits:   map[string]string{"nofile": "524288:1048576"},
+		Sysctls:   map[string]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the user defined limits take precedence over the defaults
+	want := map[string]string{"nofile": "524288:1048576", "nproc": "unlimited", "memlock": "unlimited"}
+	if !cmp.Equal(s.cfg.Ulimits, want) {
+		t.Fatalf("wanted ulimits %v, got %v", want, s.cfg.Ulimits)
+	}
+
+	err = new(srl).Init(&types.NodeConfig{
+		ShortName: "srl1",
+		Ulimits:   map[string]string{"nofile": "many"},
+		Sysctls:   map[string]string{},
+	})
+	if err == nil {
+		t.Fatalf("wanted an error for an invalid ulimit, got nil")
+	}
+}
+
+func TestLowUlimits(t *testing.T) {
+	tests := map[string]struct {
+		nodeType string
+		ulimits  map[string]string
+		want     []string
+	}{
+		"defaults":        {nodeType: "ixr10"},
+		"fixed":           {nodeType: "ixrd2", ulimits: map[string]string{"nofile": "65536", "nproc": "1024"}, want: []string{"nproc"}},
+		"chassis":         {nodeType: "ixr6", ulimits: map[string]string{"nofile": "65536", "nproc": "65536"}, want: []string{"nofile"}},
+		"soft-below-hard": {nodeType: "ixrd3", ulimits: map[string]string{"memlock": "1024:unlimited"}, want: []string{"memlock"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := new(srl)
+			err := s.Init(&types.NodeConfig{
+				ShortName: "srl1",
+				NodeType:  tc.nodeType,
+				Ulimits:   tc.ulimits,
+				Sysctls:   map[string]string{},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, u := range s.lowUlimits() {
+				got = append(got, u.Name)
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Fatalf("wanted low ulimits %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
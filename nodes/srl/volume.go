@@ -0,0 +1,64 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/srl-labs/containerlab/runtime"
+)
+
+// suffix of the name of the node's volume, appended to the container name
+const volumeNameSuffix = "-data"
+
+// container labels the node's volume is labeled with, so that it can be related to the lab
+var volumeLabels = []string{"containerlab", "clab-node-name"}
+
+// initVolume sets the container path the node's volume is mounted at from the volume label
+func (s *srl) initVolume() error {
+	p, ok := s.cfg.Labels[volumeLabel]
+	if !ok {
+		return nil
+	}
+	if !filepath.IsAbs(p) || filepath.Clean(p) == "/" {
+		return fmt.Errorf("node %s: volume mount path %q set with %s label should be an absolute container path", s.cfg.ShortName, p, volumeLabel)
+	}
+	s.volumePath = filepath.Clean(p)
+	return nil
+}
+
+// volumeName returns the name of the node's volume, derived from the container name to be unique per lab
+func (s *srl) volumeName() string {
+	return s.cfg.LongName + volumeNameSuffix
+}
+
+// Volumes returns the name of the node's volume, if the node mounts one
+func (s *srl) Volumes() []string {
+	if s.volumePath == "" {
+		return nil
+	}
+	return []string{s.volumeName()}
+}
+
+// createVolume creates the node's volume before its container is created,
+// the volume of a previous deployment is reused with its data
+func (s *srl) createVolume(ctx context.Context) error {
+	if s.volumePath == "" {
+		return nil
+	}
+	vm, ok := s.runtime.(runtime.VolumeManager)
+	if !ok {
+		return fmt.Errorf("node %s: runtime %s doesn't support the volumes set with %s label", s.cfg.ShortName, s.runtime.GetName(), volumeLabel)
+	}
+	labels := make(map[string]string, len(volumeLabels))
+	for _, l := range volumeLabels {
+		if v, ok := s.cfg.Labels[l]; ok {
+			labels[l] = v
+		}
+	}
+	return vm.CreateVolume(ctx, s.volumeName(), labels)
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package srl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// fakeVolumeRuntime records the created volumes
+type fakeVolumeRuntime struct {
+	runtime.ContainerRuntime
+	volumes map[string]map[string]string
+}
+
+func (r *fakeVolumeRuntime) CreateVolume(_ context.Context, name string, labels map[string]string) error {
+	r.volumes[name] = labels
+	return nil
+}
+
+func (*fakeVolumeRuntime) DeleteVolume(_ context.Context, _ string) error { return nil }
+
+func TestVolume(t *testing.T) {
+	s := new(srl)
+	err := s.Init(&types.NodeConfig{
+		ShortName: "srl1",
+		LongName:  "clab-lab-srl1",
+		LabDir:    t.TempDir(),
+		Labels:    map[string]string{volumeLabel: "/var/log/data/"},
+		Sysctls:   map[string]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[clab-lab-srl1-data]"; fmt.Sprint(s.Volumes()) != want {
+		t.Fatalf("wanted volumes %s, got %v", want, s.Volumes())
+	}
+	wantBind := "clab-lab-srl1-data:/var/log/data:rw"
+	var found bool
+	for _, b := range s.cfg.Binds {
+		if b == wantBind {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("wanted bind %s, got %v", wantBind, s.cfg.Binds)
+	}
+
+	// the volume is labeled with the lab and the node name once the node labels are set by clab
+	s.cfg.Labels["containerlab"] = "lab"
+	s.cfg.Labels["clab-node-name"] = "srl1"
+	r := &fakeVolumeRuntime{volumes: map[string]map[string]string{}}
+	s.runtime = r
+	if err := s.createVolume(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "map[clab-lab-srl1-data:map[clab-node-name:srl1 containerlab:lab]]"; fmt.Sprint(r.volumes) != want {
+		t.Fatalf("wanted volumes %s, got %v", want, r.volumes)
+	}
+
+	// a relative path or the container root can't be used
+	for _, p := range []string{"data", "/"} {
+		s := new(srl)
+		err := s.Init(&types.NodeConfig{
+			ShortName: "srl1",
+			LabDir:    t.TempDir(),
+			Labels:    map[string]string{volumeLabel: p},
+			Sysctls:   map[string]string{},
+		})
+		if err == nil {
+			t.Fatalf("wanted an error for mount path %q, got nil", p)
+		}
+	}
+
+	// no volume is mounted without the label
+	s = new(srl)
+	if err := s.Init(&types.NodeConfig{ShortName: "srl1", LabDir: t.TempDir(), Sysctls: map[string]string{}}); err != nil {
+		t.Fatal(err)
+	}
+	if s.Volumes() != nil {
+		t.Fatalf("wanted no volumes, got %v", s.Volumes())
+	}
+}
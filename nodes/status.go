@@ -0,0 +1,32 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package nodes
+
+import "context"
+
+// NodeStatus is the status of a node reported by a StatusReporter
+type NodeStatus string
+
+const (
+	// NodeStatusUnknown is returned along with an error when the status can't be determined
+	NodeStatusUnknown NodeStatus = ""
+	// NodeStatusCreating is reported when the node container is not started yet
+	NodeStatusCreating NodeStatus = "creating"
+	// NodeStatusBooting is reported when the container is running, but the NOS is not ready yet
+	NodeStatusBooting NodeStatus = "booting"
+	// NodeStatusReady is reported when the NOS finished booting
+	NodeStatusReady NodeStatus = "ready"
+	// NodeStatusFailed is reported when the container exited with an error
+	NodeStatusFailed NodeStatus = "failed"
+	// NodeStatusStopped is reported when the container exited without an error
+	NodeStatusStopped NodeStatus = "stopped"
+)
+
+// StatusReporter is implemented by nodes that can report their status without blocking, e.g. for a UI polling the nodes.
+// Status runs the checks once, the reported status is best-effort and not authoritative:
+// it may be outdated by the time it is returned and Ready remains the way to wait for a node to boot.
+type StatusReporter interface {
+	Status(context.Context) (NodeStatus, error)
+}
@@ -3,6 +3,7 @@ package containerd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,6 +15,7 @@ import (
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/cio"
 	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/content"
 	"github.com/containerd/containerd/errdefs"
 	"github.com/containerd/containerd/namespaces"
 	"github.com/containerd/containerd/oci"
@@ -22,6 +24,7 @@ import (
 	"github.com/docker/go-units"
 	"github.com/dustin/go-humanize"
 	"github.com/google/shlex"
+	"github.com/google/uuid"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -35,6 +38,8 @@ const (
 	cniCache            = "/opt/cni/cache"
 	runtimeName         = "containerd"
 	defaultTimeout      = 30 * time.Second
+	// interval the progress of an image pull is logged at
+	pullProgressInterval = 5 * time.Second
 )
 
 func init() {
@@ -129,39 +134,126 @@ func (c *ContainerdRuntime) DeleteNet(context.Context) error {
 func (c *ContainerdRuntime) PullImageIfRequired(ctx context.Context, imagename string) error {
 	log.Debugf("Looking up %s container image", imagename)
 	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
-	if !strings.Contains(imagename, ":") {
-		imagename = imagename + ":latest"
-	}
+	imagename = utils.GetImageNameWithTag(imagename)
 	_, err := c.client.GetImage(ctx, imagename)
 	if err == nil {
 		log.Debugf("Image %s present, skip pulling", imagename)
 		return nil
 	}
 	n := utils.GetCanonicalImageName(imagename)
+	log.Infof("Pulling %s containerd image", n)
+	done := make(chan struct{})
+	go c.logPullProgress(ctx, n, done)
 	_, err = c.client.Pull(ctx, n, containerd.WithPullUnpack)
+	close(done)
 	if err != nil {
 		return err
 	}
+	log.Infof("Done pulling %s", n)
 	return nil
 }
 
+// logPullProgress periodically logs the download progress of the image being pulled until done is closed.
+// the progress is computed from the content store ingests, the finished ones are no longer listed
+// and are counted as fully downloaded.
+func (c *ContainerdRuntime) logPullProgress(ctx context.Context, imagename string, done <-chan struct{}) {
+	seen := make(map[string]content.Status)
+	ticker := time.NewTicker(pullProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		active, err := c.client.ContentStore().ListStatuses(ctx)
+		if err != nil {
+			log.Debugf("failed to retrieve the pull progress of %s: %v", imagename, err)
+			continue
+		}
+		offset, total := pullProgress(seen, active)
+		if total == 0 {
+			continue
+		}
+		log.Infof("Pulling %s: %s of %s downloaded (%d%%)", imagename,
+			humanize.Bytes(uint64(offset)), humanize.Bytes(uint64(total)), offset*100/total)
+	}
+}
+
+// pullProgress records the active ingests in seen and returns the downloaded and total bytes of all the seen ingests.
+// the ingests that are no longer active are finished.
+func pullProgress(seen map[string]content.Status, active []content.Status) (offset, total int64) {
+	isActive := make(map[string]bool, len(active))
+	for _, st := range active {
+		seen[st.Ref] = st
+		isActive[st.Ref] = true
+	}
+	for ref, st := range seen {
+		if !isActive[ref] {
+			st.Offset = st.Total
+			seen[ref] = st
+		}
+		offset += st.Offset
+		total += st.Total
+	}
+	return offset, total
+}
+
+// ImageLabels returns the labels of the image config
+func (c *ContainerdRuntime) ImageLabels(ctx context.Context, imagename string) (map[string]string, error) {
+	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	imagename = utils.GetImageNameWithTag(imagename)
+	img, err := c.client.GetImage(ctx, imagename)
+	if err != nil {
+		img, err = c.client.GetImage(ctx, utils.GetCanonicalImageName(imagename))
+		if err != nil {
+			return nil, err
+		}
+	}
+	desc, err := img.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b, err := content.ReadBlob(ctx, img.ContentStore(), desc)
+	if err != nil {
+		return nil, err
+	}
+	// only the labels of the OCI image config are needed
+	var cfg struct {
+		Config struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, err
+	}
+	return cfg.Config.Labels, nil
+}
+
 func (c *ContainerdRuntime) CreateContainer(ctx context.Context, node *types.NodeConfig) (interface{}, error) {
 	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
 
-	var img containerd.Image
-	if !strings.Contains(node.Image, ":") {
-		node.Image = node.Image + ":latest"
-	}
-	img, err := c.client.GetImage(ctx, node.Image)
+	// the node image is left as is, images pinned by digest have no tag
+	imagename := utils.GetImageNameWithTag(node.Image)
+	img, err := c.client.GetImage(ctx, imagename)
 	if err != nil {
 		// try fetching the image with canonical name
 		// as it might be that we pulled this image with canonical name
-		img, err = c.client.GetImage(ctx, utils.GetCanonicalImageName(node.Image))
+		img, err = c.client.GetImage(ctx, utils.GetCanonicalImageName(imagename))
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	if node.DNS != nil {
+		log.Warnf("node %s: dns settings are not supported by the containerd runtime and are ignored", node.ShortName)
+	}
+	if len(node.Ulimits) != 0 {
+		log.Warnf("node %s: ulimits are not supported by the containerd runtime and are ignored", node.ShortName)
+	}
+
 	cmd, err := shlex.Split(node.Cmd)
 	if err != nil {
 		return nil, err
@@ -500,6 +592,29 @@ func waitContainerStop(ctx context.Context, exitCh <-chan containerd.ExitStatus)
 	}
 }
 
+// ContainerStatus returns the state of the container task and its exit code.
+// a container without a task is reported as created, a stopped task as exited.
+func (c *ContainerdRuntime) ContainerStatus(ctx context.Context, containername string) (*runtime.ContainerStatus, error) {
+	task, err := c.getContainerTask(ctx, containername)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return &runtime.ContainerStatus{State: runtime.ContainerStateCreated}, nil
+		}
+		return nil, err
+	}
+	status, err := task.Status(namespaces.WithNamespace(ctx, containerdNamespace))
+	if err != nil {
+		return nil, err
+	}
+	if status.Status == containerd.Stopped {
+		return &runtime.ContainerStatus{
+			State:    runtime.ContainerStateExited,
+			ExitCode: int(status.ExitStatus),
+		}, nil
+	}
+	return &runtime.ContainerStatus{State: string(status.Status)}, nil
+}
+
 func (c *ContainerdRuntime) getContainerTask(ctx context.Context, containername string) (containerd.Task, error) {
 	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
 	cont, err := c.client.LoadContainer(ctx, containername)
@@ -590,6 +705,10 @@ func (*ContainerdRuntime) produceGenericContainerList(ctx context.Context, input
 		ctr.ShortID = ctr.ID
 		ctr.Image = info.Image
 		ctr.Labels = info.Labels
+		// the digest of the image manifest the container was created from
+		if img, err := i.Image(ctx); err == nil {
+			ctr.ImageDigest = img.Target().Digest.String()
+		}
 
 		ctr.NetworkSettings, err = extractIPInfoFromLabels(ctr.Labels)
 		if err != nil {
@@ -666,21 +785,103 @@ func (c *ContainerdRuntime) GetNSPath(ctx context.Context, containername string)
 	return "/proc/" + strconv.Itoa(int(task.Pid())) + "/ns/net", nil
 }
 func (c *ContainerdRuntime) Exec(ctx context.Context, containername string, cmd []string) ([]byte, []byte, error) {
-	return c.internalExec(ctx, containername, cmd, false)
+	stdout, stderr, _, err := c.internalExec(ctx, containername, cmd, false)
+	return stdout, stderr, err
+}
+
+// ExecWithResult executes cmd on container identified with containername and returns its output and exit code
+func (c *ContainerdRuntime) ExecWithResult(ctx context.Context, containername string, cmd []string) (*runtime.ExecResult, error) {
+	stdout, stderr, code, err := c.internalExec(ctx, containername, cmd, false)
+	if err != nil {
+		return nil, err
+	}
+	return &runtime.ExecResult{
+		Stdout:   string(stdout),
+		Stderr:   string(stderr),
+		ExitCode: int(code),
+	}, nil
 }
 
 func (c *ContainerdRuntime) ExecNotWait(ctx context.Context, containername string, cmd []string) error {
-	_, _, err := c.internalExec(ctx, containername, cmd, true)
+	_, _, _, err := c.internalExec(ctx, containername, cmd, true)
 	return err
 }
 
-func (c *ContainerdRuntime) internalExec(ctx context.Context, containername string, cmd []string, detach bool) ([]byte, []byte, error) { //skipcq: RVV-A0005
+// ExecInteractive executes cmd on container identified with containername in a TTY attached to the caller's stdin/stdout/stderr
+func (c *ContainerdRuntime) ExecInteractive(ctx context.Context, containername string, cmd []string) error {
+	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	container, err := c.client.LoadContainer(ctx, containername)
+	if err != nil {
+		return err
+	}
 
-	clabExecId := "clabexec"
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return err
+	}
+	pspec := spec.Process
+	pspec.Terminal = true
+	pspec.Args = cmd
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	restore, err := runtime.MakeRawTerminal()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	// a unique exec id allows multiple interactive sessions to the same container
+	execID := "clabexec-" + uuid.New().String()
+	ioCreator := cio.NewCreator(cio.WithStreams(os.Stdin, os.Stdout, os.Stderr), cio.WithTerminal)
+	process, err := task.Exec(ctx, execID, pspec, ioCreator)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := process.Delete(ctx); err != nil {
+			log.Debugf("failed to delete process: %v", err)
+		}
+	}()
+
+	statusC, err := process.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	if err := process.Start(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	runtime.MonitorTTYSize(ctx, func(ctx context.Context, width, height uint) error {
+		return process.Resize(ctx, uint32(width), uint32(height))
+	})
+
+	select {
+	case status := <-statusC:
+		code, _, err := status.Result()
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			return fmt.Errorf("command %q exited with code %d", strings.Join(cmd, " "), code)
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// internalExec executes cmd on container identified with containername and returns stdout, stderr bytes and the exit code.
+// unless detach is set, it returns once the process exited and its output is fully copied.
+func (c *ContainerdRuntime) internalExec(ctx context.Context, containername string, cmd []string, detach bool) ([]byte, []byte, uint32, error) { //skipcq: RVV-A0005
 	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
 	container, err := c.client.LoadContainer(ctx, containername)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, 0, err
 	}
 
 	var stdinbuf, stdoutbuf, stderrbuf bytes.Buffer
@@ -690,69 +891,93 @@ func (c *ContainerdRuntime) internalExec(ctx context.Context, containername stri
 
 	spec, err := container.Spec(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, 0, err
 	}
 	pspec := spec.Process
 	pspec.Terminal = false
 	pspec.Args = cmd
 	task, err := container.Task(ctx, nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, 0, err
 	}
 
-	needToDelete := true
-	p, err := task.LoadProcess(ctx, clabExecId, nil)
+	// a unique exec id per call, as an exec with a shared id would kill a concurrent exec in the same container
+	clabExecId := "clabexec-" + uuid.New().String()
+	process, err := task.Exec(ctx, clabExecId, pspec, ioCreator)
 	if err != nil {
-		needToDelete = false
+		return nil, nil, 0, err
 	}
 
-	if needToDelete {
-		log.Debugf("Deleting old process with exec-id %s", clabExecId)
-		_, err := p.Delete(ctx, containerd.WithProcessKill)
+	// the process is killed and deleted with a context that is not cancelled with ctx,
+	// as the detached process outlives the exec call and a process of a cancelled exec must not keep running in the task
+	bgCtx := namespaces.WithNamespace(context.Background(), containerdNamespace)
+	if detach {
+		statusC, err := process.Wait(bgCtx)
 		if err != nil {
-			return nil, nil, err
+			deleteProcess(bgCtx, process)
+			return nil, nil, 0, err
 		}
+		if err := process.Start(ctx); err != nil {
+			deleteProcess(bgCtx, process)
+			return nil, nil, 0, err
+		}
+		go func() {
+			<-statusC
+			deleteProcess(bgCtx, process)
+		}()
+		return nil, nil, 0, nil
 	}
 
-	process, err := task.Exec(ctx, clabExecId, pspec, ioCreator)
-	// task, err := container.NewTask(ctx, cio.NewCreator(cio_opt))
+	defer deleteProcess(bgCtx, process)
+
+	// the wait channel is set up before the process starts, so that the exit is never missed
+	statusC, err := process.Wait(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, 0, err
 	}
 
-	var statusC <-chan containerd.ExitStatus
-	if !detach {
-
-		defer func() {
-			exitStatus, err := process.Delete(ctx)
-			if err != nil {
-				log.Errorf("failed to delete process: %v", err)
-				return
-			}
-			if exitStatus.Error() != nil {
-				log.Errorf("failed to delete process: %v", exitStatus.Error())
-			}
-		}()
-
-		statusC, err = process.Wait(ctx)
-		if err != nil {
-			return nil, nil, err
-		}
+	if err := process.Start(ctx); err != nil {
+		return nil, nil, 0, err
 	}
 
-	if err := process.Start(ctx); err != nil {
-		return nil, nil, err
+	code, err := waitExec(ctx, bgCtx, process, statusC)
+	if err != nil {
+		return nil, nil, 0, err
 	}
-	if !detach {
-		status := <-statusC
+
+	// the process exit is reported before its output is copied to the buffers
+	process.IO().Wait()
+
+	log.Debugf("exec %v in container %s exited with code %d", cmd, containername, code)
+	return stdoutbuf.Bytes(), stderrbuf.Bytes(), code, nil
+}
+
+// waitExec waits for the exec process to exit and returns its exit code.
+// when ctx is done first, the process is killed with bgCtx, so that it doesn't keep running in the task.
+func waitExec(ctx, bgCtx context.Context, process containerd.Process, statusC <-chan containerd.ExitStatus) (uint32, error) {
+	select {
+	case status := <-statusC:
 		code, _, err := status.Result()
-		if err != nil {
-			return nil, nil, err
+		return code, err
+	case <-ctx.Done():
+		if err := process.Kill(bgCtx, syscall.SIGKILL); err != nil {
+			log.Errorf("failed to kill process: %v", err)
 		}
+		return 0, ctx.Err()
+	}
+}
 
-		log.Infof("Exit code: %d", code)
+// deleteProcess deletes the exec process from the task of the container, so that it doesn't leak.
+// a process that is still running is killed first.
+func deleteProcess(ctx context.Context, process containerd.Process) {
+	exitStatus, err := process.Delete(ctx, containerd.WithProcessKill)
+	if err != nil {
+		log.Errorf("failed to delete process: %v", err)
+		return
+	}
+	if exitStatus.Error() != nil {
+		log.Errorf("failed to delete process: %v", exitStatus.Error())
 	}
-	return stdoutbuf.Bytes(), stderrbuf.Bytes(), nil
 }
 
 func (c *ContainerdRuntime) DeleteContainer(ctx context.Context, containerID string) error {
@@ -0,0 +1,126 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package containerd
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/namespaces"
+)
+
+// fakeProcess records the signals and the deletions of an exec process
+type fakeProcess struct {
+	containerd.Process
+	killed []syscall.Signal
+	// errors of the contexts the process is killed and deleted with
+	killCtxErr, deleteCtxErr error
+	deleteOpts               int
+	deleted                  bool
+}
+
+func (p *fakeProcess) Kill(ctx context.Context, s syscall.Signal, _ ...containerd.KillOpts) error {
+	p.killed = append(p.killed, s)
+	p.killCtxErr = ctx.Err()
+	return nil
+}
+
+func (p *fakeProcess) Delete(ctx context.Context, opts ...containerd.ProcessDeleteOpts) (*containerd.ExitStatus, error) {
+	p.deleted = true
+	p.deleteCtxErr = ctx.Err()
+	p.deleteOpts = len(opts)
+	return containerd.NewExitStatus(0, time.Now(), nil), nil
+}
+
+func TestWaitExecCancelled(t *testing.T) {
+	p := &fakeProcess{}
+	bgCtx := namespaces.WithNamespace(context.Background(), containerdNamespace)
+	ctx, cancel := context.WithCancel(bgCtx)
+	cancel()
+
+	// the process never exits on its own
+	statusC := make(chan containerd.ExitStatus)
+	if _, err := waitExec(ctx, bgCtx, p, statusC); err != context.Canceled {
+		t.Fatalf("wanted %v, got %v", context.Canceled, err)
+	}
+	deleteProcess(bgCtx, p)
+
+	if len(p.killed) != 1 || p.killed[0] != syscall.SIGKILL || p.killCtxErr != nil {
+		t.Fatalf("wanted the process killed with SIGKILL on a live context, got signals %v, context error %v", p.killed, p.killCtxErr)
+	}
+	if !p.deleted || p.deleteCtxErr != nil || p.deleteOpts != 1 {
+		t.Fatalf("wanted the process deleted with the kill option on a live context, got deleted %v, context error %v, %d options", p.deleted, p.deleteCtxErr, p.deleteOpts)
+	}
+}
+
+func TestWaitExecExited(t *testing.T) {
+	p := &fakeProcess{}
+	ctx := namespaces.WithNamespace(context.Background(), containerdNamespace)
+	statusC := make(chan containerd.ExitStatus, 1)
+	statusC <- *containerd.NewExitStatus(3, time.Now(), nil)
+
+	code, err := waitExec(ctx, ctx, p, statusC)
+	if err != nil || code != 3 {
+		t.Fatalf("wanted exit code 3, got %d, %v", code, err)
+	}
+	if len(p.killed) != 0 {
+		t.Fatalf("wanted the exited process not killed, got signals %v", p.killed)
+	}
+}
+
+func TestPullProgress(t *testing.T) {
+	type poll struct {
+		active []content.Status
+		offset int64
+		total  int64
+	}
+	layer := func(ref string, offset, total int64) content.Status {
+		return content.Status{Ref: ref, Offset: offset, Total: total}
+	}
+	tests := map[string]struct {
+		polls []poll
+	}{
+		"no_ingests": {
+			polls: []poll{{}},
+		},
+		"single_layer": {
+			polls: []poll{
+				{active: []content.Status{layer("l1", 10, 100)}, offset: 10, total: 100},
+				{active: []content.Status{layer("l1", 60, 100)}, offset: 60, total: 100},
+			},
+		},
+		"layer_finished_between_polls": {
+			polls: []poll{
+				{active: []content.Status{layer("l1", 10, 100), layer("l2", 5, 50)}, offset: 15, total: 150},
+				// l1 is done and no longer reported, its size is counted as downloaded
+				{active: []content.Status{layer("l2", 20, 50)}, offset: 120, total: 150},
+				{offset: 150, total: 150},
+			},
+		},
+		"layer_seen_first_time": {
+			polls: []poll{
+				{active: []content.Status{layer("l1", 50, 100)}, offset: 50, total: 100},
+				// l2 starts after the first poll and adds to the total
+				{active: []content.Status{layer("l1", 80, 100), layer("l2", 10, 200)}, offset: 90, total: 300},
+				{active: []content.Status{layer("l2", 150, 200)}, offset: 250, total: 300},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			seen := make(map[string]content.Status)
+			for i, p := range tc.polls {
+				offset, total := pullProgress(seen, p.active)
+				if offset != p.offset || total != p.total {
+					t.Fatalf("poll %d: wanted %d of %d, got %d of %d", i, p.offset, p.total, offset, total)
+				}
+			}
+		})
+	}
+}
@@ -10,17 +10,22 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
+	"os"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	dockerTypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
 	dockerC "github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-units"
 	"github.com/dustin/go-humanize"
 	"github.com/google/shlex"
 	log "github.com/sirupsen/logrus"
@@ -33,6 +38,8 @@ const (
 	runtimeName    = "docker"
 	sysctlBase     = "/proc/sys"
 	defaultTimeout = 30 * time.Second
+	// interval of the exec inspection while waiting for the exec exit code
+	execInspectInterval = 100 * time.Millisecond
 )
 
 func init() {
@@ -47,6 +54,14 @@ type DockerRuntime struct {
 	config runtime.RuntimeConfig
 	Client *dockerC.Client
 	Mgmt   *types.MgmtNet
+
+	rootlessOnce sync.Once
+	rootless     bool
+
+	// registry digests of the images, keyed by the image name and ID.
+	// the containers are listed repeatedly while polling, the images are inspected once.
+	digestsMu sync.Mutex
+	digests   map[string]string
 }
 
 func (c *DockerRuntime) Init(opts ...runtime.RuntimeOption) error {
@@ -62,6 +77,109 @@ func (c *DockerRuntime) Init(opts ...runtime.RuntimeOption) error {
 	return nil
 }
 
+// checkULASubnet returns an error if the IPv6 subnet generated for the mgmt network
+// overlaps with the subnet of an existing network.
+// the subnet is not re-generated, as the addresses of the nodes are derived from it before the network is created.
+func (c *DockerRuntime) checkULASubnet(ctx context.Context) error {
+	nets, err := c.Client.NetworkList(ctx, dockerTypes.NetworkListOptions{})
+	if err != nil {
+		return err
+	}
+	if name := overlappingNetwork(c.Mgmt.IPv6Subnet, nets); name != "" {
+		return fmt.Errorf("IPv6 subnet %s generated for the management network %q overlaps with the subnet of the network %q, set the ipv6_subnet of the management network explicitly",
+			c.Mgmt.IPv6Subnet, c.Mgmt.Network, name)
+	}
+	return nil
+}
+
+// overlappingNetwork returns the name of the first network with a subnet overlapping with subnet,
+// or an empty string if there is none.
+func overlappingNetwork(subnet string, nets []dockerTypes.NetworkResource) string {
+	_, sn, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return ""
+	}
+	for _, nr := range nets {
+		for _, cfg := range nr.IPAM.Config {
+			_, n, err := net.ParseCIDR(cfg.Subnet)
+			if err != nil {
+				continue
+			}
+			if n.Contains(sn.IP) || sn.Contains(n.IP) {
+				return nr.Name
+			}
+		}
+	}
+	return ""
+}
+
+// IsRootless returns true when the docker daemon runs in rootless mode.
+// the daemon is queried once and the result is reused for the subsequent calls.
+func (c *DockerRuntime) IsRootless(ctx context.Context) bool {
+	c.rootlessOnce.Do(func() {
+		info, err := c.Client.Info(ctx)
+		if err != nil {
+			log.Debugf("failed to get docker info to detect rootless mode: %v", err)
+			return
+		}
+		for _, o := range info.SecurityOptions {
+			if strings.Contains(o, "name=rootless") {
+				c.rootless = true
+				return
+			}
+		}
+	})
+	return c.rootless
+}
+
+// TailLogs writes the last lines of the container output to w, lines < 1 writes the whole output
+func (c *DockerRuntime) TailLogs(ctx context.Context, id string, lines int, w io.Writer) error {
+	tail := "all"
+	if lines > 0 {
+		tail = strconv.Itoa(lines)
+	}
+	rc, err := c.Client.ContainerLogs(ctx, id, dockerTypes.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       tail,
+	})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	// containers are created with a TTY, so the output is not multiplexed
+	_, err = io.Copy(w, rc)
+	return err
+}
+
+// CreateVolume creates the named volume with the labels, an existing volume with the same name is reused with its data
+func (c *DockerRuntime) CreateVolume(ctx context.Context, name string, labels map[string]string) error {
+	vctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	_, err := c.Client.VolumeInspect(vctx, name)
+	switch {
+	case err == nil:
+		log.Debugf("Reusing volume %s", name)
+		return nil
+	case !dockerC.IsErrNotFound(err):
+		return err
+	}
+	log.Debugf("Creating volume %s", name)
+	_, err = c.Client.VolumeCreate(vctx, volume.VolumeCreateBody{Name: name, Labels: labels})
+	return err
+}
+
+// DeleteVolume removes the named volume, a volume that doesn't exist is skipped
+func (c *DockerRuntime) DeleteVolume(ctx context.Context, name string) error {
+	vctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	err := c.Client.VolumeRemove(vctx, name, false)
+	if dockerC.IsErrNotFound(err) {
+		return nil
+	}
+	return err
+}
+
 func (c *DockerRuntime) WithKeepMgmtNet() {
 	c.config.KeepMgmtNet = true
 }
@@ -137,6 +255,12 @@ func (c *DockerRuntime) CreateNet(ctx context.Context) (err error) {
 			Options: netwOpts,
 		}
 
+		if c.Mgmt.IPv6ULA {
+			if err := c.checkULASubnet(nctx); err != nil {
+				return err
+			}
+		}
+
 		netCreateResponse, err := c.Client.NetworkCreate(nctx, c.Mgmt.Network, opts)
 		if err != nil {
 			return err
@@ -277,6 +401,11 @@ func (c *DockerRuntime) CreateContainer(ctx context.Context, node *types.NodeCon
 		NetworkMode:  container.NetworkMode(c.Mgmt.Network),
 		ExtraHosts:   node.ExtraHosts, // add static /etc/hosts entries
 	}
+	// the docker daemon resolver settings are used unless the node sets its own
+	if node.DNS != nil {
+		containerHostConfig.DNS = node.DNS.Servers
+		containerHostConfig.DNSSearch = node.DNS.Search
+	}
 	var resources container.Resources
 	if node.Memory != "" {
 		mem, err := humanize.ParseBytes(node.Memory)
@@ -292,6 +421,13 @@ func (c *DockerRuntime) CreateContainer(ctx context.Context, node *types.NodeCon
 	if node.CPUSet != "" {
 		resources.CpusetCpus = node.CPUSet
 	}
+	ulimits, err := types.ParseUlimits(node.Ulimits)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range ulimits {
+		resources.Ulimits = append(resources.Ulimits, &units.Ulimit{Name: u.Name, Soft: u.Soft, Hard: u.Hard})
+	}
 	containerHostConfig.Resources = resources
 	containerNetworkingConfig := &network.NetworkingConfig{}
 
@@ -387,6 +523,33 @@ func (c *DockerRuntime) PullImageIfRequired(ctx context.Context, imageName strin
 	return nil
 }
 
+// ImageLabels returns the labels of the image config
+func (c *DockerRuntime) ImageLabels(ctx context.Context, image string) (map[string]string, error) {
+	inspect, _, err := c.Client.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	if inspect.Config == nil {
+		return nil, nil
+	}
+	return inspect.Config.Labels, nil
+}
+
+// ContainerStatus returns the state of the container and its exit code
+func (c *DockerRuntime) ContainerStatus(ctx context.Context, id string) (*runtime.ContainerStatus, error) {
+	cont, err := c.Client.ContainerInspect(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cont.ContainerJSONBase == nil || cont.State == nil {
+		return nil, fmt.Errorf("container %s state is not available", id)
+	}
+	return &runtime.ContainerStatus{
+		State:    cont.State.Status,
+		ExitCode: cont.State.ExitCode,
+	}, nil
+}
+
 // StartContainer starts a docker container
 func (c *DockerRuntime) StartContainer(ctx context.Context, id string) error {
 	nctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
@@ -441,7 +604,7 @@ func (c *DockerRuntime) ListContainers(ctx context.Context, gfilters []*types.Ge
 
 		nr = append(nr, bridgenet...)
 	}
-	return c.produceGenericContainerList(ctrs, nr)
+	return c.produceGenericContainerList(ctx, ctrs, nr)
 }
 
 func (c *DockerRuntime) GetContainer(ctx context.Context, containerID string) (*types.GenericContainer, error) {
@@ -476,7 +639,7 @@ func (*DockerRuntime) buildFilterString(gfilters []*types.GenericFilter) filters
 }
 
 // Transform docker-specific to generic container format
-func (c *DockerRuntime) produceGenericContainerList(inputContainers []dockerTypes.Container, inputNetworkRessources []dockerTypes.NetworkResource) ([]types.GenericContainer, error) {
+func (c *DockerRuntime) produceGenericContainerList(ctx context.Context, inputContainers []dockerTypes.Container, inputNetworkRessources []dockerTypes.NetworkResource) ([]types.GenericContainer, error) {
 	var result []types.GenericContainer
 
 	for _, i := range inputContainers {
@@ -490,6 +653,7 @@ func (c *DockerRuntime) produceGenericContainerList(inputContainers []dockerType
 			Labels:          i.Labels,
 			NetworkSettings: types.GenericMgmtIPs{},
 		}
+		ctr.ImageDigest = c.imageDigest(ctx, i.Image, i.ImageID)
 		bridgeName := c.Mgmt.Network
 		// if bridgeName is "", try to find a network created by clab that the container is connected to
 		if bridgeName == "" && inputNetworkRessources != nil {
@@ -512,11 +676,95 @@ func (c *DockerRuntime) produceGenericContainerList(inputContainers []dockerType
 	return result, nil
 }
 
+// imageDigest returns the registry digest of the image the container was created from.
+// the digest of an image pinned by digest is taken from the image name,
+// otherwise it is the repo digest of the image for the repository of the image name.
+// an empty string is returned for the images not pulled from a registry, e.g. built locally.
+// the digest is cached per image, so that an image is inspected once by the runtime.
+func (c *DockerRuntime) imageDigest(ctx context.Context, image, imageID string) string {
+	if d := utils.ImageDigest(image); d != "" {
+		return d
+	}
+	key := image + "@" + imageID
+	c.digestsMu.Lock()
+	d, ok := c.digests[key]
+	c.digestsMu.Unlock()
+	if ok {
+		return d
+	}
+	inspect, _, err := c.Client.ImageInspectWithRaw(ctx, imageID)
+	if err != nil {
+		// not cached, the image may be inspected successfully on the next listing
+		log.Debugf("failed to inspect image %s: %v", image, err)
+		return ""
+	}
+	d = repoDigest(image, inspect.RepoDigests)
+	c.digestsMu.Lock()
+	if c.digests == nil {
+		c.digests = make(map[string]string)
+	}
+	c.digests[key] = d
+	c.digestsMu.Unlock()
+	return d
+}
+
+// repoDigest returns the digest out of the repo digests of the image for the repository of the image name,
+// or the first digest if the image was not pulled from that repository.
+func repoDigest(image string, repoDigests []string) string {
+	repo := strings.TrimSuffix(image, ":"+utils.ImageTag(image))
+	var digest string
+	for _, rd := range repoDigests {
+		i := strings.Index(rd, "@")
+		if i < 0 {
+			continue
+		}
+		if rd[:i] == repo {
+			return rd[i+1:]
+		}
+		if digest == "" {
+			digest = rd[i+1:]
+		}
+	}
+	return digest
+}
+
 // Exec executes cmd on container identified with id and returns stdout, stderr bytes and an error
 func (c *DockerRuntime) Exec(ctx context.Context, id string, cmd []string) ([]byte, []byte, error) {
+	_, stdout, stderr, err := c.internalExec(ctx, id, cmd)
+	return stdout, stderr, err
+}
+
+// ExecWithResult executes cmd on container identified with id and returns its output and exit code
+func (c *DockerRuntime) ExecWithResult(ctx context.Context, id string, cmd []string) (*runtime.ExecResult, error) {
+	execID, stdout, stderr, err := c.internalExec(ctx, id, cmd)
+	if err != nil {
+		return nil, err
+	}
+	// the output stream may end before the exec is reported as exited
+	execInfo, err := c.Client.ContainerExecInspect(ctx, execID)
+	for err == nil && execInfo.Running {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(execInspectInterval):
+		}
+		execInfo, err = c.Client.ContainerExecInspect(ctx, execID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &runtime.ExecResult{
+		Stdout:   string(stdout),
+		Stderr:   string(stderr),
+		ExitCode: execInfo.ExitCode,
+	}, nil
+}
+
+// internalExec executes cmd on container identified with id and returns the exec id, stdout, stderr bytes and an error
+func (c *DockerRuntime) internalExec(ctx context.Context, id string, cmd []string) (string, []byte, []byte, error) {
 	cont, err := c.Client.ContainerInspect(ctx, id)
 	if err != nil {
-		return nil, nil, err
+		return "", nil, nil, err
 	}
 	execID, err := c.Client.ContainerExecCreate(ctx, id, dockerTypes.ExecConfig{
 		User:         "root",
@@ -526,14 +774,14 @@ func (c *DockerRuntime) Exec(ctx context.Context, id string, cmd []string) ([]by
 	})
 	if err != nil {
 		log.Errorf("failed to create exec in container %s: %v", cont.Name, err)
-		return nil, nil, err
+		return "", nil, nil, err
 	}
 	log.Debugf("%s exec created %v", cont.Name, id)
 
 	rsp, err := c.Client.ContainerExecAttach(ctx, execID.ID, dockerTypes.ExecStartCheck{})
 	if err != nil {
 		log.Errorf("failed exec in container %s: %v", cont.Name, err)
-		return nil, nil, err
+		return "", nil, nil, err
 	}
 	defer rsp.Close()
 	log.Debugf("%s exec attached %v", cont.Name, id)
@@ -549,12 +797,76 @@ func (c *DockerRuntime) Exec(ctx context.Context, id string, cmd []string) ([]by
 	select {
 	case err := <-outputDone:
 		if err != nil {
-			return outBuf.Bytes(), errBuf.Bytes(), err
+			return execID.ID, outBuf.Bytes(), errBuf.Bytes(), err
+		}
+	case <-ctx.Done():
+		return "", nil, nil, ctx.Err()
+	}
+	return execID.ID, outBuf.Bytes(), errBuf.Bytes(), nil
+}
+
+// ExecInteractive executes cmd on container identified with id in a TTY attached to the caller's stdin/stdout/stderr
+func (c *DockerRuntime) ExecInteractive(ctx context.Context, id string, cmd []string) error {
+	// the terminal is checked before the exec is created, so that no exec is left running in the container without a TTY
+	restore, err := runtime.MakeRawTerminal()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	execID, err := c.Client.ContainerExecCreate(ctx, id, dockerTypes.ExecConfig{
+		User:         "root",
+		Tty:          true,
+		AttachStdin:  true,
+		AttachStderr: true,
+		AttachStdout: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create exec in container %s: %v", id, err)
+	}
+
+	rsp, err := c.Client.ContainerExecAttach(ctx, execID.ID, dockerTypes.ExecStartCheck{Tty: true})
+	if err != nil {
+		return fmt.Errorf("failed exec in container %s: %v", id, err)
+	}
+	defer rsp.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	runtime.MonitorTTYSize(ctx, func(ctx context.Context, width, height uint) error {
+		return c.Client.ContainerExecResize(ctx, execID.ID, dockerTypes.ResizeOptions{Width: width, Height: height})
+	})
+
+	go func() {
+		_, _ = io.Copy(rsp.Conn, os.Stdin)
+		_ = rsp.CloseWrite()
+	}()
+
+	// with a TTY stdout and stderr are multiplexed on the same stream
+	outputDone := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(os.Stdout, rsp.Reader)
+		outputDone <- err
+	}()
+
+	select {
+	case err := <-outputDone:
+		if err != nil {
+			return err
 		}
 	case <-ctx.Done():
-		return nil, nil, ctx.Err()
+		return ctx.Err()
+	}
+
+	execInfo, err := c.Client.ContainerExecInspect(ctx, execID.ID)
+	if err != nil {
+		return err
 	}
-	return outBuf.Bytes(), errBuf.Bytes(), nil
+	if execInfo.ExitCode != 0 {
+		return fmt.Errorf("command %q exited with code %d", strings.Join(cmd, " "), execInfo.ExitCode)
+	}
+	return nil
 }
 
 // ExecNotWait executes cmd on container identified with id but doesn't wait for output nor attaches stdout/err
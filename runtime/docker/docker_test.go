@@ -0,0 +1,124 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	dockerTypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	dockerC "github.com/docker/docker/client"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestExecInteractiveNotATerminal(t *testing.T) {
+	// the daemon is not expected to receive any request
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, "unexpected request", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	cli, err := dockerC.NewClientWithOpts(dockerC.WithHost("tcp://"+srv.Listener.Addr().String()), dockerC.WithVersion("1.41"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stdin is a pipe instead of a terminal
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	defer func(f *os.File) { os.Stdin = f }(os.Stdin)
+	os.Stdin = r
+
+	c := &DockerRuntime{Client: cli}
+	err = c.ExecInteractive(context.Background(), "srl1", []string{"sr_cli"})
+	if !errors.Is(err, runtime.ErrNotATerminal) {
+		t.Fatalf("wanted %v, got %v", runtime.ErrNotATerminal, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Fatalf("wanted no exec created, got %d requests to the daemon", n)
+	}
+}
+
+func TestImageDigestCached(t *testing.T) {
+	var inspects int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/v1.41/images/") {
+			http.Error(w, "unexpected request", http.StatusInternalServerError)
+			return
+		}
+		atomic.AddInt32(&inspects, 1)
+		_ = json.NewEncoder(w).Encode(dockerTypes.ImageInspect{
+			ID:          "sha256:aaaa",
+			RepoDigests: []string{"other/srlinux@sha256:2222", "ghcr.io/nokia/srlinux@sha256:1111"},
+		})
+	}))
+	defer srv.Close()
+
+	cli, err := dockerC.NewClientWithOpts(dockerC.WithHost("tcp://"+srv.Listener.Addr().String()), dockerC.WithVersion("1.41"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &DockerRuntime{Client: cli, Mgmt: new(types.MgmtNet)}
+
+	ctrs := []dockerTypes.Container{
+		{ID: "111111111111aaaa", Image: "ghcr.io/nokia/srlinux:21.6.4", ImageID: "sha256:aaaa", NetworkSettings: &dockerTypes.SummaryNetworkSettings{}},
+		{ID: "222222222222aaaa", Image: "ghcr.io/nokia/srlinux:21.6.4", ImageID: "sha256:aaaa", NetworkSettings: &dockerTypes.SummaryNetworkSettings{}},
+	}
+	// the containers are listed repeatedly when polling for the nodes state
+	for i := 0; i < 3; i++ {
+		res, err := c.produceGenericContainerList(context.Background(), ctrs, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ctr := range res {
+			if ctr.ImageDigest != "sha256:1111" {
+				t.Fatalf("wanted digest %q, got %q", "sha256:1111", ctr.ImageDigest)
+			}
+		}
+	}
+	if n := atomic.LoadInt32(&inspects); n != 1 {
+		t.Fatalf("wanted the image inspected once, got %d inspects", n)
+	}
+}
+
+func TestOverlappingNetwork(t *testing.T) {
+	nets := []dockerTypes.NetworkResource{
+		{Name: "bridge", IPAM: network.IPAM{Config: []network.IPAMConfig{{Subnet: "172.17.0.0/16"}}}},
+		{Name: "lab1", IPAM: network.IPAM{Config: []network.IPAMConfig{{Subnet: "172.20.20.0/24"}, {Subnet: "fd12:3456:789a::/64"}}}},
+		{Name: "wide", IPAM: network.IPAM{Config: []network.IPAMConfig{{Subnet: "fd99::/48"}}}},
+		{Name: "none"},
+	}
+	tests := map[string]struct {
+		subnet string
+		want   string
+	}{
+		"no_overlap": {subnet: "fd12:3456:789b::/64", want: ""},
+		"same":       {subnet: "fd12:3456:789a::/64", want: "lab1"},
+		"contained":  {subnet: "fd99:0:0:1::/64", want: "wide"},
+		"containing": {subnet: "fd12:3456::/32", want: "lab1"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := overlappingNetwork(tc.subnet, nets); got != tc.want {
+				t.Fatalf("wanted network %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
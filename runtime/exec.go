@@ -0,0 +1,65 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package runtime
+
+import (
+	"context"
+	goruntime "runtime"
+	"sync"
+)
+
+// ExecResult is the result of a command executed in a container
+type ExecResult struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit-code"`
+	// Error is set when the command could not be executed in the container
+	Error string `json:"error,omitempty"`
+}
+
+// BulkExec executes cmd concurrently in the containers passed as a map of container names to their runtimes.
+// up to maxWorkers commands run in parallel, maxWorkers of 0 defaults to the number of CPUs.
+// a failure to execute the command in a container is recorded in its result and doesn't stop the batch.
+func BulkExec(ctx context.Context, containers map[string]ContainerRuntime, cmd []string, maxWorkers uint) map[string]*ExecResult {
+	results := make(map[string]*ExecResult, len(containers))
+	if len(containers) == 0 {
+		return results
+	}
+
+	workers := int(maxWorkers)
+	if workers <= 0 {
+		workers = goruntime.NumCPU()
+	}
+	if workers > len(containers) {
+		workers = len(containers)
+	}
+
+	input := make(chan string)
+	mu := new(sync.Mutex)
+	wg := new(sync.WaitGroup)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for name := range input {
+				res, err := containers[name].ExecWithResult(ctx, name, cmd)
+				if err != nil {
+					res = &ExecResult{ExitCode: -1, Error: err.Error()}
+				}
+				mu.Lock()
+				results[name] = res
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for name := range containers {
+		input <- name
+	}
+	close(input)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeRuntime implements ExecWithResult, the remaining ContainerRuntime methods are not used by BulkExec
+type fakeRuntime struct {
+	ContainerRuntime
+}
+
+func (*fakeRuntime) ExecWithResult(_ context.Context, id string, cmd []string) (*ExecResult, error) {
+	switch id {
+	case "failed":
+		return &ExecResult{Stderr: "error", ExitCode: 1}, nil
+	case "unreachable":
+		return nil, errors.New("container not found")
+	}
+	return &ExecResult{Stdout: id + ": " + strings.Join(cmd, " ")}, nil
+}
+
+func TestBulkExec(t *testing.T) {
+	r := &fakeRuntime{}
+	containers := map[string]ContainerRuntime{
+		"srl1":        r,
+		"srl2":        r,
+		"failed":      r,
+		"unreachable": r,
+	}
+	want := map[string]*ExecResult{
+		"srl1":        {Stdout: "srl1: sr_cli show version"},
+		"srl2":        {Stdout: "srl2: sr_cli show version"},
+		"failed":      {Stderr: "error", ExitCode: 1},
+		"unreachable": {ExitCode: -1, Error: "container not found"},
+	}
+
+	for _, workers := range []uint{0, 1, 10} {
+		got := BulkExec(context.Background(), containers, []string{"sr_cli", "show", "version"}, workers)
+		if d := cmp.Diff(want, got); d != "" {
+			t.Errorf("workers %d: unexpected results (-want +got):\n%s", workers, d)
+		}
+	}
+}
@@ -377,10 +377,16 @@ func (*IgniteRuntime) Exec(context.Context, string, []string) ([]byte, []byte, e
 	log.Infof("Exec is not yet implemented for Ignite runtime")
 	return []byte{}, []byte{}, nil
 }
+func (*IgniteRuntime) ExecWithResult(context.Context, string, []string) (*runtime.ExecResult, error) {
+	return nil, fmt.Errorf("exec is not yet implemented for Ignite runtime")
+}
 func (*IgniteRuntime) ExecNotWait(context.Context, string, []string) error {
 	log.Infof("ExecNotWait is not yet implemented for Ignite runtime")
 	return nil
 }
+func (*IgniteRuntime) ExecInteractive(context.Context, string, []string) error {
+	return fmt.Errorf("interactive exec is not yet implemented for Ignite runtime")
+}
 func (c *IgniteRuntime) DeleteContainer(ctx context.Context, containerID string) error {
 	vm, err := providers.Client.VMs().Find(filter.NewVMFilter(containerID))
 	if err != nil {
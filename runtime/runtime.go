@@ -6,6 +6,7 @@ package runtime
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/srl-labs/containerlab/types"
@@ -45,8 +46,12 @@ type ContainerRuntime interface {
 	GetNSPath(context.Context, string) (string, error)
 	// Executes cmd on container identified with id and returns stdout, stderr bytes and an error
 	Exec(context.Context, string, []string) ([]byte, []byte, error)
+	// ExecWithResult executes cmd on container identified with id and returns its output and exit code
+	ExecWithResult(context.Context, string, []string) (*ExecResult, error)
 	// ExecNotWait executes cmd on container identified with id but doesn't wait for output nor attaches stdout/err
 	ExecNotWait(context.Context, string, []string) error
+	// ExecInteractive executes cmd on container identified with id in a TTY attached to the caller's stdin/stdout/stderr
+	ExecInteractive(context.Context, string, []string) error
 	// Delete container by its name
 	DeleteContainer(context.Context, string) error
 	// Getter for runtime config options
@@ -54,6 +59,57 @@ type ContainerRuntime interface {
 	GetName() string
 }
 
+// RootlessChecker is implemented by runtimes that can run without root privileges on the host.
+// IsRootless returns true when the runtime daemon runs rootless.
+type RootlessChecker interface {
+	IsRootless(context.Context) bool
+}
+
+// LogTailer is implemented by runtimes that keep the output of the containers.
+// TailLogs writes up to the last lines of the container output to w, lines < 1 writes the whole output.
+type LogTailer interface {
+	TailLogs(ctx context.Context, id string, lines int, w io.Writer) error
+}
+
+// ImageInspector is implemented by runtimes that can inspect the images in their local store.
+// ImageLabels returns the labels of the image config, the image must be present in the store.
+type ImageInspector interface {
+	ImageLabels(ctx context.Context, image string) (map[string]string, error)
+}
+
+// VolumeManager is implemented by runtimes that manage named volumes.
+// CreateVolume creates the volume with the labels or reuses the existing volume with the same name,
+// DeleteVolume removes the volume and its data, removing a volume that doesn't exist is not an error.
+type VolumeManager interface {
+	CreateVolume(ctx context.Context, name string, labels map[string]string) error
+	DeleteVolume(ctx context.Context, name string) error
+}
+
+// StatusInspector is implemented by runtimes that can report the state of a single container.
+// ContainerStatus returns the state of the container identified by its name or id.
+type StatusInspector interface {
+	ContainerStatus(ctx context.Context, id string) (*ContainerStatus, error)
+}
+
+// container states reported by ContainerStatus before the container is started
+// and once the container main process is gone
+const (
+	ContainerStateCreated = "created"
+	ContainerStateExited  = "exited"
+	ContainerStateDead    = "dead"
+)
+
+// ContainerStatus is the state of a container, ExitCode is set once the container has exited
+type ContainerStatus struct {
+	State    string
+	ExitCode int
+}
+
+// Exited returns true if the container main process is gone
+func (s *ContainerStatus) Exited() bool {
+	return s.State == ContainerStateExited || s.State == ContainerStateDead
+}
+
 type Initializer func() ContainerRuntime
 
 type RuntimeOption func(ContainerRuntime)
@@ -0,0 +1,71 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// ErrNotATerminal is returned by interactive execs when the caller's stdin is not a terminal
+var ErrNotATerminal = errors.New("interactive exec requires stdin to be a terminal")
+
+// TTYResizeFunc resizes the TTY of an interactive exec to the given width and height
+type TTYResizeFunc func(ctx context.Context, width, height uint) error
+
+// MakeRawTerminal puts the caller's terminal in raw mode so that the keystrokes
+// are passed as is to the interactive exec.
+// the returned function restores the terminal to its original state.
+func MakeRawTerminal() (func(), error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, ErrNotATerminal
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := term.Restore(fd, state); err != nil {
+			log.Debugf("failed to restore terminal state: %v", err)
+		}
+	}, nil
+}
+
+// MonitorTTYSize sets the exec TTY to the size of the caller's terminal
+// and resizes it every time the terminal is resized until ctx is done.
+func MonitorTTYSize(ctx context.Context, resize TTYResizeFunc) {
+	resizeTTY := func() {
+		w, h, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			log.Debugf("failed to get terminal size: %v", err)
+			return
+		}
+		if err := resize(ctx, uint(w), uint(h)); err != nil {
+			log.Debugf("failed to resize exec tty: %v", err)
+		}
+	}
+	resizeTTY()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGWINCH)
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-sigCh:
+				resizeTTY()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
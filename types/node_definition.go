@@ -17,9 +17,13 @@ type NodeDefinition struct {
 	StartupConfig        string            `yaml:"startup-config,omitempty"`
 	StartupDelay         uint              `yaml:"startup-delay,omitempty"`
 	EnforceStartupConfig bool              `yaml:"enforce-startup-config,omitempty"`
+	BootTimeout          string            `yaml:"boot-timeout,omitempty"`
+	StartupConfigMode    string            `yaml:"startup-config-mode,omitempty"`
+	StartupConfigSHA256  string            `yaml:"startup-config-sha256,omitempty"`
 	Config               *ConfigDispatcher `yaml:"config,omitempty"`
 	Image                string            `yaml:"image,omitempty"`
 	License              string            `yaml:"license,omitempty"`
+	LicenseSHA256        string            `yaml:"license-sha256,omitempty"`
 	Position             string            `yaml:"position,omitempty"`
 	Entrypoint           string            `yaml:"entrypoint,omitempty"`
 	Cmd                  string            `yaml:"cmd,omitempty"`
@@ -37,6 +41,8 @@ type NodeDefinition struct {
 	Publish []string `yaml:"publish,omitempty"`
 	// environment variables
 	Env map[string]string `yaml:"env,omitempty"`
+	// files with KEY=VALUE environment variables, the variables set with env take precedence
+	EnvFiles []string `yaml:"env-files,omitempty"`
 	// linux user used in a container
 	User string `yaml:"user,omitempty"`
 	// container labels
@@ -54,6 +60,16 @@ type NodeDefinition struct {
 	CPUSet string `yaml:"cpu-set,omitempty"`
 	// Set node Memory (cgroup or hypervisor)
 	Memory string `yaml:"memory,omitempty"`
+	// list of nodes that must be ready before this node's post-deploy stage starts
+	WaitFor []string `yaml:"wait-for,omitempty"`
+	// kernel parameters set in the container network namespace
+	Sysctls map[string]string `yaml:"sysctls,omitempty"`
+	// DNS resolver settings of the container
+	DNS *DNSConfig `yaml:"dns,omitempty"`
+	// resource limits of the container processes
+	Ulimits map[string]string `yaml:"ulimits,omitempty"`
+	// extra /etc/hosts entries of the container in the name:ip form
+	ExtraHosts []string `yaml:"extra-hosts,omitempty"`
 
 	// Extra options, may be kind specific
 	Extras *Extras `yaml:"extras,omitempty"`
@@ -94,6 +110,27 @@ func (n *NodeDefinition) GetStartupDelay() uint {
 	return n.StartupDelay
 }
 
+func (n *NodeDefinition) GetBootTimeout() string {
+	if n == nil {
+		return ""
+	}
+	return n.BootTimeout
+}
+
+func (n *NodeDefinition) GetStartupConfigMode() string {
+	if n == nil {
+		return ""
+	}
+	return n.StartupConfigMode
+}
+
+func (n *NodeDefinition) GetStartupConfigSHA256() string {
+	if n == nil {
+		return ""
+	}
+	return n.StartupConfigSHA256
+}
+
 func (n *NodeDefinition) GetEnforceStartupConfig() bool {
 	if n == nil {
 		return false
@@ -123,6 +160,13 @@ func (n *NodeDefinition) GetLicense() string {
 	return n.License
 }
 
+func (n *NodeDefinition) GetLicenseSHA256() string {
+	if n == nil {
+		return ""
+	}
+	return n.LicenseSHA256
+}
+
 func (n *NodeDefinition) GetPostion() string {
 	if n == nil {
 		return ""
@@ -186,6 +230,13 @@ func (n *NodeDefinition) GetEnv() map[string]string {
 	return n.Env
 }
 
+func (n *NodeDefinition) GetEnvFiles() []string {
+	if n == nil {
+		return nil
+	}
+	return n.EnvFiles
+}
+
 func (n *NodeDefinition) GetUser() string {
 	if n == nil {
 		return ""
@@ -249,6 +300,41 @@ func (n *NodeDefinition) GetNodeMemory() string {
 	return n.Memory
 }
 
+func (n *NodeDefinition) GetWaitFor() []string {
+	if n == nil {
+		return nil
+	}
+	return n.WaitFor
+}
+
+func (n *NodeDefinition) GetSysctls() map[string]string {
+	if n == nil {
+		return nil
+	}
+	return n.Sysctls
+}
+
+func (n *NodeDefinition) GetUlimits() map[string]string {
+	if n == nil {
+		return nil
+	}
+	return n.Ulimits
+}
+
+func (n *NodeDefinition) GetExtraHosts() []string {
+	if n == nil {
+		return nil
+	}
+	return n.ExtraHosts
+}
+
+func (n *NodeDefinition) GetDNS() *DNSConfig {
+	if n == nil {
+		return nil
+	}
+	return n.DNS
+}
+
 func (n *NodeDefinition) GetExec() []string {
 	if n == nil {
 		return nil
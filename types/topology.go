@@ -1,8 +1,10 @@
 package types
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/docker/go-connections/nat"
 	"github.com/mitchellh/go-homedir"
@@ -15,6 +17,14 @@ type Topology struct {
 	Kinds    map[string]*NodeDefinition `yaml:"kinds,omitempty"`
 	Nodes    map[string]*NodeDefinition `yaml:"nodes,omitempty"`
 	Links    []*LinkConfig              `yaml:"links,omitempty"`
+	// groups of nodes sharing a startup-config template, the nodes join a group with the group setting
+	Groups map[string]*GroupDefinition `yaml:"groups,omitempty"`
+}
+
+// GroupDefinition represents the settings the member nodes of a group inherit
+type GroupDefinition struct {
+	// startup-config template rendered for each member node
+	StartupConfig string `yaml:"startup-config,omitempty"`
 }
 
 func NewTopology() *Topology {
@@ -30,6 +40,8 @@ type LinkConfig struct {
 	Endpoints []string
 	Labels    map[string]string      `yaml:"labels,omitempty"`
 	Vars      map[string]interface{} `yaml:"vars,omitempty"`
+	// MTU of the link, set on the veth interfaces and passed to the nodes that configure it in the NOS
+	MTU int `yaml:"mtu,omitempty"`
 }
 
 func (t *Topology) GetDefaults() *NodeDefinition {
@@ -110,6 +122,36 @@ func (t *Topology) GetNodeEnv(name string) map[string]string {
 	return nil
 }
 
+// GetNodeEnvFiles returns the absolute paths of the node env files
+// set on the node, kind or defaults level, with the node level being the most specific.
+// an error is returned if any of the files doesn't exist.
+func (t *Topology) GetNodeEnvFiles(name string) ([]string, error) {
+	ndef, ok := t.Nodes[name]
+	if !ok {
+		return nil, nil
+	}
+	files := ndef.GetEnvFiles()
+	if len(files) == 0 {
+		files = t.GetKind(t.GetNodeKind(name)).GetEnvFiles()
+	}
+	if len(files) == 0 {
+		files = t.GetDefaults().GetEnvFiles()
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		p, err := resolvePath(f)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(p); err != nil {
+			return nil, fmt.Errorf("env file %q of node %q: %v", f, name, err)
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
 func (t *Topology) GetNodePublish(name string) []string {
 	if ndef, ok := t.Nodes[name]; ok {
 		if len(ndef.GetPublish()) > 0 {
@@ -153,12 +195,20 @@ func (t *Topology) GetNodeStartupConfig(name string) (string, error) {
 	if ndef, ok := t.Nodes[name]; ok {
 		var err error
 		cfg = ndef.GetStartupConfig()
+		// the group template is more specific than the kind and defaults configs
+		if g, ok := t.Groups[t.GetNodeGroup(name)]; ok && g != nil && cfg == "" {
+			cfg = g.StartupConfig
+		}
 		if t.GetKind(t.GetNodeKind(name)).GetStartupConfig() != "" && cfg == "" {
 			cfg = t.GetKind(t.GetNodeKind(name)).GetStartupConfig()
 		}
 		if cfg == "" {
 			cfg = t.GetDefaults().GetStartupConfig()
 		}
+		// remote startup configs are fetched by the node at deploy time
+		if utils.IsHTTPURL(cfg) {
+			return cfg, nil
+		}
 		if cfg != "" {
 			cfg, err = resolvePath(cfg)
 			if err != nil {
@@ -184,6 +234,46 @@ func (t *Topology) GetNodeStartupDelay(name string) uint {
 	return 0
 }
 
+func (t *Topology) GetNodeBootTimeout(name string) string {
+	if ndef, ok := t.Nodes[name]; ok {
+		if ndef.GetBootTimeout() != "" {
+			return ndef.GetBootTimeout()
+		}
+		if t.GetKind(t.GetNodeKind(name)).GetBootTimeout() != "" {
+			return t.GetKind(t.GetNodeKind(name)).GetBootTimeout()
+		}
+		return t.GetDefaults().GetBootTimeout()
+	}
+	return ""
+}
+
+func (t *Topology) GetNodeStartupConfigMode(name string) string {
+	if ndef, ok := t.Nodes[name]; ok {
+		if ndef.GetStartupConfigMode() != "" {
+			return ndef.GetStartupConfigMode()
+		}
+		if t.GetKind(t.GetNodeKind(name)).GetStartupConfigMode() != "" {
+			return t.GetKind(t.GetNodeKind(name)).GetStartupConfigMode()
+		}
+		return t.GetDefaults().GetStartupConfigMode()
+	}
+	return ""
+}
+
+// GetNodeStartupConfigSHA256 returns the expected SHA-256 digest of the node startup-config file
+func (t *Topology) GetNodeStartupConfigSHA256(name string) string {
+	if ndef, ok := t.Nodes[name]; ok {
+		if ndef.GetStartupConfigSHA256() != "" {
+			return ndef.GetStartupConfigSHA256()
+		}
+		if t.GetKind(t.GetNodeKind(name)).GetStartupConfigSHA256() != "" {
+			return t.GetKind(t.GetNodeKind(name)).GetStartupConfigSHA256()
+		}
+		return t.GetDefaults().GetStartupConfigSHA256()
+	}
+	return ""
+}
+
 func (t *Topology) GetNodeEnforceStartupConfig(name string) bool {
 	if ndef, ok := t.Nodes[name]; ok {
 		if ndef.GetEnforceStartupConfig() {
@@ -197,6 +287,20 @@ func (t *Topology) GetNodeEnforceStartupConfig(name string) bool {
 	return false
 }
 
+// GetNodeLicenseSHA256 returns the expected SHA-256 digest of the node license file
+func (t *Topology) GetNodeLicenseSHA256(name string) string {
+	if ndef, ok := t.Nodes[name]; ok {
+		if ndef.GetLicenseSHA256() != "" {
+			return ndef.GetLicenseSHA256()
+		}
+		if t.GetKind(t.GetNodeKind(name)).GetLicenseSHA256() != "" {
+			return t.GetKind(t.GetNodeKind(name)).GetLicenseSHA256()
+		}
+		return t.GetDefaults().GetLicenseSHA256()
+	}
+	return ""
+}
+
 func (t *Topology) GetNodeLicense(name string) (string, error) {
 	var license string
 	if ndef, ok := t.Nodes[name]; ok {
@@ -233,6 +337,43 @@ func (t *Topology) GetNodeImage(name string) string {
 	return ""
 }
 
+// CheckGroups returns an error if a node joins a group that is not defined in the groups section.
+// the groups are not checked when the section is missing, as the group setting also groups the nodes in the graph.
+func (t *Topology) CheckGroups() error {
+	if len(t.Groups) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(t.Nodes))
+	for name := range t.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if g := t.GetNodeGroup(name); g != "" {
+			if _, ok := t.Groups[g]; !ok {
+				return fmt.Errorf("node %q refers to group %q which is not defined in the topology groups", name, g)
+			}
+		}
+	}
+	return nil
+}
+
+// GetNodeGroupIndex returns the 1-based index of the node among the members of its group sorted by name,
+// so that the group templates can derive per-node values, e.g. addresses. returns 0 for a node without a group.
+func (t *Topology) GetNodeGroupIndex(name string) int {
+	g := t.GetNodeGroup(name)
+	if g == "" {
+		return 0
+	}
+	var idx int
+	for n := range t.Nodes {
+		if n <= name && t.GetNodeGroup(n) == g {
+			idx++
+		}
+	}
+	return idx
+}
+
 func (t *Topology) GetNodeGroup(name string) string {
 	if ndef, ok := t.Nodes[name]; ok {
 		if ndef.GetGroup() != "" {
@@ -413,6 +554,71 @@ func (t *Topology) GetNodeMemory(name string) string {
 	return ""
 }
 
+func (t *Topology) GetNodeWaitFor(name string) []string {
+	if ndef, ok := t.Nodes[name]; ok {
+		if len(ndef.GetWaitFor()) != 0 {
+			return ndef.GetWaitFor()
+		}
+		if len(t.GetKind(t.GetNodeKind(name)).GetWaitFor()) != 0 {
+			return t.GetKind(t.GetNodeKind(name)).GetWaitFor()
+		}
+		return t.GetDefaults().GetWaitFor()
+	}
+	return nil
+}
+
+// GetNodeSysctls returns the sysctls of the node merged from the defaults, kind and node levels.
+// the returned map is never nil, as the node kinds add their own sysctls to it.
+func (t *Topology) GetNodeSysctls(name string) map[string]string {
+	sysctls := make(map[string]string)
+	if ndef, ok := t.Nodes[name]; ok {
+		for k, v := range utils.MergeStringMaps(t.GetDefaults().GetSysctls(),
+			t.GetKind(t.GetNodeKind(name)).GetSysctls(),
+			ndef.GetSysctls()) {
+			sysctls[k] = v
+		}
+	}
+	return sysctls
+}
+
+// GetNodeUlimits returns the ulimits of the node merged from the defaults, kind and node levels
+func (t *Topology) GetNodeUlimits(name string) map[string]string {
+	if ndef, ok := t.Nodes[name]; ok {
+		return utils.MergeStringMaps(t.GetDefaults().GetUlimits(),
+			t.GetKind(t.GetNodeKind(name)).GetUlimits(),
+			ndef.GetUlimits())
+	}
+	return nil
+}
+
+// GetNodeDNS returns the DNS settings of the node, the node level settings replace the kind and defaults level ones
+func (t *Topology) GetNodeDNS(name string) *DNSConfig {
+	if ndef, ok := t.Nodes[name]; ok {
+		if d := ndef.GetDNS(); d != nil {
+			return d
+		}
+		if d := t.GetKind(t.GetNodeKind(name)).GetDNS(); d != nil {
+			return d
+		}
+		return t.GetDefaults().GetDNS()
+	}
+	return nil
+}
+
+// GetNodeExtraHosts returns the extra /etc/hosts entries of the node, the node level entries replace the kind and defaults level ones
+func (t *Topology) GetNodeExtraHosts(name string) []string {
+	if ndef, ok := t.Nodes[name]; ok {
+		if len(ndef.GetExtraHosts()) != 0 {
+			return ndef.GetExtraHosts()
+		}
+		if len(t.GetKind(t.GetNodeKind(name)).GetExtraHosts()) != 0 {
+			return t.GetKind(t.GetNodeKind(name)).GetExtraHosts()
+		}
+		return t.GetDefaults().GetExtraHosts()
+	}
+	return nil
+}
+
 // Returns the 'extras' section for the given node
 func (t *Topology) GetNodeExtras(name string) *Extras {
 	if ndef, ok := t.Nodes[name]; ok {
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -359,6 +360,99 @@ func TestGetNodeLicense(t *testing.T) {
 	}
 }
 
+func TestGetNodeSHA256(t *testing.T) {
+	topo := &Topology{
+		Defaults: &NodeDefinition{LicenseSHA256: "default-lic", StartupConfigSHA256: "default-cfg"},
+		Kinds: map[string]*NodeDefinition{
+			"srl": {LicenseSHA256: "kind-lic"},
+		},
+		Nodes: map[string]*NodeDefinition{
+			"node1": {Kind: "srl", StartupConfigSHA256: "node-cfg"},
+			"node2": {Kind: "srl"},
+			"node3": {Kind: "linux"},
+		},
+	}
+	tests := map[string]struct{ lic, cfg string }{
+		"node1": {lic: "kind-lic", cfg: "node-cfg"},
+		"node2": {lic: "kind-lic", cfg: "default-cfg"},
+		"node3": {lic: "default-lic", cfg: "default-cfg"},
+		"node4": {},
+	}
+	for name, tc := range tests {
+		if got := topo.GetNodeLicenseSHA256(name); got != tc.lic {
+			t.Errorf("node %s: wanted license digest %q, got %q", name, tc.lic, got)
+		}
+		if got := topo.GetNodeStartupConfigSHA256(name); got != tc.cfg {
+			t.Errorf("node %s: wanted startup-config digest %q, got %q", name, tc.cfg, got)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	cfg, err := filepath.Abs("test_data/config.cfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	topo := &Topology{
+		Groups: map[string]*GroupDefinition{
+			"leafs": {StartupConfig: "test_data/config.cfg"},
+		},
+		Kinds: map[string]*NodeDefinition{
+			"srl": {Group: "leafs"},
+		},
+		Nodes: map[string]*NodeDefinition{
+			"leaf2":  {Kind: "srl"},
+			"leaf1":  {Kind: "srl"},
+			"custom": {Kind: "srl", StartupConfig: "test_data/lic1.key"},
+			"spine1": {Kind: "linux"},
+		},
+	}
+	if err := topo.CheckGroups(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		cfg string
+		idx int
+	}{
+		"custom": {idx: 1},
+		"leaf1":  {cfg: cfg, idx: 2},
+		"leaf2":  {cfg: cfg, idx: 3},
+		"spine1": {},
+	}
+	for name, tc := range tests {
+		if idx := topo.GetNodeGroupIndex(name); idx != tc.idx {
+			t.Errorf("node %s: wanted group index %d, got %d", name, tc.idx, idx)
+		}
+		if tc.cfg == "" {
+			continue
+		}
+		got, err := topo.GetNodeStartupConfig(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.cfg {
+			t.Errorf("node %s: wanted startup-config %q, got %q", name, tc.cfg, got)
+		}
+	}
+
+	// the node config takes precedence over the group template
+	if got, _ := topo.GetNodeStartupConfig("custom"); filepath.Base(got) != "lic1.key" {
+		t.Errorf("wanted the node startup-config, got %q", got)
+	}
+
+	topo.Nodes["spine1"].Group = "spines"
+	if err := topo.CheckGroups(); err == nil {
+		t.Fatal("wanted an error for an undefined group, got nil")
+	}
+
+	// the groups are used for the graph only without the groups section
+	topo.Groups = nil
+	if err := topo.CheckGroups(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestGetNodePosition(t *testing.T) {
 	for name, item := range topologyTestSet {
 		t.Logf("%q test item", name)
@@ -456,3 +550,95 @@ func TestGetNodeLabels(t *testing.T) {
 		}
 	}
 }
+
+func TestGetNodeSysctls(t *testing.T) {
+	topo := &Topology{
+		Defaults: &NodeDefinition{
+			Sysctls: map[string]string{"net.ipv4.ip_forward": "0", "net.ipv6.conf.all.autoconf": "0"},
+		},
+		Kinds: map[string]*NodeDefinition{
+			"srl": {Sysctls: map[string]string{"net.ipv4.ip_forward": "1"}},
+		},
+		Nodes: map[string]*NodeDefinition{
+			"node1": {Kind: "srl", Sysctls: map[string]string{"net.ipv6.conf.all.autoconf": "1"}},
+			"node2": {Kind: "linux"},
+		},
+	}
+
+	want := map[string]map[string]string{
+		"node1":   {"net.ipv4.ip_forward": "1", "net.ipv6.conf.all.autoconf": "1"},
+		"node2":   {"net.ipv4.ip_forward": "0", "net.ipv6.conf.all.autoconf": "0"},
+		"unknown": {},
+	}
+	for name, w := range want {
+		if d := cmp.Diff(w, topo.GetNodeSysctls(name)); d != "" {
+			t.Errorf("node %s: unexpected sysctls (-want +got):\n%s", name, d)
+		}
+	}
+}
+
+func TestGetNodeDNS(t *testing.T) {
+	defaults := &DNSConfig{Servers: []string{"10.0.0.1"}}
+	kind := &DNSConfig{Servers: []string{"10.0.0.2"}, Search: []string{"kind.example.com"}}
+	node := &DNSConfig{Search: []string{"node.example.com"}}
+	topo := &Topology{
+		Defaults: &NodeDefinition{DNS: defaults},
+		Kinds:    map[string]*NodeDefinition{"srl": {DNS: kind}},
+		Nodes: map[string]*NodeDefinition{
+			"node1": {Kind: "srl", DNS: node},
+			"node2": {Kind: "srl"},
+			"node3": {Kind: "linux"},
+		},
+	}
+
+	// the node level settings replace the kind and defaults level ones as a whole
+	for name, want := range map[string]*DNSConfig{"node1": node, "node2": kind, "node3": defaults, "node4": nil} {
+		if got := topo.GetNodeDNS(name); got != want {
+			t.Errorf("node %s: wanted %+v, got %+v", name, want, got)
+		}
+	}
+}
+
+func TestGetNodeUlimits(t *testing.T) {
+	topo := &Topology{
+		Defaults: &NodeDefinition{Ulimits: map[string]string{"nofile": "1024", "nproc": "4096"}},
+		Kinds:    map[string]*NodeDefinition{"srl": {Ulimits: map[string]string{"nofile": "65536"}}},
+		Nodes: map[string]*NodeDefinition{
+			"node1": {Kind: "srl", Ulimits: map[string]string{"memlock": "unlimited"}},
+			"node2": {Kind: "linux"},
+		},
+	}
+
+	want := map[string]string{"nofile": "65536", "nproc": "4096", "memlock": "unlimited"}
+	if got := topo.GetNodeUlimits("node1"); !cmp.Equal(got, want) {
+		t.Errorf("node1: wanted %v, got %v", want, got)
+	}
+	want = map[string]string{"nofile": "1024", "nproc": "4096"}
+	if got := topo.GetNodeUlimits("node2"); !cmp.Equal(got, want) {
+		t.Errorf("node2: wanted %v, got %v", want, got)
+	}
+}
+
+func TestGetNodeExtraHosts(t *testing.T) {
+	topo := &Topology{
+		Defaults: &NodeDefinition{ExtraHosts: []string{"ntp.lab:10.0.0.123"}},
+		Kinds:    map[string]*NodeDefinition{"srl": {ExtraHosts: []string{"syslog.lab:10.0.0.200"}}},
+		Nodes: map[string]*NodeDefinition{
+			"node1": {Kind: "srl", ExtraHosts: []string{"collector.lab:10.0.0.57"}},
+			"node2": {Kind: "srl"},
+			"node3": {Kind: "linux"},
+		},
+	}
+
+	tests := map[string][]string{
+		"node1": {"collector.lab:10.0.0.57"},
+		"node2": {"syslog.lab:10.0.0.200"},
+		"node3": {"ntp.lab:10.0.0.123"},
+		"node4": nil,
+	}
+	for node, want := range tests {
+		if got := topo.GetNodeExtraHosts(node); !cmp.Equal(got, want) {
+			t.Errorf("%s: wanted %v, got %v", node, want, got)
+		}
+	}
+}
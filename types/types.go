@@ -7,13 +7,16 @@ package types
 import (
 	"bytes"
 	"fmt"
-	"os"
+	"net"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/containernetworking/plugins/pkg/ns"
 	"github.com/docker/go-connections/nat"
+	"github.com/dustin/go-humanize"
 	log "github.com/sirupsen/logrus"
 	"github.com/srl-labs/containerlab/utils"
 )
@@ -39,6 +42,10 @@ type Endpoint struct {
 	EndpointName string
 	// mac address
 	MAC string
+	// MTU of the link set in the topology file, 0 if not set
+	MTU int
+	// link the endpoint belongs to
+	Link *Link
 }
 
 // mgmtNet struct defines the management network options
@@ -47,8 +54,9 @@ type MgmtNet struct {
 	Network    string `yaml:"network,omitempty"` // docker network name
 	Bridge     string `yaml:"bridge,omitempty"`  // linux bridge backing the docker network (or containerd bridge net)
 	IPv4Subnet string `yaml:"ipv4_subnet,omitempty"`
-	IPv6Subnet string `yaml:"ipv6_subnet,omitempty"`
+	IPv6Subnet string `yaml:"ipv6_subnet,omitempty"` // "auto" generates a unique local subnet
 	MTU        string `yaml:"mtu,omitempty"`
+	IPv6ULA    bool   `yaml:"-"` // set when the IPv6 subnet was generated for the "auto" value
 }
 
 // NodeConfig is a struct that contains the information of a container element
@@ -59,9 +67,13 @@ type NodeConfig struct {
 	LabDir               string // LabDir is a directory related to the node, it contains config items and/or other persistent state
 	Index                int
 	Group                string
+	GroupIndex           int // 1-based index of the node among the members of its group, 0 if the node is not in a group
 	Kind                 string
 	StartupConfig        string // path to config template file that is used for startup config generation
 	StartupDelay         uint   // optional delay (in seconds) to wait before creating this node
+	BootTimeout          string // optional max time to wait for the node to boot, in Go duration format
+	StartupConfigMode    string // defines if startup config replaces the default config or is merged on top of it (if supported by kind)
+	StartupConfigSHA256  string // expected SHA-256 digest of the startup config file, not verified when empty
 	EnforceStartupConfig bool   // when set to true will enforce the use of startup-config, even when config is present in the lab directory
 	ResStartupConfig     string // path to config file that is actually mounted to the container and is a result of templation
 	Config               *ConfigDispatcher
@@ -69,6 +81,7 @@ type NodeConfig struct {
 	NodeType             string
 	Position             string
 	License              string
+	LicenseSHA256        string // expected SHA-256 digest of the license file, not verified when empty
 	Image                string
 	Sysctls              map[string]string
 	User                 string
@@ -93,7 +106,7 @@ type NodeConfig struct {
 	TLSAnchor            string
 	NSPath               string   // network namespace path for this node
 	Publish              []string // list of ports to publish with mysocketctl
-	ExtraHosts           []string // Extra /etc/hosts entries for all nodes
+	ExtraHosts           []string // Extra /etc/hosts entries, the user defined ones followed by the lab nodes with static mgmt addresses
 	// container labels
 	Labels map[string]string
 	// Slice of pointers to local endpoints
@@ -106,6 +119,12 @@ type NodeConfig struct {
 	CPU    float64
 	CPUSet string
 	Memory string
+	// names of the nodes that must be ready before this node's post-deploy stage starts
+	WaitFor []string
+	// DNS resolver settings of the container, the runtime defaults are used when nil
+	DNS *DNSConfig
+	// resource limits of the container processes by the ulimit name, e.g. nofile: 1048576 or nofile: 1024:4096
+	Ulimits map[string]string
 
 	DeploymentStatus string // status that is set by containerlab to indicate deployment stage
 
@@ -116,6 +135,12 @@ type NodeConfig struct {
 // GenerateConfig generates configuration for the nodes
 // out of the template based on the node configuration and saves the result to dst
 func (node *NodeConfig) GenerateConfig(dst, templ string) error {
+	return node.GenerateConfigWithData(dst, templ, node)
+}
+
+// GenerateConfigWithData is GenerateConfig with the template rendered with data
+// instead of the node configuration, e.g. to extend the node configuration with the node's links
+func (node *NodeConfig) GenerateConfigWithData(dst, templ string, data interface{}) error {
 
 	// If the config file is already present in the node dir
 	// we do not regenerate the config unless EnforceStartupConfig is explicitly set to true and startup-config points to a file
@@ -127,23 +152,65 @@ func (node *NodeConfig) GenerateConfig(dst, templ string) error {
 		log.Infof("Startup config for '%s' node enforced: '%s'", node.ShortName, dst)
 	}
 	log.Debugf("generating config for node %s from file %s", node.ShortName, node.StartupConfig)
-	tpl, err := template.New(filepath.Base(node.StartupConfig)).Parse(templ)
+	b, err := node.RenderConfigTemplate(templ, data)
 	if err != nil {
 		return err
 	}
-	dstBytes := new(bytes.Buffer)
-	err = tpl.Execute(dstBytes, node)
+	log.Debugf("node '%s' generated config: %s", node.ShortName, b)
+	return utils.WriteFileAtomic(dst, b, 0644)
+}
+
+// RenderConfigTemplate renders the startup config template of the node with data
+func (node *NodeConfig) RenderConfigTemplate(templ string, data interface{}) ([]byte, error) {
+	tpl, err := template.New(filepath.Base(node.StartupConfig)).Parse(templ)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	log.Debugf("node '%s' generated config: %s", node.ShortName, dstBytes.String())
-	f, err := os.Create(dst)
-	if err != nil {
-		return err
+	dstBytes := new(bytes.Buffer)
+	if err := tpl.Execute(dstBytes, data); err != nil {
+		return nil, err
 	}
-	defer f.Close()
-	_, err = f.Write(dstBytes.Bytes())
-	return err
+	return dstBytes.Bytes(), nil
+}
+
+// ValidateResources checks the cpu, cpu-set and memory limits of the node,
+// so that invalid values are reported before any container is created
+func (node *NodeConfig) ValidateResources() error {
+	if node.CPU < 0 {
+		return fmt.Errorf("node %s: cpu must be a positive number of CPUs, got %v", node.ShortName, node.CPU)
+	}
+	if node.Memory != "" {
+		mem, err := humanize.ParseBytes(node.Memory)
+		if err != nil || mem == 0 {
+			return fmt.Errorf("node %s: memory %q must be a positive number of bytes with an optional suffix, e.g. 4Gb", node.ShortName, node.Memory)
+		}
+	}
+	if node.CPUSet != "" && !validCPUSet(node.CPUSet) {
+		return fmt.Errorf("node %s: cpu-set %q must be a list of cores or core ranges, e.g. 0-1,4", node.ShortName, node.CPUSet)
+	}
+	if _, err := ParseUlimits(node.Ulimits); err != nil {
+		return fmt.Errorf("node %s: %v", node.ShortName, err)
+	}
+	return nil
+}
+
+// validCPUSet checks that s is a comma separated list of cores (e.g. 3) and ascending core ranges (e.g. 0-2)
+func validCPUSet(s string) bool {
+	for _, r := range strings.Split(s, ",") {
+		bounds := strings.Split(r, "-")
+		if len(bounds) > 2 {
+			return false
+		}
+		var prev uint64
+		for i, b := range bounds {
+			n, err := strconv.ParseUint(b, 10, 16)
+			if err != nil || (i == 1 && n < prev) {
+				return false
+			}
+			prev = n
+		}
+	}
+	return true
 }
 
 func DisableTxOffload(n *NodeConfig) error {
@@ -173,6 +240,7 @@ type GenericContainer struct {
 	ID              string
 	ShortID         string // trimmed ID for display purposes
 	Image           string
+	ImageDigest     string // digest of the image the container was created from, e.g. sha256:1a2b...
 	State           string
 	Status          string
 	Labels          map[string]string
@@ -234,8 +302,176 @@ func (cd *ConfigDispatcher) GetVars() map[string]interface{} {
 	return cd.Vars
 }
 
+// DNSConfig represents the DNS resolver settings of a node
+type DNSConfig struct {
+	// addresses of the DNS servers
+	Servers []string `yaml:"servers,omitempty"`
+	// DNS search domains
+	Search []string `yaml:"search,omitempty"`
+}
+
+// Validate returns an error if a DNS server is not an IP address or a search domain is malformed
+func (d *DNSConfig) Validate() error {
+	if d == nil {
+		return nil
+	}
+	for _, s := range d.Servers {
+		if net.ParseIP(s) == nil {
+			return fmt.Errorf("dns server %q is not an IP address", s)
+		}
+	}
+	for _, s := range d.Search {
+		if s == "" || strings.ContainsAny(s, " \t/") {
+			return fmt.Errorf("dns search domain %q is not a domain name", s)
+		}
+	}
+	return nil
+}
+
+// hostGateway is the extra hosts address the docker runtime resolves to the address of the host
+const hostGateway = "host-gateway"
+
+// ValidateExtraHosts returns an error if an extra /etc/hosts entry is not in the name:ip form,
+// the address can also be host-gateway, which is resolved to the host address by the docker runtime
+func ValidateExtraHosts(hosts []string) error {
+	for _, h := range hosts {
+		i := strings.Index(h, ":")
+		if i < 0 {
+			return fmt.Errorf("extra host %q should be in the name:ip form", h)
+		}
+		name, ip := h[:i], h[i+1:]
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return fmt.Errorf("extra host %q has an invalid host name %q", h, name)
+		}
+		if ip != hostGateway && net.ParseIP(ip) == nil {
+			return fmt.Errorf("extra host %q has an invalid IP address %q", h, ip)
+		}
+	}
+	return nil
+}
+
+// UlimitUnlimited is the value of a ulimit without a limit
+const UlimitUnlimited = -1
+
+// ulimit names that can be set on the nodes
+var supportedUlimits = []string{"memlock", "nofile", "nproc"}
+
+// Ulimit is a resource limit of the container processes
+type Ulimit struct {
+	Name string
+	Soft int64
+	Hard int64
+}
+
+// ParseUlimits parses the ulimits of a node, sorted by name.
+// a ulimit value is either a single limit used as both soft and hard limit or a soft:hard pair,
+// a limit is a non-negative number or unlimited (-1).
+func ParseUlimits(m map[string]string) ([]*Ulimit, error) {
+	res := make([]*Ulimit, 0, len(m))
+	for name, v := range m {
+		if _, ok := utils.StringInSlice(supportedUlimits, name); !ok {
+			return nil, fmt.Errorf("unsupported ulimit %q, should be any of %s", name, strings.Join(supportedUlimits, ", "))
+		}
+		u := &Ulimit{Name: name}
+		soft, hard := v, v
+		if i := strings.Index(v, ":"); i >= 0 {
+			soft, hard = v[:i], v[i+1:]
+		}
+		var err error
+		if u.Soft, err = parseUlimitValue(soft); err != nil {
+			return nil, fmt.Errorf("ulimit %s value %q must be a number, unlimited or a soft:hard pair of those", name, v)
+		}
+		if u.Hard, err = parseUlimitValue(hard); err != nil {
+			return nil, fmt.Errorf("ulimit %s value %q must be a number, unlimited or a soft:hard pair of those", name, v)
+		}
+		if u.Hard != UlimitUnlimited && (u.Soft == UlimitUnlimited || u.Soft > u.Hard) {
+			return nil, fmt.Errorf("ulimit %s soft limit exceeds its hard limit in %q", name, v)
+		}
+		res = append(res, u)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+func parseUlimitValue(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "unlimited" || s == "-1" {
+		return UlimitUnlimited, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err == nil && n < 0 {
+		return 0, fmt.Errorf("negative limit %d", n)
+	}
+	return n, err
+}
+
 // Extras contains extra node parameters which are not entitled to be part of a generic node config
 type Extras struct {
-	SRLAgents     []string `yaml:"srl-agents,omitempty"`     // Nokia SR Linux agents. As of now just the agents spec files can be provided here
-	MysocketProxy string   `yaml:"mysocket-proxy,omitempty"` // Proxy address that mysocketctl will use
+	SRLAgents     []SRLAgent `yaml:"srl-agents,omitempty"`     // Nokia SR Linux agents
+	MysocketProxy string     `yaml:"mysocket-proxy,omitempty"` // Proxy address that mysocketctl will use
+
+	// Nokia SR Linux CLI commands appended to the default config before it is committed
+	SRLDefaultConfigSnippets []string `yaml:"srl-default-config-snippets,omitempty"`
+
+	// scripts executed with bash inside Nokia SR Linux nodes once the node is configured
+	SRLPostDeployScripts []SRLPostDeployScript `yaml:"srl-post-deploy-scripts,omitempty"`
+}
+
+// SRLAgent is a Nokia SR Linux agent defined by its appmgr spec file
+// and optionally the agent binary and config files.
+// in the topology file an agent is either a path to the spec file or a map of paths.
+type SRLAgent struct {
+	Spec   string `yaml:"spec,omitempty"`
+	Binary string `yaml:"binary,omitempty"`
+	Config string `yaml:"config,omitempty"`
+}
+
+// UnmarshalYAML supports both the plain spec file path and the map forms of an agent
+func (a *SRLAgent) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var spec string
+	if err := unmarshal(&spec); err == nil {
+		a.Spec = spec
+		return nil
+	}
+
+	type agent SRLAgent
+	var v agent
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	if v.Spec == "" {
+		return fmt.Errorf("srl agent %+v must have a spec file path", v)
+	}
+	*a = SRLAgent(v)
+	return nil
+}
+
+// SRLPostDeployScript is a host script executed inside a Nokia SR Linux node after its post-deploy config.
+// in the topology file a script is either a path to the script or a map with the path and options.
+type SRLPostDeployScript struct {
+	Path string `yaml:"path,omitempty"`
+	// a non-zero exit code of the script is logged instead of failing the deployment
+	IgnoreErrors bool `yaml:"ignore-errors,omitempty"`
+}
+
+// UnmarshalYAML supports both the plain path and the map forms of a post-deploy script
+func (ps *SRLPostDeployScript) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		ps.Path = path
+		return nil
+	}
+
+	type script SRLPostDeployScript
+	var v script
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	if v.Path == "" {
+		return fmt.Errorf("srl post-deploy script %+v must have a path", v)
+	}
+	*ps = SRLPostDeployScript(v)
+	return nil
 }
@@ -0,0 +1,234 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v2"
+)
+
+func TestSRLAgentUnmarshal(t *testing.T) {
+	tests := map[string]struct {
+		got     string
+		want    []SRLAgent
+		wantErr bool
+	}{
+		"spec-paths": {
+			got: `srl-agents:
+  - agent1.yml
+  - path/agent2.yml
+`,
+			want: []SRLAgent{{Spec: "agent1.yml"}, {Spec: "path/agent2.yml"}},
+		},
+		"mixed": {
+			got: `srl-agents:
+  - agent1.yml
+  - spec: agent2.yml
+    binary: bin/agent2
+    config: agent2-config.yml
+`,
+			want: []SRLAgent{
+				{Spec: "agent1.yml"},
+				{Spec: "agent2.yml", Binary: "bin/agent2", Config: "agent2-config.yml"},
+			},
+		},
+		"missing-spec": {
+			got: `srl-agents:
+  - binary: bin/agent2
+`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := new(Extras)
+			err := yaml.Unmarshal([]byte(tc.got), e)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("wanted an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !cmp.Equal(e.SRLAgents, tc.want) {
+				t.Fatalf("wanted %+v got %+v", tc.want, e.SRLAgents)
+			}
+		})
+	}
+}
+
+func TestSRLPostDeployScriptUnmarshal(t *testing.T) {
+	tests := map[string]struct {
+		got     string
+		want    []SRLPostDeployScript
+		wantErr bool
+	}{
+		"mixed": {
+			got: `srl-post-deploy-scripts:
+  - scripts/setup.sh
+  - path: scripts/optional.sh
+    ignore-errors: true
+`,
+			want: []SRLPostDeployScript{
+				{Path: "scripts/setup.sh"},
+				{Path: "scripts/optional.sh", IgnoreErrors: true},
+			},
+		},
+		"missing-path": {
+			got: `srl-post-deploy-scripts:
+  - ignore-errors: true
+`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := new(Extras)
+			err := yaml.Unmarshal([]byte(tc.got), e)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("wanted an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !cmp.Equal(e.SRLPostDeployScripts, tc.want) {
+				t.Fatalf("wanted %+v got %+v", tc.want, e.SRLPostDeployScripts)
+			}
+		})
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	tests := map[string]struct {
+		node    NodeConfig
+		wantErr bool
+	}{
+		"unset":           {node: NodeConfig{}},
+		"valid":           {node: NodeConfig{CPU: 1.5, Memory: "4Gb", CPUSet: "0-1,4"}},
+		"bytes":           {node: NodeConfig{Memory: "4294967296"}},
+		"negative-cpu":    {node: NodeConfig{CPU: -1}, wantErr: true},
+		"bad-memory":      {node: NodeConfig{Memory: "lots"}, wantErr: true},
+		"zero-memory":     {node: NodeConfig{Memory: "0"}, wantErr: true},
+		"bad-cpu-set":     {node: NodeConfig{CPUSet: "0-a"}, wantErr: true},
+		"reversed-range":  {node: NodeConfig{CPUSet: "3-1"}, wantErr: true},
+		"empty-cpu-entry": {node: NodeConfig{CPUSet: "0,,1"}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.node.ValidateResources()
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateDNS(t *testing.T) {
+	tests := map[string]struct {
+		dns     *DNSConfig
+		wantErr bool
+	}{
+		"unset":         {},
+		"valid":         {dns: &DNSConfig{Servers: []string{"10.0.0.53", "2001:db8::53"}, Search: []string{"lab.example.com"}}},
+		"server-name":   {dns: &DNSConfig{Servers: []string{"ns1.example.com"}}, wantErr: true},
+		"server-prefix": {dns: &DNSConfig{Servers: []string{"10.0.0.53/32"}}, wantErr: true},
+		"empty-search":  {dns: &DNSConfig{Search: []string{""}}, wantErr: true},
+		"spaced-search": {dns: &DNSConfig{Search: []string{"lab example.com"}}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.dns.Validate()
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateExtraHosts(t *testing.T) {
+	tests := map[string]struct {
+		hosts   []string
+		wantErr bool
+	}{
+		"unset":       {},
+		"valid":       {hosts: []string{"ntp.lab:10.0.0.123", "syslog:2001:db8::514", "host.docker.internal:host-gateway"}},
+		"no-ip":       {hosts: []string{"ntp.lab"}, wantErr: true},
+		"empty-name":  {hosts: []string{":10.0.0.123"}, wantErr: true},
+		"spaced-name": {hosts: []string{"ntp lab:10.0.0.123"}, wantErr: true},
+		"invalid-ip":  {hosts: []string{"ntp.lab:10.0.0.256"}, wantErr: true},
+		"prefix":      {hosts: []string{"ntp.lab:10.0.0.0/24"}, wantErr: true},
+		"one-invalid": {hosts: []string{"ntp.lab:10.0.0.123", "syslog=10.0.0.514"}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateExtraHosts(tc.hosts)
+			if tc.wantErr && err == nil {
+				t.Fatalf("wanted an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseUlimits(t *testing.T) {
+	tests := map[string]struct {
+		ulimits map[string]string
+		want    []*Ulimit
+		wantErr bool
+	}{
+		"unset": {want: []*Ulimit{}},
+		"valid": {
+			ulimits: map[string]string{"nproc": "1024:4096", "nofile": "1048576", "memlock": "unlimited"},
+			want: []*Ulimit{
+				{Name: "memlock", Soft: UlimitUnlimited, Hard: UlimitUnlimited},
+				{Name: "nofile", Soft: 1048576, Hard: 1048576},
+				{Name: "nproc", Soft: 1024, Hard: 4096},
+			},
+		},
+		"unlimited-hard": {
+			ulimits: map[string]string{"nofile": "65536:-1"},
+			want:    []*Ulimit{{Name: "nofile", Soft: 65536, Hard: UlimitUnlimited}},
+		},
+		"unsupported":    {ulimits: map[string]string{"core": "0"}, wantErr: true},
+		"not-a-number":   {ulimits: map[string]string{"nofile": "lots"}, wantErr: true},
+		"negative":       {ulimits: map[string]string{"nofile": "-2"}, wantErr: true},
+		"soft-over-hard": {ulimits: map[string]string{"nofile": "4096:1024"}, wantErr: true},
+		"unlimited-soft": {ulimits: map[string]string{"nofile": "unlimited:1024"}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseUlimits(tc.ulimits)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("wanted an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Fatalf("wanted %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
@@ -34,12 +34,41 @@ func GetCanonicalImageName(imageName string) string {
 			canonicalImageName = "docker.io/" + imageName
 		}
 	}
-	// append latest tag if no tag was provided
-	if !strings.Contains(canonicalImageName, ":") {
-		canonicalImageName = canonicalImageName + ":latest"
+	// append latest tag if neither tag nor digest was provided
+	return GetImageNameWithTag(canonicalImageName)
+}
+
+// GetImageNameWithTag returns the image name with the implicit "latest" tag appended
+// if the name has neither a tag nor a digest.
+// image names pinned by digest, e.g. srlinux@sha256:1a2b..., are returned as is.
+func GetImageNameWithTag(imageName string) string {
+	if ImageDigest(imageName) != "" || ImageTag(imageName) != "" {
+		return imageName
+	}
+	return imageName + ":latest"
+}
+
+// ImageDigest returns the digest the image name is pinned to, e.g. sha256:1a2b...,
+// or an empty string if the image name has no digest.
+func ImageDigest(imageName string) string {
+	if i := strings.Index(imageName, "@"); i >= 0 {
+		return imageName[i+1:]
 	}
+	return ""
+}
 
-	return canonicalImageName
+// ImageTag returns the tag of the image name or an empty string if the image name has no tag.
+// the digest of the image name is not a tag, e.g. the tag of srlinux:21.6.2@sha256:1a2b... is 21.6.2.
+func ImageTag(imageName string) string {
+	if i := strings.Index(imageName, "@"); i >= 0 {
+		imageName = imageName[:i]
+	}
+	// the tag follows the last colon, unless the colon separates the registry port
+	i := strings.LastIndex(imageName, ":")
+	if i < 0 || strings.Contains(imageName[i:], "/") {
+		return ""
+	}
+	return imageName[i+1:]
 }
 
 func GetCNIBinaryPath() string {
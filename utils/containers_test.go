@@ -0,0 +1,46 @@
+package utils
+
+import "testing"
+
+func TestGetCanonicalImageName(t *testing.T) {
+	tests := map[string]struct {
+		image string
+		want  string
+	}{
+		"official":        {image: "alpine", want: "docker.io/library/alpine:latest"},
+		"user":            {image: "foo/bar:1.0", want: "docker.io/foo/bar:1.0"},
+		"registry":        {image: "ghcr.io/nokia/srlinux", want: "ghcr.io/nokia/srlinux:latest"},
+		"registry-port":   {image: "registry:5000/foo/srlinux", want: "registry:5000/foo/srlinux:latest"},
+		"digest":          {image: "ghcr.io/nokia/srlinux@sha256:0a1b2c3d", want: "ghcr.io/nokia/srlinux@sha256:0a1b2c3d"},
+		"official-digest": {image: "srlinux@sha256:0a1b2c3d", want: "docker.io/library/srlinux@sha256:0a1b2c3d"},
+		"tag-and-digest":  {image: "ghcr.io/nokia/srlinux:21.6.2@sha256:0a1b2c3d", want: "ghcr.io/nokia/srlinux:21.6.2@sha256:0a1b2c3d"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert(t, GetCanonicalImageName(tc.image), tc.want)
+		})
+	}
+}
+
+func TestImageTagAndDigest(t *testing.T) {
+	tests := map[string]struct {
+		image      string
+		wantTag    string
+		wantDigest string
+	}{
+		"no-tag":         {image: "ghcr.io/nokia/srlinux"},
+		"tag":            {image: "ghcr.io/nokia/srlinux:21.6.2", wantTag: "21.6.2"},
+		"registry-port":  {image: "registry:5000/srlinux"},
+		"port-and-tag":   {image: "registry:5000/srlinux:21.6.2", wantTag: "21.6.2"},
+		"digest":         {image: "srlinux@sha256:0a1b2c3d", wantDigest: "sha256:0a1b2c3d"},
+		"tag-and-digest": {image: "srlinux:21.6.2@sha256:0a1b2c3d", wantTag: "21.6.2", wantDigest: "sha256:0a1b2c3d"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert(t, ImageTag(tc.image), tc.wantTag)
+			assert(t, ImageDigest(tc.image), tc.wantDigest)
+		})
+	}
+}
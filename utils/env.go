@@ -5,8 +5,11 @@
 package utils
 
 import (
+	"bufio"
 	"fmt"
+	"os"
 	"reflect"
+	"strings"
 )
 
 // convertEnvs convert env variables passed as a map to a list of them
@@ -85,3 +88,32 @@ func StringInSlice(slice []string, val string) (int, bool) {
 	}
 	return -1, false
 }
+
+// ReadEnvFile reads the environment variables from a file with KEY=VALUE lines.
+// empty lines and lines starting with # are skipped, the value is everything after the first =.
+func ReadEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		k := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || k == "" || strings.ContainsAny(k, " \t") {
+			return nil, fmt.Errorf("%s:%d: malformed line %q, expected KEY=VALUE", path, n, line)
+		}
+		env[k] = kv[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
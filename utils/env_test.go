@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 
@@ -185,3 +187,52 @@ func TestMergeMapsLists(t *testing.T) {
 	assert(t, MergeMaps(d1, nil), d1)
 	assert(t, MergeMaps(d1, d2), d2)
 }
+
+func TestReadEnvFile(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		"vars": {
+			content: "# comment\nA=1\n\n  B = two words\nC=x=y\nD=\n",
+			want:    map[string]string{"A": "1", "B": " two words", "C": "x=y", "D": ""},
+		},
+		"missing-separator": {
+			content: "A=1\nB\n",
+			wantErr: true,
+		},
+		"empty-key": {
+			content: "=1\n",
+			wantErr: true,
+		},
+		"key-with-space": {
+			content: "export A=1\n",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "env")
+			if err := os.WriteFile(p, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadEnvFile(p)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("wanted an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert(t, got, tc.want)
+		})
+	}
+
+	if _, err := ReadEnvFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatalf("wanted an error for a missing file, got nil")
+	}
+}
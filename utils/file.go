@@ -11,7 +11,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
 var errNonRegularFile = errors.New("non-regular file")
@@ -32,7 +34,7 @@ func FileExists(filename string) bool {
 // mode is the desired target file permissions, e.g. "0644".
 func CopyFile(src, dst string, mode os.FileMode) (err error) {
 	var sfi os.FileInfo
-	if isHTTP := (strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")); !isHTTP {
+	if !IsHTTPURL(src) {
 		sfi, err = os.Stat(src)
 		if err != nil {
 			return err
@@ -71,7 +73,7 @@ func CopyFile(src, dst string, mode os.FileMode) (err error) {
 func CopyFileContents(src, dst string, mode os.FileMode) (err error) {
 	var in io.ReadCloser
 
-	if isHTTP := (strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")); isHTTP {
+	if IsHTTPURL(src) {
 		resp, err := http.Get(src)
 		if err != nil || resp.StatusCode != 200 {
 			return fmt.Errorf("%w: %s", errHTTPFetch, src)
@@ -112,6 +114,33 @@ func CopyFileContents(src, dst string, mode os.FileMode) (err error) {
 	return err
 }
 
+// IsHTTPURL returns true if s is an http(s) URL.
+func IsHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// FetchHTTP downloads the content of an http(s) URL.
+// proxy settings are taken from the HTTP_PROXY/HTTPS_PROXY/NO_PROXY env vars.
+// a non-positive timeout means no timeout.
+func FetchHTTP(url string, timeout time.Duration) ([]byte, error) {
+	c := &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+		Timeout:   timeout,
+	}
+
+	resp, err := c.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errHTTPFetch, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: unexpected status %s", errHTTPFetch, url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
 // CreateFile writes content to a file by path `file`.
 func CreateFile(file, content string) (err error) {
 	var f *os.File
@@ -125,6 +154,33 @@ func CreateFile(file, content string) (err error) {
 	return err
 }
 
+// WriteFileAtomic writes data to a temp file in the directory of the file by path `file`
+// and renames it to `file` once written, so that a failed write never leaves a partial file behind.
+// the temp file is removed on error.
+func WriteFileAtomic(file string, data []byte, perm os.FileMode) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Chmod(perm); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
+
 // CreateDirectory creates a directory by a path with a mode/permission specified by perm.
 // If directory exists, the function does not do anything.
 func CreateDirectory(path string, perm os.FileMode) {
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFetchHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/config.json":
+			_, _ = w.Write([]byte("{}"))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	b, err := FetchHTTP(srv.URL+"/config.json", time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert(t, string(b), "{}")
+
+	_, err = FetchHTTP(srv.URL+"/missing", time.Second)
+	if !errors.Is(err, errHTTPFetch) {
+		t.Fatalf("wanted %v, got %v", errHTTPFetch, err)
+	}
+
+	_, err = FetchHTTP(srv.URL+"/slow", 50*time.Millisecond)
+	if !errors.Is(err, errHTTPFetch) {
+		t.Fatalf("wanted %v, got %v", errHTTPFetch, err)
+	}
+}
+
+func TestIsHTTPURL(t *testing.T) {
+	assert(t, IsHTTPURL("http://example.com/config.json"), true)
+	assert(t, IsHTTPURL("https://example.com/config.json"), true)
+	assert(t, IsHTTPURL("/tmp/config.json"), false)
+	assert(t, IsHTTPURL("config.json"), false)
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(dst, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(dst, []byte("new"), 0640); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "new" {
+		t.Fatalf("wanted 'new' got '%s'", b)
+	}
+	fi, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0640 {
+		t.Fatalf("wanted mode 0640 got %o", fi.Mode().Perm())
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("wanted no temp files left, got %d entries", len(entries))
+	}
+
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "config.json"), []byte("new"), 0644); err == nil {
+		t.Fatalf("wanted an error for a missing directory, got nil")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package utils
+
+import (
+	"bytes"
+	"strings"
+)
+
+// TailBuffer is an io.Writer that keeps only the last lines written to it.
+// lines longer than the max line length are truncated, which bounds the memory used
+// regardless of the amount of data written.
+type TailBuffer struct {
+	lines      []string
+	maxLines   int
+	maxLineLen int
+	// partial last line not yet terminated by a newline
+	cur []byte
+}
+
+// NewTailBuffer returns a TailBuffer keeping up to maxLines lines of up to maxLineLen bytes each
+func NewTailBuffer(maxLines, maxLineLen int) *TailBuffer {
+	return &TailBuffer{
+		maxLines:   maxLines,
+		maxLineLen: maxLineLen,
+	}
+}
+
+func (t *TailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			t.appendCur(p)
+			break
+		}
+		t.appendCur(p[:i])
+		t.pushLine()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// appendCur appends b to the current line up to the max line length
+func (t *TailBuffer) appendCur(b []byte) {
+	if room := t.maxLineLen - len(t.cur); room < len(b) {
+		if room <= 0 {
+			return
+		}
+		b = b[:room]
+	}
+	t.cur = append(t.cur, b...)
+}
+
+func (t *TailBuffer) pushLine() {
+	if t.maxLines <= 0 {
+		t.cur = t.cur[:0]
+		return
+	}
+	if len(t.lines) == t.maxLines {
+		t.lines = t.lines[1:]
+	}
+	t.lines = append(t.lines, string(t.cur))
+	t.cur = t.cur[:0]
+}
+
+// String returns the kept lines, including a trailing line not terminated by a newline
+func (t *TailBuffer) String() string {
+	lines := t.lines
+	if len(t.cur) > 0 && t.maxLines > 0 {
+		lines = append(append([]string{}, t.lines...), string(t.cur))
+		if len(lines) > t.maxLines {
+			lines = lines[1:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTailBuffer(t *testing.T) {
+	tests := map[string]struct {
+		writes     []string
+		maxLines   int
+		maxLineLen int
+		want       string
+	}{
+		"fewer-lines": {
+			writes:     []string{"line1\nline2\n"},
+			maxLines:   5,
+			maxLineLen: 100,
+			want:       "line1\nline2",
+		},
+		"last-lines-kept": {
+			writes:     []string{"line1\nline2\n", "line3\nline4\n"},
+			maxLines:   2,
+			maxLineLen: 100,
+			want:       "line3\nline4",
+		},
+		"lines-split-across-writes": {
+			writes:     []string{"li", "ne1\nli", "ne2"},
+			maxLines:   2,
+			maxLineLen: 100,
+			want:       "line1\nline2",
+		},
+		"unterminated-line-counts": {
+			writes:     []string{"line1\nline2\nline3"},
+			maxLines:   2,
+			maxLineLen: 100,
+			want:       "line2\nline3",
+		},
+		"long-lines-truncated": {
+			writes:     []string{"0123456789\n", "abc", "defghijkl\n"},
+			maxLines:   2,
+			maxLineLen: 5,
+			want:       "01234\nabcde",
+		},
+		"no-lines": {
+			writes:     []string{"line1\n"},
+			maxLines:   0,
+			maxLineLen: 100,
+			want:       "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := NewTailBuffer(tc.maxLines, tc.maxLineLen)
+			for _, w := range tc.writes {
+				if n, err := b.Write([]byte(w)); err != nil || n != len(w) {
+					t.Fatalf("write %q: n=%d, err=%v", w, n, err)
+				}
+			}
+			assert(t, b.String(), tc.want)
+		})
+	}
+}
+
+func TestTailBufferBounded(t *testing.T) {
+	b := NewTailBuffer(3, 10)
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(b, "line %d with some extra text\n", i)
+	}
+	assert(t, b.String(), "line 9997 \nline 9998 \nline 9999 ")
+	if len(b.lines) > 3 {
+		t.Fatalf("wanted at most 3 lines kept, got %d", len(b.lines))
+	}
+}
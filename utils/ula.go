@@ -0,0 +1,43 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// ULASubnet returns the IPv6 unique local /64 subnet (RFC 4193) derived from seed.
+// the 40-bit global ID of the subnet is taken from the SHA-256 digest of seed,
+// so the same seed always yields the same subnet and different seeds yield different subnets with a high probability.
+// the subnet is not checked against the subnets in use on the host, it is up to the runtime to reject an overlapping subnet.
+func ULASubnet(seed string) *net.IPNet {
+	d := sha256.Sum256([]byte(seed))
+	ip := make(net.IP, net.IPv6len)
+	ip[0] = 0xfd
+	copy(ip[1:6], d[:5])
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(64, 128)}
+}
+
+// IPv6SubnetAddr returns the address with the interface ID id in the IPv6 subnet,
+// the subnet prefix must be at most 64 bits long.
+func IPv6SubnetAddr(subnet string, id uint64) (string, error) {
+	_, n, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return "", err
+	}
+	if n.IP.To4() != nil {
+		return "", fmt.Errorf("%s is not an IPv6 subnet", subnet)
+	}
+	if ones, _ := n.Mask.Size(); ones > 64 {
+		return "", fmt.Errorf("IPv6 subnet %s is longer than /64", subnet)
+	}
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, n.IP)
+	binary.BigEndian.PutUint64(ip[8:], id)
+	return ip.String(), nil
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestULASubnet(t *testing.T) {
+	s := ULASubnet("clab")
+	if !strings.HasPrefix(s.String(), "fd") || !strings.HasSuffix(s.String(), "::/64") {
+		t.Fatalf("wanted a ULA /64 subnet, got %s", s)
+	}
+	assert(t, ULASubnet("clab").String(), s.String())
+	if ULASubnet("clab2").String() == s.String() {
+		t.Fatalf("wanted different subnets for different seeds, got %s", s)
+	}
+}
+
+func TestIPv6SubnetAddr(t *testing.T) {
+	a, err := IPv6SubnetAddr("fd12:3456:789a::/64", 0xc1ab0001)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert(t, a, "fd12:3456:789a::c1ab:1")
+
+	for _, s := range []string{"172.20.20.0/24", "fd12:3456:789a::/80", "foo"} {
+		if _, err := IPv6SubnetAddr(s, 1); err == nil {
+			t.Fatalf("wanted an error for subnet %s, got nil", s)
+		}
+	}
+}